@@ -3,6 +3,7 @@ package backup
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
@@ -11,40 +12,11 @@ import (
 )
 
 func Dump(ctx context.Context, docker docker.Client) ([]byte, error) {
-	configContent, err := config.ReadConfig(ctx, docker)
-	if err != nil {
-		return nil, err
-	}
-
-	registryContent, err := config.ReadRegistry(ctx, docker)
-	if err != nil {
-		return nil, err
-	}
-
-	catalogContent, err := config.ReadCatalog()
+	backup, err := DumpConfig(ctx, docker, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	toolsConfig, err := config.ReadTools(ctx, docker)
-	if err != nil {
-		return nil, err
-	}
-
-	catalogConfig, err := catalog.ReadConfig()
-	if err != nil {
-		return nil, err
-	}
-
-	catalogFiles := make(map[string]string)
-	for name := range catalogConfig.Catalogs {
-		catalogFileContent, err := config.ReadCatalogFile(name)
-		if err != nil {
-			return nil, err
-		}
-		catalogFiles[name] = string(catalogFileContent)
-	}
-
 	secretsClient := desktop.NewSecretsClient()
 	storedSecrets, err := secretsClient.ListJfsSecrets(ctx)
 	if err != nil {
@@ -74,15 +46,71 @@ func Dump(ctx context.Context, docker docker.Client) ([]byte, error) {
 		return nil, err
 	}
 
-	backup := Backup{
+	backup.Secrets = secrets
+	backup.Policy = policy
+
+	return json.Marshal(backup)
+}
+
+// DumpConfig dumps the configuration files, without the secrets. When catalogNames is not empty,
+// only those catalogs are kept.
+func DumpConfig(ctx context.Context, docker docker.Client, catalogNames []string) (Backup, error) {
+	configContent, err := config.ReadConfig(ctx, docker)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	registryContent, err := config.ReadRegistry(ctx, docker)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	catalogContent, err := config.ReadCatalog()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	toolsConfig, err := config.ReadTools(ctx, docker)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	catalogConfig, err := catalog.ReadConfig()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	if len(catalogNames) > 0 {
+		selected := map[string]catalog.Catalog{}
+		for _, name := range catalogNames {
+			entry, found := catalogConfig.Catalogs[name]
+			if !found {
+				return Backup{}, fmt.Errorf("catalog %q not found", name)
+			}
+			selected[name] = entry
+		}
+		catalogConfig.Catalogs = selected
+
+		catalogContent, err = json.MarshalIndent(catalogConfig, "", "  ")
+		if err != nil {
+			return Backup{}, err
+		}
+	}
+
+	catalogFiles := make(map[string]string)
+	for name := range catalogConfig.Catalogs {
+		catalogFileContent, err := config.ReadCatalogFile(name)
+		if err != nil {
+			return Backup{}, err
+		}
+		catalogFiles[name] = string(catalogFileContent)
+	}
+
+	return Backup{
 		Config:       string(configContent),
 		Registry:     string(registryContent),
 		Catalog:      string(catalogContent),
 		CatalogFiles: catalogFiles,
 		Tools:        string(toolsConfig),
-		Secrets:      secrets,
-		Policy:       policy,
-	}
-
-	return json.Marshal(backup)
+	}, nil
 }
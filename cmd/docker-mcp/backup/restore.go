@@ -15,6 +15,47 @@ func Restore(ctx context.Context, backupData []byte) error {
 		return err
 	}
 
+	if err := RestoreConfig(backup); err != nil {
+		return err
+	}
+
+	secretsClient := desktop.NewSecretsClient()
+
+	secretsBefore, err := secretsClient.ListJfsSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	secretsKeep := map[string]bool{}
+	for _, secret := range backup.Secrets {
+		if err := secretsClient.SetJfsSecret(ctx, desktop.Secret{
+			Name:     secret.Name,
+			Value:    secret.Value,
+			Provider: secret.Provider,
+		}); err != nil {
+			return err
+		}
+		secretsKeep[secret.Name] = true
+	}
+
+	for _, secret := range secretsBefore {
+		if !secretsKeep[secret.Name] {
+			if err := secretsClient.DeleteJfsSecret(ctx, secret.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := secretsClient.SetJfsPolicy(ctx, backup.Policy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreConfig restores the configuration files, without touching the secrets.
+// Catalogs that are not part of the backup are removed.
+func RestoreConfig(backup Backup) error {
 	if err := config.WriteConfig([]byte(backup.Config)); err != nil {
 		return err
 	}
@@ -50,36 +91,5 @@ func Restore(ctx context.Context, backupData []byte) error {
 		}
 	}
 
-	secretsClient := desktop.NewSecretsClient()
-
-	secretsBefore, err := secretsClient.ListJfsSecrets(ctx)
-	if err != nil {
-		return err
-	}
-
-	secretsKeep := map[string]bool{}
-	for _, secret := range backup.Secrets {
-		if err := secretsClient.SetJfsSecret(ctx, desktop.Secret{
-			Name:     secret.Name,
-			Value:    secret.Value,
-			Provider: secret.Provider,
-		}); err != nil {
-			return err
-		}
-		secretsKeep[secret.Name] = true
-	}
-
-	for _, secret := range secretsBefore {
-		if !secretsKeep[secret.Name] {
-			if err := secretsClient.DeleteJfsSecret(ctx, secret.Name); err != nil {
-				return err
-			}
-		}
-	}
-
-	if err := secretsClient.SetJfsPolicy(ctx, backup.Policy); err != nil {
-		return err
-	}
-
 	return nil
 }
@@ -0,0 +1,59 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, manifestFile), []byte(`{"version":1}`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, signaturesDir, "blobs", "sha256"), 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(src, signaturesDir, "blobs", "sha256", "abc"),
+		[]byte("blob"),
+		0o644,
+	))
+
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, writeArchive(src, archive))
+
+	dst := t.TempDir()
+	require.NoError(t, extractArchive(archive, dst))
+
+	var manifest Manifest
+	require.NoError(t, readJSON(filepath.Join(dst, manifestFile), &manifest))
+	assert.Equal(t, 1, manifest.Version)
+
+	blob, err := os.ReadFile(filepath.Join(dst, signaturesDir, "blobs", "sha256", "abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "blob", string(blob))
+}
+
+func TestExtractArchiveRejectsEscapingEntries(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "../evil",
+		Mode:     0o644,
+		Size:     4,
+		Typeflag: tar.TypeReg,
+	}))
+	_, err = tw.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	err = extractArchive(archive, t.TempDir())
+	require.ErrorContains(t, err, "invalid entry")
+}
@@ -0,0 +1,254 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/backup"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/gateway/proxies"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
+)
+
+type CreateOptions struct {
+	// Catalogs to include. All the configured catalogs when empty.
+	Catalogs []string
+	// EnabledOnly only includes the images of the servers enabled in the registry.
+	EnabledOnly bool
+}
+
+// Create packages the configuration, the catalogs, the images they reference and their signatures into
+// a single .tar.gz archive that can be loaded with Load on a host that has no network access.
+// Secrets are not included.
+func Create(ctx context.Context, docker docker.Client, output string, options CreateOptions) error {
+	cfg, err := backup.DumpConfig(ctx, docker, options.Catalogs)
+	if err != nil {
+		return err
+	}
+
+	images, err := referencedImages(ctx, cfg, options.EnabledOnly)
+	if err != nil {
+		return err
+	}
+	images = append(images, proxies.Images()...)
+
+	tmpDir, err := os.MkdirTemp("", "mcp-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fmt.Printf("Pulling %d image(s)...\n", len(images))
+	if err := docker.PullImages(ctx, images...); err != nil {
+		return err
+	}
+
+	fmt.Println("Saving images...")
+	if err := saveImages(ctx, docker, images, filepath.Join(tmpDir, imagesFile)); err != nil {
+		return err
+	}
+
+	fmt.Println("Fetching signatures...")
+	signatures, err := saveSignatures(ctx, images, filepath.Join(tmpDir, signaturesDir))
+	if err != nil {
+		return err
+	}
+
+	catalogNames := make([]string, 0, len(cfg.CatalogFiles))
+	for catalogName := range cfg.CatalogFiles {
+		catalogNames = append(catalogNames, catalogName)
+	}
+	sort.Strings(catalogNames)
+
+	if err := writeJSON(filepath.Join(tmpDir, configFile), cfg); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(tmpDir, manifestFile), Manifest{
+		Version:    Version,
+		Created:    time.Now().UTC(),
+		Catalogs:   catalogNames,
+		Images:     images,
+		Signatures: signatures,
+	}); err != nil {
+		return err
+	}
+
+	if err := writeArchive(tmpDir, output); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+
+	fmt.Printf("Bundle written to %s: %d catalog(s), %d image(s), %d signature(s)\n",
+		output, len(catalogNames), len(images), len(signatures))
+	return nil
+}
+
+// referencedImages lists the images of the servers and POCI tools of the catalogs of a configuration.
+func referencedImages(ctx context.Context, cfg backup.Backup, enabledOnly bool) ([]string, error) {
+	var catalogPaths []string
+	for catalogName := range cfg.CatalogFiles {
+		catalogPaths = append(catalogPaths, catalogName+".yaml")
+	}
+	sort.Strings(catalogPaths)
+
+	mcpCatalog, err := catalog.ReadFrom(ctx, catalogPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var enabled map[string]bool
+	if enabledOnly {
+		registry, err := config.ParseRegistryConfig([]byte(cfg.Registry))
+		if err != nil {
+			return nil, err
+		}
+		enabled = map[string]bool{}
+		for _, serverName := range registry.ServerNames() {
+			enabled[serverName] = true
+		}
+	}
+
+	unique := map[string]bool{}
+	for serverName, server := range mcpCatalog.Servers {
+		if enabledOnly && !enabled[serverName] {
+			continue
+		}
+		if server.Image != "" {
+			unique[server.Image] = true
+		}
+		for _, tool := range server.Tools {
+			if tool.Container.Image != "" {
+				unique[tool.Container.Image] = true
+			}
+		}
+	}
+
+	images := make([]string, 0, len(unique))
+	for image := range unique {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+func saveImages(ctx context.Context, docker docker.Client, images []string, path string) error {
+	out, err := docker.SaveImages(ctx, images...)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, out); err != nil {
+		return fmt.Errorf("saving docker images: %w", err)
+	}
+	return f.Close()
+}
+
+// saveSignatures stores the signatures of the mcp/ images in an OCI layout, each annotated with its tag.
+func saveSignatures(ctx context.Context, images []string, path string) ([]string, error) {
+	signaturesLayout, err := layout.Write(path, empty.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+
+	var signatures []string
+	for _, image := range images {
+		if !strings.HasPrefix(image, "mcp/") {
+			continue
+		}
+
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, err
+		}
+		digest := ref.Identifier()
+		if _, pinned := ref.(name.Digest); !pinned {
+			desc, err := remote.Head(ref, options...)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  no signature for %s: %s\n", image, err)
+				continue
+			}
+			digest = desc.Digest.String()
+		}
+
+		tag := mirror.SignatureTag(mirror.SignaturesRepository, digest)
+		sigRef, err := name.ParseReference(tag)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := remote.Image(sigRef, options...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  no signature for %s: %s\n", image, err)
+			continue
+		}
+
+		tagName := strings.TrimPrefix(tag, mirror.SignaturesRepository+":")
+		if err := signaturesLayout.AppendImage(sig, layout.WithAnnotations(map[string]string{
+			ocispec.AnnotationRefName: tagName,
+		})); err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, tagName)
+	}
+
+	return signatures, nil
+}
+
+func writeJSON(path string, v any) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0o644)
+}
+
+// writeArchive writes the content of a directory into a .tar.gz archive.
+func writeArchive(dir, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,186 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/backup"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+)
+
+type LoadOptions struct {
+	// SignaturesRepository is where to push the signatures of the bundle, usually the mirror of mcp/signatures.
+	// Signatures are not pushed when empty.
+	SignaturesRepository string
+}
+
+// Load restores a bundle created with Create: the configuration and catalogs are restored, the images
+// are loaded into the Docker engine and, optionally, the signatures are pushed to a local registry.
+func Load(ctx context.Context, docker docker.Client, input string, options LoadOptions) error {
+	tmpDir, err := os.MkdirTemp("", "mcp-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := extractArchive(input, tmpDir); err != nil {
+		return fmt.Errorf("reading bundle: %w", err)
+	}
+
+	var manifest Manifest
+	if err := readJSON(filepath.Join(tmpDir, manifestFile), &manifest); err != nil {
+		return fmt.Errorf("reading bundle manifest: %w", err)
+	}
+	if manifest.Version > Version {
+		return fmt.Errorf("bundle version %d is not supported, upgrade docker mcp", manifest.Version)
+	}
+
+	var cfg backup.Backup
+	if err := readJSON(filepath.Join(tmpDir, configFile), &cfg); err != nil {
+		return fmt.Errorf("reading bundle configuration: %w", err)
+	}
+
+	fmt.Printf("Loading %d image(s)...\n", len(manifest.Images))
+	images, err := os.Open(filepath.Join(tmpDir, imagesFile))
+	if err != nil {
+		return err
+	}
+	defer images.Close()
+	if err := docker.LoadImages(ctx, images); err != nil {
+		return err
+	}
+
+	if options.SignaturesRepository != "" && len(manifest.Signatures) > 0 {
+		repository := options.SignaturesRepository
+		fmt.Printf("Pushing %d signature(s) to %s...\n", len(manifest.Signatures), repository)
+		if err := pushSignatures(ctx, filepath.Join(tmpDir, signaturesDir), repository); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Restoring configuration and %d catalog(s)...\n", len(manifest.Catalogs))
+	return backup.RestoreConfig(cfg)
+}
+
+func pushSignatures(ctx context.Context, path, repository string) error {
+	signaturesLayout, err := layout.FromPath(path)
+	if err != nil {
+		return err
+	}
+	index, err := signaturesLayout.ImageIndex()
+	if err != nil {
+		return err
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	for _, desc := range indexManifest.Manifests {
+		tag := desc.Annotations[ocispec.AnnotationRefName]
+		if tag == "" {
+			continue
+		}
+
+		ref, err := name.NewTag(repository + ":" + tag)
+		if err != nil {
+			return err
+		}
+		sig, err := index.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := remote.Write(
+			ref,
+			sig,
+			remote.WithContext(ctx),
+			remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		); err != nil {
+			return fmt.Errorf("pushing signature %s: %w", ref, err)
+		}
+	}
+
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+// extractArchive extracts a .tar.gz archive into a directory, refusing entries that would escape it.
+func extractArchive(input, dir string) error {
+	f, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid entry %q", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := extractFile(tr, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %q", header.Name)
+		}
+	}
+}
+
+func extractFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,22 @@
+package bundle
+
+import "time"
+
+// Version is the version of the bundle format. It's bumped when a bundle can't be loaded by older versions.
+const Version = 1
+
+const (
+	manifestFile  = "manifest.json"
+	configFile    = "config.json"
+	imagesFile    = "images.tar"
+	signaturesDir = "signatures"
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version    int       `json:"version"`
+	Created    time.Time `json:"created"`
+	Catalogs   []string  `json:"catalogs"`
+	Images     []string  `json:"images"`
+	Signatures []string  `json:"signatures,omitempty"`
+}
@@ -15,6 +15,10 @@ type Catalog struct {
 	DisplayName string `json:"displayName"`
 	URL         string `json:"url,omitempty"`
 	LastUpdate  string `json:"lastUpdate,omitempty"`
+	// Digest is the manifest digest of a catalog imported from an OCI registry.
+	Digest string `json:"digest,omitempty"`
+	// VerifyKey is the public key used to verify the signature of a catalog imported from an OCI registry.
+	VerifyKey string `json:"verifyKey,omitempty"`
 }
 
 func ReadConfig() (*Config, error) {
@@ -9,9 +9,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/oci"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/tui"
 )
 
+type ImportOptions struct {
+	// VerifyKey is the path to a public key used to verify signed OCI catalogs.
+	VerifyKey string
+}
+
 func isValidURL(u string) bool {
 	parsedURL, err := url.ParseRequestURI(u)
 	if err != nil {
@@ -43,7 +49,11 @@ func DownloadFile(ctx context.Context, url string) ([]byte, error) {
 }
 
 func Import(ctx context.Context, nameOrURL string) error {
-	// Accept urls or catalog names.
+	return ImportWithOptions(ctx, nameOrURL, ImportOptions{})
+}
+
+func ImportWithOptions(ctx context.Context, nameOrURL string, opts ImportOptions) error {
+	// Accept urls, oci:// references or catalog names.
 	url := nameOrURL
 	if urlFromAlias, ok := aliasToURL[nameOrURL]; ok {
 		url = urlFromAlias
@@ -51,11 +61,24 @@ func Import(ctx context.Context, nameOrURL string) error {
 
 	var (
 		catalogContent []byte
+		digest         string
 		err            error
 	)
-	if isValidURL(url) {
+	switch {
+	case oci.IsCatalogRef(url):
+		if opts.VerifyKey != "" {
+			opts.VerifyKey, err = filepath.Abs(opts.VerifyKey)
+			if err != nil {
+				return fmt.Errorf("failed to get absolute path: %w", err)
+			}
+		}
+		var artifact oci.CatalogArtifact
+		artifact, err = pullCatalog(url, opts.VerifyKey)
+		catalogContent = artifact.Content
+		digest = artifact.Digest.DigestStr()
+	case isValidURL(url):
 		catalogContent, err = DownloadFile(ctx, url)
-	} else {
+	default:
 		url, err = filepath.Abs(url)
 		if err != nil {
 			return fmt.Errorf("failed to get absolute path: %w", err)
@@ -95,6 +118,8 @@ func Import(ctx context.Context, nameOrURL string) error {
 	cfg.Catalogs[metaData.Name] = Catalog{
 		DisplayName: metaData.DisplayName,
 		URL:         url,
+		Digest:      digest,
+		VerifyKey:   opts.VerifyKey,
 	}
 	if err := WriteConfig(cfg); err != nil {
 		return err
@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+)
+
+type lintReport struct {
+	Catalog  string            `json:"catalog"  yaml:"catalog"`
+	Errors   int               `json:"errors"   yaml:"errors"`
+	Warnings int               `json:"warnings" yaml:"warnings"`
+	Findings []catalog.Finding `json:"findings" yaml:"findings"`
+}
+
+// Lint checks a catalog file, or a configured catalog by name, and fails if any error is found.
+func Lint(_ context.Context, fileOrName string, format Format) error {
+	content, err := readFileOrCatalog(fileOrName)
+	if err != nil {
+		return err
+	}
+
+	findings, err := catalog.Lint(content)
+	if err != nil {
+		return err
+	}
+
+	report := lintReport{
+		Catalog:  fileOrName,
+		Findings: findings,
+	}
+	for _, finding := range findings {
+		if finding.Severity == catalog.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	if report.Findings == nil {
+		report.Findings = []catalog.Finding{}
+	}
+
+	switch format {
+	case JSON:
+		buf, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+	case YAML:
+		buf, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(buf))
+	default:
+		for _, finding := range findings {
+			location := ""
+			if finding.Path != "" {
+				location = finding.Path + ": "
+			}
+			fmt.Printf("%s: %s%s [%s]\n", finding.Severity, location, finding.Message, finding.Rule)
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("catalog %s has %d error(s)", fileOrName, report.Errors)
+	}
+	return nil
+}
+
+// PrintSchema prints the JSON Schema of the catalog format.
+func PrintSchema() {
+	fmt.Print(string(catalog.JSONSchema))
+}
+
+func readFileOrCatalog(fileOrName string) ([]byte, error) {
+	content, err := os.ReadFile(fileOrName)
+	if err == nil {
+		return content, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.Catalogs[fileOrName]; !ok {
+		return nil, fmt.Errorf("%q is neither a file nor a configured catalog", fileOrName)
+	}
+
+	return ReadCatalogFile(fileOrName)
+}
@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
+)
+
+// Mirror copies all the images referenced by a catalog to the registries given by the rewrite rules.
+// Signatures of the mcp/ images are copied too, so that the gateway can still verify them.
+func Mirror(ctx context.Context, fileOrName string, rules mirror.Rules, dryRun bool) error {
+	if len(rules) == 0 {
+		return errors.New("at least one --registry-mirror rule is required")
+	}
+
+	catalogPath, err := catalogFilePath(fileOrName)
+	if err != nil {
+		return err
+	}
+	mcpCatalog, err := catalog.ReadFrom(ctx, []string{catalogPath})
+	if err != nil {
+		return err
+	}
+
+	images := catalogImages(mcpCatalog)
+	signaturesRepository := rules.Rewrite(mirror.SignaturesRepository)
+
+	var failed []string
+	for _, image := range images {
+		target := rules.Rewrite(image)
+		if target == image {
+			fmt.Printf("Skipping %s: no rule matches\n", image)
+			continue
+		}
+
+		fmt.Printf("Copying %s to %s\n", image, target)
+		if dryRun {
+			continue
+		}
+
+		digest, err := mirror.Copy(ctx, image, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  failed: %s\n", err)
+			failed = append(failed, image)
+			continue
+		}
+
+		if strings.HasPrefix(image, "mcp/") && signaturesRepository != mirror.SignaturesRepository {
+			signature := mirror.SignatureTag(mirror.SignaturesRepository, digest)
+			if _, err := mirror.Copy(ctx, signature, mirror.SignatureTag(signaturesRepository, digest)); err != nil {
+				fmt.Fprintf(os.Stderr, "  no signature copied: %s\n", err)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to mirror %d image(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	fmt.Println()
+	fmt.Println("Run the gateway with the same rules to use the mirrored images:")
+	var flags []string
+	for _, rule := range rules {
+		flags = append(flags, fmt.Sprintf("--registry-mirror '%s=%s'", rule.From, rule.To))
+	}
+	fmt.Printf("  docker mcp gateway run %s\n", strings.Join(flags, " "))
+	return nil
+}
+
+// catalogImages lists the images of the servers and POCI tools of a catalog.
+func catalogImages(mcpCatalog catalog.Catalog) []string {
+	unique := map[string]bool{}
+	for _, server := range mcpCatalog.Servers {
+		if server.Image != "" {
+			unique[server.Image] = true
+		}
+		for _, tool := range server.Tools {
+			if tool.Container.Image != "" {
+				unique[tool.Container.Image] = true
+			}
+		}
+	}
+
+	images := make([]string, 0, len(unique))
+	for image := range unique {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// catalogFilePath returns the path of a catalog file, or of a configured catalog, as understood by catalog.ReadFrom.
+func catalogFilePath(fileOrName string) (string, error) {
+	if _, err := os.Stat(fileOrName); err == nil {
+		return filepath.Abs(fileOrName)
+	}
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := cfg.Catalogs[fileOrName]; !ok {
+		return "", fmt.Errorf("%q is neither a file nor a configured catalog", fileOrName)
+	}
+
+	return fileOrName + ".yaml", nil
+}
@@ -0,0 +1,57 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/oci"
+)
+
+// Push publishes a whole catalog as an OCI artifact so that it can be imported
+// with `docker mcp catalog import oci://<ref>` and pinned by digest.
+func Push(_ context.Context, name, ociRef, signKey string) error {
+	cfg, err := ReadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Catalogs[name]; !ok {
+		return fmt.Errorf("catalog %q not found", name)
+	}
+
+	content, err := ReadCatalogFile(name)
+	if err != nil {
+		return err
+	}
+	if len(content) == 0 {
+		return fmt.Errorf("catalog %q is empty", name)
+	}
+
+	dgst, err := oci.PushCatalog(ociRef, name, content)
+	if err != nil {
+		return fmt.Errorf("pushing catalog %q: %w", name, err)
+	}
+
+	if signKey != "" {
+		if err := oci.SignCatalog(dgst, name, signKey); err != nil {
+			return fmt.Errorf("signing catalog %q: %w", name, err)
+		}
+	}
+
+	fmt.Printf("pushed catalog %s to %s%s\n", name, oci.CatalogRefPrefix, dgst.String())
+	return nil
+}
+
+func pullCatalog(ociRef, verifyKey string) (oci.CatalogArtifact, error) {
+	artifact, err := oci.PullCatalog(ociRef)
+	if err != nil {
+		return oci.CatalogArtifact{}, err
+	}
+
+	if verifyKey != "" {
+		if err := oci.VerifyCatalog(artifact.Digest, verifyKey); err != nil {
+			return oci.CatalogArtifact{}, fmt.Errorf("verifying %s: %w", ociRef, err)
+		}
+	}
+
+	return artifact, nil
+}
@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"gopkg.in/yaml.v3"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/yq"
 )
 
@@ -52,7 +54,8 @@ func SupportedFormats() string {
 	return strings.Join(quoted, ", ")
 }
 
-func Show(ctx context.Context, name string, format Format) error {
+// Show prints a catalog. When resolved is true, the catalogs it extends are applied and the source of every field is shown.
+func Show(ctx context.Context, name string, format Format, resolved bool) error {
 	cfg, err := ReadConfigWithDefaultCatalog(ctx)
 	if err != nil {
 		return err
@@ -88,6 +91,10 @@ func Show(ctx context.Context, name string, format Format) error {
 		}
 	}
 
+	if resolved {
+		return showResolved(ctx, name, format)
+	}
+
 	data, err := ReadCatalogFile(name)
 	if err != nil {
 		return err
@@ -125,6 +132,101 @@ func Show(ctx context.Context, name string, format Format) error {
 	return nil
 }
 
+func showResolved(ctx context.Context, name string, format Format) error {
+	servers, err := catalog.Resolve(ctx, name+".yaml")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case JSON:
+		buf, err := json.MarshalIndent(servers, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+		return nil
+	case YAML:
+		buf, err := yaml.Marshal(servers)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(buf))
+		return nil
+	}
+
+	names := make([]string, 0, len(servers))
+	for serverName := range servers {
+		names = append(names, serverName)
+	}
+	sort.Strings(names)
+
+	for _, serverName := range names {
+		fmt.Printf("%s:\n", serverName)
+		fields, err := formatResolvedServer(servers[serverName])
+		if err != nil {
+			return err
+		}
+		fmt.Print(fields)
+	}
+	return nil
+}
+
+// formatResolvedServer prints a server as YAML, each top level field annotated with the catalog(s) it comes from.
+func formatResolvedServer(server catalog.ResolvedServer) (string, error) {
+	buf, err := yaml.Marshal(server.Server)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]any
+	if err := yaml.Unmarshal(buf, &fields); err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var out strings.Builder
+	for _, key := range keys {
+		buf, err := yaml.Marshal(map[string]any{key: fields[key]})
+		if err != nil {
+			return "", err
+		}
+
+		lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+		for i, line := range lines {
+			out.WriteString("  " + line)
+			if source := fieldSource(server, key); i == 0 && source != "" {
+				out.WriteString("  # " + source)
+			}
+			out.WriteString("\n")
+		}
+	}
+
+	return out.String(), nil
+}
+
+// fieldSource tells where a top level field comes from. When parts of it come from different catalogs, they are all listed.
+func fieldSource(server catalog.ResolvedServer, key string) string {
+	if source, ok := server.Sources[key]; ok {
+		return source
+	}
+
+	seen := map[string]bool{}
+	var sources []string
+	for _, path := range server.SortedSourceKeys() {
+		if !strings.HasPrefix(path, key+".") || seen[server.Sources[path]] {
+			continue
+		}
+		seen[server.Sources[path]] = true
+		sources = append(sources, server.Sources[path])
+	}
+	return strings.Join(sources, ", ")
+}
+
 func getSortedKeys(m map[string]Tile) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
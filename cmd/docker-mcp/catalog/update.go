@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"time"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/oci"
 )
 
 func Update(ctx context.Context, args []string) error {
@@ -52,6 +54,7 @@ func updateCatalog(ctx context.Context, name string, catalog Catalog) error {
 
 	var (
 		catalogContent []byte
+		digest         string
 		err            error
 	)
 	// For the docker catalog, use the default URL if none is set
@@ -59,9 +62,15 @@ func updateCatalog(ctx context.Context, name string, catalog Catalog) error {
 		url = DockerCatalogURL
 	}
 
-	if isValidURL(url) {
+	switch {
+	case oci.IsCatalogRef(url):
+		var artifact oci.CatalogArtifact
+		artifact, err = pullCatalog(url, catalog.VerifyKey)
+		catalogContent = artifact.Content
+		digest = artifact.Digest.DigestStr()
+	case isValidURL(url):
 		catalogContent, err = DownloadFile(ctx, url)
-	} else {
+	default:
 		catalogContent, err = os.ReadFile(url)
 	}
 	if err != nil {
@@ -76,6 +85,8 @@ func updateCatalog(ctx context.Context, name string, catalog Catalog) error {
 		DisplayName: catalog.DisplayName,
 		URL:         catalog.URL,
 		LastUpdate:  time.Now().Format(time.RFC3339),
+		Digest:      digest,
+		VerifyKey:   catalog.VerifyKey,
 	}
 	if err := WriteConfig(cfg); err != nil {
 		return err
@@ -86,7 +86,7 @@ func (e *ErrVendorNotFound) Error() string {
 
 type Updater func(key string, server *MCPServerSTDIO) error
 
-func newMCPGatewayServer() *MCPServerSTDIO {
+func newMCPGatewayServer(remote *RemoteGateway) *MCPServerSTDIO {
 	var env map[string]string
 	if runtime.GOOS == "windows" {
 		// As of 0.9.3, Claude Desktop locks down environment variables that CLI plugins need.
@@ -96,9 +96,13 @@ func newMCPGatewayServer() *MCPServerSTDIO {
 			"ProgramData":  os.Getenv("ProgramData"),
 		}
 	}
+	args := []string{"mcp", "gateway", "run"}
+	if remote != nil {
+		args = remote.args()
+	}
 	return &MCPServerSTDIO{
 		Command: "docker",
-		Args:    []string{"mcp", "gateway", "run"},
+		Args:    args,
 		Env:     env,
 	}
 }
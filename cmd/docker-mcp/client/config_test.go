@@ -237,3 +237,19 @@ func getYQProcessor(t *testing.T, cfg any) yqProcessor {
 		return yqProcessor{}
 	}
 }
+
+func TestNewMCPGatewayServer(t *testing.T) {
+	assert.Equal(t, []string{"mcp", "gateway", "run"}, newMCPGatewayServer(nil).Args)
+
+	server := newMCPGatewayServer(&RemoteGateway{
+		URL:             "https://gateway.example.com/mcp",
+		Servers:         []string{"fetch", "github"},
+		AuthTokenSecret: "gateway-token",
+	})
+	assert.Equal(t, "docker", server.Command)
+	assert.Equal(t, []string{
+		"mcp", "gateway", "connect", "https://gateway.example.com/mcp",
+		"--servers", "fetch,github",
+		"--auth-token-secret", "gateway-token",
+	}, server.Args)
+}
@@ -2,17 +2,47 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// RemoteGateway is a gateway running elsewhere, that clients reach through
+// 'docker mcp gateway connect'.
+type RemoteGateway struct {
+	URL             string
+	Transport       string
+	Servers         []string
+	AuthTokenSecret string
+}
+
+func (r *RemoteGateway) args() []string {
+	args := []string{"mcp", "gateway", "connect", r.URL}
+	if r.Transport != "" {
+		args = append(args, "--transport", r.Transport)
+	}
+	if len(r.Servers) > 0 {
+		args = append(args, "--servers", strings.Join(r.Servers, ","))
+	}
+	if r.AuthTokenSecret != "" {
+		args = append(args, "--auth-token-secret", r.AuthTokenSecret)
+	}
+	return args
+}
+
+// Connect configures a client to use the local gateway or, if remote is not nil, a remote one.
 func Connect(
 	ctx context.Context,
 	cwd string,
 	config Config,
 	vendor string,
 	global, quiet bool,
+	remote *RemoteGateway,
 ) error {
 	if vendor == vendorGordon && global {
+		if remote != nil {
+			return errors.New("only the local gateway can be connected to Ask Gordon")
+		}
 		if err := connectGordon(ctx); err != nil {
 			return err
 		}
@@ -21,7 +51,7 @@ func Connect(
 		if err != nil {
 			return err
 		}
-		if err := updater(DockerMCPCatalog, newMCPGatewayServer()); err != nil {
+		if err := updater(DockerMCPCatalog, newMCPGatewayServer(remote)); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/bundle"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+)
+
+func bundleCommand(docker docker.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Move a working setup to hosts without network access",
+	}
+
+	cmd.AddCommand(createBundleCommand(docker))
+	cmd.AddCommand(loadBundleCommand(docker))
+	return cmd
+}
+
+func createBundleCommand(docker docker.Client) *cobra.Command {
+	var opts bundle.CreateOptions
+	cmd := &cobra.Command{
+		Use:   "create <file>",
+		Short: "Package catalogs, configuration and images into an archive",
+		Long: `Package the catalogs, the registry, config and tools files, the images of the servers and
+POCI tools, the network proxy images and the signatures of the mcp/ images into a single .tar.gz
+archive. Secrets are not included.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  # Package everything
+  docker mcp bundle create mcp-bundle.tar.gz
+
+  # Package only the images of the enabled servers of the Docker catalog
+  docker mcp bundle create mcp-bundle.tar.gz --catalog docker-mcp --enabled-only`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bundle.Create(cmd.Context(), docker, args[0], opts)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringSliceVar(&opts.Catalogs, "catalog", nil, "Catalogs to include (default is all the configured catalogs)")
+	flags.BoolVar(&opts.EnabledOnly, "enabled-only", false, "Only include the images of the enabled servers")
+	return cmd
+}
+
+func loadBundleCommand(docker docker.Client) *cobra.Command {
+	var opts bundle.LoadOptions
+	cmd := &cobra.Command{
+		Use:   "load <file>",
+		Short: "Restore an archive created with bundle create",
+		Long: `Restore the catalogs and the registry, config and tools files of a bundle, replacing the current
+ones, and load its images into the Docker engine. Signatures can be pushed to a local registry so
+that the gateway can verify the images with --verify-signatures.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  # Restore a bundle
+  docker mcp bundle load mcp-bundle.tar.gz
+
+  # Also push the signatures to a local mirror of mcp/signatures
+  docker mcp bundle load mcp-bundle.tar.gz --signatures-repository registry.corp/mcp-mirror/signatures
+  docker mcp gateway run --registry-mirror 'docker.io/mcp/signatures=registry.corp/mcp-mirror/signatures' --verify-signatures`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bundle.Load(cmd.Context(), docker, args[0], opts)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.SignaturesRepository, "signatures-repository", "", "Repository to push the signatures to")
+	return cmd
+}
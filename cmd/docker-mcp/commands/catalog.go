@@ -9,6 +9,7 @@ import (
 
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/catalog"
 	catalogTypes "github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/yq"
 )
 
@@ -22,10 +23,13 @@ func catalogCommand() *cobra.Command {
 	cmd.AddCommand(bootstrapCatalogCommand())
 	cmd.AddCommand(importCatalogCommand())
 	cmd.AddCommand(exportCatalogCommand())
+	cmd.AddCommand(pushCatalogCommand())
 	cmd.AddCommand(lsCatalogCommand())
 	cmd.AddCommand(rmCatalogCommand())
 	cmd.AddCommand(updateCatalogCommand())
 	cmd.AddCommand(showCatalogCommand())
+	cmd.AddCommand(lintCatalogCommand())
+	cmd.AddCommand(mirrorCatalogCommand())
 	cmd.AddCommand(forkCatalogCommand())
 	cmd.AddCommand(createCatalogCommand())
 	cmd.AddCommand(initCatalogCommand())
@@ -37,12 +41,16 @@ func catalogCommand() *cobra.Command {
 func importCatalogCommand() *cobra.Command {
 	var mcpRegistry string
 	var dryRun bool
+	var verifyKey string
 	cmd := &cobra.Command{
-		Use:   "import <alias|url|file>",
-		Short: "Import a catalog from URL or file",
-		Long: `Import an MCP server catalog from a URL or local file. The catalog will be downloaded 
+		Use:   "import <alias|url|file|oci://ref>",
+		Short: "Import a catalog from URL, file or OCI registry",
+		Long: `Import an MCP server catalog from a URL, local file or OCI registry. The catalog will be downloaded 
 and stored locally for use with the MCP gateway.
 
+OCI references are prefixed with oci:// and can be pinned by tag or digest. When --verify-key
+is used, the catalog's signature is verified, now and on every update.
+
 When --mcp-registry flag is used, the argument must be an existing catalog name, and the
 command will import servers from the MCP registry URL into that catalog.`,
 		Args: cobra.ExactArgs(1),
@@ -51,6 +59,9 @@ command will import servers from the MCP registry URL into that catalog.`,
   
   # Import from local file
   docker mcp catalog import ./shared-catalog.yaml
+
+  # Import from an OCI registry, pinned by digest and verified
+  docker mcp catalog import oci://registry.example.com/mcp/team-catalog@sha256:... --verify-key ./cosign.pub
   
   # Import from MCP registry URL into existing catalog
   docker mcp catalog import my-catalog --mcp-registry https://registry.example.com/server`,
@@ -63,13 +74,44 @@ command will import servers from the MCP registry URL into that catalog.`,
 				return importMCPRegistryToCatalog(cmd.Context(), args[0], mcpRegistry)
 			}
 			// Default behavior: import entire catalog
-			return catalog.Import(cmd.Context(), args[0])
+			return catalog.ImportWithOptions(cmd.Context(), args[0], catalog.ImportOptions{
+				VerifyKey: verifyKey,
+			})
 		},
 	}
 	cmd.Flags().
 		StringVar(&mcpRegistry, "mcp-registry", "", "Import server from MCP registry URL into existing catalog")
 	cmd.Flags().
 		BoolVar(&dryRun, "dry-run", false, "Show Imported Data but do not update the Catalog")
+	cmd.Flags().
+		StringVar(&verifyKey, "verify-key", "", "Public key used to verify the signature of an OCI catalog")
+	return cmd
+}
+
+func pushCatalogCommand() *cobra.Command {
+	var opts struct {
+		SignKey string
+	}
+	cmd := &cobra.Command{
+		Use:   "push <name> <oci-ref>",
+		Short: "Push a catalog to an OCI registry",
+		Long: `Push a whole catalog to an OCI registry as an artifact. The catalog can then be
+imported by other users with 'docker mcp catalog import oci://<ref>' and pinned by digest.
+
+When --sign-key is used, a signature is pushed next to the catalog. Set
+DOCKER_MCP_SIGNING_PASSWORD if the private key is encrypted.`,
+		Args: cobra.ExactArgs(2),
+		Example: `  # Push a catalog with a tag
+  docker mcp catalog push team-servers registry.example.com/mcp/team-catalog:v1
+
+  # Push and sign a catalog
+  docker mcp catalog push team-servers registry.example.com/mcp/team-catalog:v1 --sign-key ./cosign.key`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return catalog.Push(cmd.Context(), args[0], args[1], opts.SignKey)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.SignKey, "sign-key", "", "Private key used to sign the catalog")
 	return cmd
 }
 
@@ -145,30 +187,112 @@ If no name is provided, updates all catalogs that have remote sources.`,
 
 func showCatalogCommand() *cobra.Command {
 	var opts struct {
-		Format catalog.Format
+		Format   catalog.Format
+		Resolved bool
 	}
 	cmd := &cobra.Command{
 		Use:   "show [name]",
 		Short: "Display catalog contents",
 		Long: `Display the contents of a catalog including all server definitions and metadata.
-If no name is provided, shows the Docker official catalog.`,
+If no name is provided, shows the Docker official catalog.
+
+With --resolved, the catalogs it extends are applied and each field is annotated with the
+catalog it comes from.`,
 		Args: cobra.MaximumNArgs(1),
 		Example: `  # Show Docker's official catalog
   docker mcp catalog show
   
   # Show a specific catalog in JSON format
-  docker mcp catalog show my-catalog --format=json`,
+  docker mcp catalog show my-catalog --format=json
+
+  # Show the effective servers of a catalog that extends another one
+  docker mcp catalog show team-overlay --resolved`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := catalog.DockerCatalogName
 			if len(args) > 0 {
 				name = args[0]
 			}
 
-			return catalog.Show(cmd.Context(), name, opts.Format)
+			return catalog.Show(cmd.Context(), name, opts.Format, opts.Resolved)
+		},
+	}
+	flags := cmd.Flags()
+	flags.Var(&opts.Format, "format", fmt.Sprintf("Supported: %s.", catalog.SupportedFormats()))
+	flags.BoolVar(&opts.Resolved, "resolved", false, "Apply the catalogs it extends and show where each field comes from")
+	return cmd
+}
+
+func mirrorCatalogCommand() *cobra.Command {
+	var opts struct {
+		Rules  mirror.Rules
+		DryRun bool
+	}
+	cmd := &cobra.Command{
+		Use:   "mirror [file|name]",
+		Short: "Copy the images of a catalog to another registry",
+		Long: `Copy all the images referenced by a catalog, servers and POCI tools, to the registries given
+by the rewrite rules. Signatures of the mcp/ images are copied too, so that they can still be
+verified. Run the gateway with the same --registry-mirror rules to use the copies.
+If no catalog is provided, mirrors the Docker official catalog.`,
+		Args: cobra.MaximumNArgs(1),
+		Example: `  # Mirror the Docker official catalog to a private registry
+  docker mcp catalog mirror --registry-mirror 'docker.io/mcp/*=registry.corp/mcp-mirror/*'
+
+  # Show what would be copied
+  docker mcp catalog mirror my-catalog --registry-mirror 'docker.io/*=registry.corp/hub/*' --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := catalog.DockerCatalogName
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return catalog.Mirror(cmd.Context(), name, opts.Rules, opts.DryRun)
+		},
+	}
+	flags := cmd.Flags()
+	flags.Var(&opts.Rules, "registry-mirror", "Rewrite rule, e.g. 'docker.io/mcp/*=registry.corp/mcp-mirror/*' (can be repeated)")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Show what would be copied without copying anything")
+	_ = cmd.MarkFlagRequired("registry-mirror")
+	return cmd
+}
+
+func lintCatalogCommand() *cobra.Command {
+	var opts struct {
+		Format catalog.Format
+		Schema bool
+	}
+	cmd := &cobra.Command{
+		Use:   "lint [file|name]",
+		Short: "Check a catalog for errors",
+		Long: `Check a catalog file, or a configured catalog, for problems that would otherwise only
+show up when the gateway runs: unknown or misspelled fields, secrets without env, {{...}} templates
+that don't match the config schema, unknown template functions, duplicate tools and insecure settings.
+
+The command fails if any error is found. Use --schema to print the JSON Schema of the catalog format.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.Schema {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		Example: `  # Lint a catalog file
+  docker mcp catalog lint ./my-catalog.yaml
+
+  # Lint a configured catalog with machine-readable output
+  docker mcp catalog lint team-servers --format=json
+
+  # Print the catalog JSON Schema
+  docker mcp catalog lint --schema > catalog.schema.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Schema {
+				catalog.PrintSchema()
+				return nil
+			}
+			return catalog.Lint(cmd.Context(), args[0], opts.Format)
 		},
 	}
 	flags := cmd.Flags()
 	flags.Var(&opts.Format, "format", fmt.Sprintf("Supported: %s.", catalog.SupportedFormats()))
+	flags.BoolVar(&opts.Schema, "schema", false, "Print the JSON Schema of the catalog format")
 	return cmd
 }
 
@@ -51,6 +51,7 @@ func connectClientCommand(cwd string, cfg client.Config) *cobra.Command {
 	var opts struct {
 		Global bool
 		Quiet  bool
+		Remote client.RemoteGateway
 	}
 	cmd := &cobra.Command{
 		Use: fmt.Sprintf(
@@ -63,12 +64,20 @@ func connectClientCommand(cwd string, cfg client.Config) *cobra.Command {
 		),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return client.Connect(cmd.Context(), cwd, cfg, args[0], opts.Global, opts.Quiet)
+			var remote *client.RemoteGateway
+			if opts.Remote.URL != "" {
+				remote = &opts.Remote
+			}
+			return client.Connect(cmd.Context(), cwd, cfg, args[0], opts.Global, opts.Quiet, remote)
 		},
 	}
 	flags := cmd.Flags()
 	addGlobalFlag(flags, &opts.Global)
 	addQuietFlag(flags, &opts.Quiet)
+	flags.StringVar(&opts.Remote.URL, "gateway-url", "", "Connect the client to the remote gateway at this URL, instead of a local gateway")
+	flags.StringVar(&opts.Remote.Transport, "gateway-transport", "", "Transport of the remote gateway: streaming (default) or sse")
+	flags.StringSliceVar(&opts.Remote.Servers, "gateway-servers", nil, "Servers to enable on a remote gateway running in central mode")
+	flags.StringVar(&opts.Remote.AuthTokenSecret, "gateway-auth-token-secret", "", "Name of the secret holding the bearer token sent to the remote gateway")
 	return cmd
 }
 
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/command"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/bridge"
 	catalogTypes "github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/gateway"
@@ -80,7 +83,9 @@ func gatewayCommand(docker docker.Client, dockerCli command.Cli) *cobra.Command
 
 			if options.Central {
 				options.Watch = false
-				options.Transport = "streaming"
+				if options.Transport != "websocket" && options.Transport != "ws" {
+					options.Transport = "streaming"
+				}
 			}
 
 			if options.Transport == "stdio" {
@@ -177,7 +182,8 @@ func gatewayCommand(docker docker.Client, dockerCli command.Cli) *cobra.Command
 	runCmd.Flags().
 		StringSliceVar(&options.ToolNames, "tools", options.ToolNames, "List of tools to enable")
 	runCmd.Flags().
-		StringArrayVar(&options.Interceptors, "interceptor", options.Interceptors, "List of interceptors to use (format: when:type:path, e.g. 'before:exec:/bin/path')")
+		StringArrayVar(&options.Interceptors, "interceptor", options.Interceptors, "List of interceptors to use (format: when[options]:type:path, e.g. 'before:exec:/bin/path' or 'after[method=resources/read,server=github*,fail=open]:http:localhost:8080/hook')")
+	runCmd.Flags().StringVar(&options.InterceptorsFile, "interceptors-file", options.InterceptorsFile, "Path to a YAML file listing interceptors")
 	runCmd.Flags().
 		StringArrayVar(&options.OciRef, "oci-ref", options.OciRef, "OCI image references to use")
 	runCmd.Flags().
@@ -185,15 +191,89 @@ func gatewayCommand(docker docker.Client, dockerCli command.Cli) *cobra.Command
 	runCmd.Flags().
 		IntVar(&options.Port, "port", options.Port, "TCP port to listen on (default is to listen on stdio)")
 	runCmd.Flags().
-		StringVar(&options.Transport, "transport", options.Transport, "stdio, sse or streaming (default is stdio)")
+		StringVar(&options.Transport, "transport", options.Transport, "stdio, sse, streaming or websocket (default is stdio)")
 	runCmd.Flags().
 		BoolVar(&options.LogCalls, "log-calls", options.LogCalls, "Log calls to the tools")
 	runCmd.Flags().
 		BoolVar(&options.BlockSecrets, "block-secrets", options.BlockSecrets, "Block secrets from being/received sent to/from tools")
+	runCmd.Flags().
+		StringVar(&options.SecretScanMode, "secret-scan-mode", "block", "What to do with the secrets sent to/received from tools: block the calls or redact the secrets")
+	runCmd.Flags().
+		StringVar(&options.SecretScanRules, "secret-scan-rules", options.SecretScanRules, "Path to a gitleaks compatible TOML file with extra rules to find secrets")
+	runCmd.Flags().
+		Float64Var(&options.SecretScanEntropy, "secret-scan-entropy", options.SecretScanEntropy, "Minimum Shannon entropy of the tokens considered secrets, e.g. 4.5 (0 to only use rules)")
+	runCmd.Flags().
+		StringSliceVar(&options.SecretScanAllow, "secret-scan-allow", nil, "Secrets a server can send/receive, as server=rule, e.g. 'github=github-pat' (can be repeated)")
+	runCmd.Flags().
+		StringArrayVar(&options.PIIPolicies, "pii-policy", nil, "What to do with the personal data sent to/received from a server, as server[:category,...]=allow|mask|tokenize|block, e.g. 'crm:email,phone=tokenize' (can be repeated, first match wins)")
+	runCmd.Flags().
+		StringSliceVar(&options.PIILocales, "pii-locales", nil, "Locales of the phone numbers and national IDs to detect: us, gb, fr, es (none by default)")
+	runCmd.Flags().
+		StringVar(&options.PIIAuditLog, "pii-audit-log", options.PIIAuditLog, "Path to a file where the categories of personal data seen are recorded, as JSON lines")
+	runCmd.Flags().
+		StringArrayVar(&options.PromptInjection, "prompt-injection", nil, "How to handle possible prompt injections in the content returned by a server, as [server=]off|annotate|strip|wrap, e.g. 'fetch=wrap' (can be repeated, first match wins)")
+	runCmd.Flags().
+		StringArrayVar(&options.ResultLimits, "result-limit", nil, "Maximum size of the text and structured content returned by the tools of a server, as [server=]bytes[:truncate|spill], e.g. 'fetch=100000:spill' (can be repeated, first match wins)")
+	runCmd.Flags().
+		DurationVar(&options.ResultRetention, "result-retention", time.Hour, "How long the results that spill over are kept for the clients to read them")
+	runCmd.Flags().
+		Int64Var(&options.ResultStoreMaxBytes, "result-store-max-bytes", 256<<20, "Maximum size of the kept results, the oldest are dropped first")
+	runCmd.Flags().
+		Int64Var(&options.ResultSessionMaxBytes, "result-session-max-bytes", 64<<20, "Maximum size of the kept results of each client session, the oldest are dropped first")
+	runCmd.Flags().
+		StringVar(&options.Record, "record", "", "Record the exchanges with each server in a directory, as <server>.jsonl")
+	runCmd.Flags().
+		StringVar(&options.Replay, "replay", "", "Answer with the exchanges recorded in a directory instead of running the servers")
+	runCmd.Flags().
+		StringVar(&options.ReplayMatch, "replay-match", "exact", "How requests are matched with the recorded requests: exact, or fuzzy to fall back to the closest recorded request for the same tool, prompt or resource")
+	runCmd.Flags().
+		StringArrayVar(&options.ReplayIgnore, "replay-ignore", nil, "Params not compared when matching the recorded requests, as a dotted path, e.g. 'arguments.requestId' or 'arguments.*.id' (can be repeated)")
 	runCmd.Flags().
 		BoolVar(&options.BlockNetwork, "block-network", options.BlockNetwork, "Block tools from accessing forbidden network resources")
 	runCmd.Flags().
 		BoolVar(&options.VerifySignatures, "verify-signatures", options.VerifySignatures, "Verify signatures of the server images")
+	runCmd.Flags().
+		Var(&options.RegistryMirrors, "registry-mirror", "Rewrite rule for server images, e.g. 'docker.io/mcp/*=registry.corp/mcp-mirror/*' (can be repeated)")
+	runCmd.Flags().
+		BoolVar(&options.DisableSampling, "disable-sampling", options.DisableSampling, "Reject sampling requests from the servers instead of forwarding them to the client")
+	runCmd.Flags().
+		StringSliceVar(&options.SamplingDisabledServers, "sampling-disabled-servers", nil, "Names of the servers that are not allowed to request sampling")
+	runCmd.Flags().
+		Int64Var(&options.SamplingMaxTokens, "sampling-max-tokens", options.SamplingMaxTokens, "Maximum number of tokens a server can request when sampling (0 for no limit)")
+	runCmd.Flags().
+		StringSliceVar(&options.SamplingAllowedModels, "sampling-allowed-models", nil, "Model hints servers are allowed to request when sampling, e.g. 'claude-*' (default is any model)")
+	runCmd.Flags().
+		StringVar(&options.TLSCert, "tls-cert", options.TLSCert, "Path to the TLS certificate used to serve the HTTP transports")
+	runCmd.Flags().
+		StringVar(&options.TLSKey, "tls-key", options.TLSKey, "Path to the private key of the TLS certificate (both files are reloaded when they change)")
+	runCmd.Flags().
+		BoolVar(&options.TLSSelfSigned, "tls-self-signed", options.TLSSelfSigned, "Serve the HTTP transports over TLS with a generated self-signed certificate, for local use")
+	runCmd.Flags().
+		StringVar(&options.EventStore, "event-store", "memory", "Where the streaming transport keeps events for clients resuming a stream with Last-Event-ID: memory or disk")
+	runCmd.Flags().
+		StringVar(&options.EventStorePath, "event-store-path", "events", "Directory of the disk event store (absolute or relative to ~/.docker/mcp/)")
+	runCmd.Flags().
+		DurationVar(&options.EventRetention, "event-retention", time.Hour, "How long the events are kept for clients resuming a stream")
+	runCmd.Flags().
+		Int64Var(&options.EventStoreMaxBytes, "event-store-max-bytes", 64<<20, "Maximum size of the kept events, the oldest are dropped first")
+	runCmd.Flags().
+		StringVar(&options.SharedState, "shared-state", options.SharedState, "Redis address, e.g. redis://:password@redis:6379/0, where replicas behind a load balancer share their sessions and long-lived servers")
+	runCmd.Flags().
+		StringVar(&options.ReplicaID, "replica-id", options.ReplicaID, "Name of this replica in the shared state (default is the hostname)")
+	runCmd.Flags().
+		StringVar(&options.ReplicaURL, "replica-url", options.ReplicaURL, "URL where the other replicas can forward the requests of the sessions owned by this replica, e.g. http://10.0.0.5:8811")
+	runCmd.Flags().
+		StringSliceVar(&options.AuthTokenSecrets, "auth-token-secret", nil, "Names of the secrets holding the bearer tokens accepted by the HTTP transports")
+	runCmd.Flags().
+		StringVar(&options.AuthClientCA, "auth-client-ca", options.AuthClientCA, "Path to the CA certificates used to authenticate clients by certificate (mTLS, requires --tls-cert)")
+	runCmd.Flags().
+		StringVar(&options.AuthIssuer, "auth-oauth-issuer", options.AuthIssuer, "OAuth authorization server whose access tokens are accepted")
+	runCmd.Flags().
+		StringVar(&options.AuthJWKSURL, "auth-oauth-jwks-url", options.AuthJWKSURL, "URL of the keys used to validate the OAuth access tokens")
+	runCmd.Flags().
+		StringVar(&options.AuthAudience, "auth-oauth-audience", options.AuthAudience, "Audience the OAuth access tokens must be issued for, usually the URL of the gateway (required with --auth-oauth-jwks-url)")
+	runCmd.Flags().
+		StringSliceVar(&options.AuthScopes, "auth-oauth-scopes", nil, "Scopes required in the OAuth access tokens")
 	runCmd.Flags().
 		BoolVar(&options.DryRun, "dry-run", options.DryRun, "Start the gateway but do not listen for connections (useful for testing the configuration)")
 	runCmd.Flags().BoolVar(&options.Verbose, "verbose", options.Verbose, "Verbose output")
@@ -213,8 +293,59 @@ func gatewayCommand(docker docker.Client, dockerCli command.Cli) *cobra.Command
 	runCmd.Flags().
 		BoolVar(&options.Central, "central", options.Central, "In central mode, clients tell us which servers to enable")
 	_ = runCmd.Flags().MarkHidden("central")
+	runCmd.Flags().
+		DurationVar(&options.SessionIdleTimeout, "session-idle-timeout", 30*time.Minute, "In central mode, close the servers of a selection after this much time without requests (0 to keep them)")
+	_ = runCmd.Flags().MarkHidden("session-idle-timeout")
 
 	cmd.AddCommand(runCmd)
+	cmd.AddCommand(connectGatewayCommand(docker))
+
+	return cmd
+}
+
+func connectGatewayCommand(docker docker.Client) *cobra.Command {
+	var options bridge.Options
+	var authTokenSecret string
+	var headers []string
+
+	cmd := &cobra.Command{
+		Use:   "connect <url>",
+		Short: "Connect a stdio MCP client to a remote gateway",
+		Long:  "Act as a local stdio MCP server that proxies to a gateway running with the streaming or sse transport, for clients that only support stdio.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.URL = args[0]
+			options.Headers = map[string]string{}
+			for _, header := range headers {
+				name, value, ok := strings.Cut(header, ":")
+				if !ok {
+					return fmt.Errorf("invalid header %q, expected 'Name: value'", header)
+				}
+				options.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+
+			if authTokenSecret != "" {
+				secrets, err := docker.ReadSecrets(cmd.Context(), []string{authTokenSecret}, false)
+				if err != nil {
+					return fmt.Errorf("reading secret %s: %w", authTokenSecret, err)
+				}
+				token, ok := secrets[authTokenSecret]
+				if !ok || token == "" {
+					return fmt.Errorf("secret %s not found", authTokenSecret)
+				}
+				options.Headers["Authorization"] = "Bearer " + token
+			}
+
+			return bridge.New(options).Run(cmd.Context(), &mcp.StdioTransport{})
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.Transport, "transport", "streaming", "Transport of the remote gateway: streaming or sse")
+	flags.StringSliceVar(&options.Servers, "servers", nil, "Servers to enable on a gateway running in central mode")
+	flags.StringVar(&authTokenSecret, "auth-token-secret", "", "Name of the secret holding the bearer token sent to the gateway")
+	flags.StringArrayVar(&headers, "header", nil, "Header sent to the gateway, e.g. 'X-Api-Key: value'")
+	flags.DurationVar(&options.ReconnectDelay, "reconnect-delay", time.Second, "Delay before reconnecting to the gateway, doubled after each failed attempt")
 
 	return cmd
 }
@@ -82,6 +82,7 @@ func Root(ctx context.Context, cwd string, dockerCli command.Cli) *cobra.Command
 
 	dockerClient := docker.NewClient(dockerCli)
 
+	cmd.AddCommand(bundleCommand(dockerClient))
 	cmd.AddCommand(catalogCommand())
 	cmd.AddCommand(clientCommand(cwd))
 	cmd.AddCommand(configCommand(dockerClient))
@@ -60,13 +60,24 @@ func toolsCommand(docker docker.Client) *cobra.Command {
 			)
 		},
 	})
-	cmd.AddCommand(&cobra.Command{
-		Use:   "call",
+
+	var callOptions tools.CallOptions
+	callCmd := &cobra.Command{
+		Use:   "call [tool] [key=value] ...",
 		Short: "Call a tool",
+		Long: `Call a tool. The key=value arguments are converted to the types of the tool's input schema.
+Repeat a key to pass an array. Progress and server logs are printed to stderr. The command fails when
+the tool returns an error.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return tools.Call(cmd.Context(), version, gatewayArgs, verbose, args)
+			return tools.Call(cmd.Context(), version, gatewayArgs, verbose, args, callOptions)
 		},
-	})
+	}
+	callCmd.Flags().StringVar(&callOptions.ArgsJSON, "args-json", "", "Arguments as a JSON object")
+	callCmd.Flags().StringVar(&callOptions.ArgsFile, "args-file", "", "Read the arguments from a JSON or YAML file, or - for stdin")
+	callCmd.Flags().StringVarP(&callOptions.Output, "output", "o", "text", "Output format (text|json|yaml|raw)")
+	callCmd.Flags().StringVar(&callOptions.SaveDir, "save-dir", ".", "Directory where the images, audio and binary resources are saved")
+	callCmd.Flags().StringVar(&callOptions.LogLevel, "log-level", "info", "Minimum level of the server logs to print (debug|info|notice|warning|error), empty to print none")
+	cmd.AddCommand(callCmd)
 
 	var enableServerName string
 	enableCmd := &cobra.Command{
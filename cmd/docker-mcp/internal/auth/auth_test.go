@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func request(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "http://gateway:8811/mcp", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens(map[string]string{"ci": "secret-1", "alice": "secret-2"})
+
+	identity, err := tokens.Authenticate(request("secret-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "token:alice", identity.String())
+
+	_, err = tokens.Authenticate(request("wrong"))
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	_, err = tokens.Authenticate(request(""))
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestClientCertificate(t *testing.T) {
+	clientCertificate := &ClientCertificate{}
+
+	_, err := clientCertificate.Authenticate(request(""))
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	r := request("")
+	r.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{
+			Subject:  pkix.Name{CommonName: "build-agent"},
+			NotAfter: time.Now().Add(time.Hour),
+		}}},
+	}
+	identity, err := clientCertificate.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, "client-certificate:build-agent", identity.String())
+}
+
+func TestMiddleware(t *testing.T) {
+	var identity *Identity
+	var tokenInfo *mcpauth.TokenInfo
+	handler := Middleware(Chain{NewTokens(map[string]string{"ci": "secret"})}, true)(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			identity = FromContext(r.Context())
+			tokenInfo = mcpauth.TokenInfoFromContext(r.Context())
+		}),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request("wrong"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(
+		t,
+		`Bearer resource_metadata="http://gateway:8811/.well-known/oauth-protected-resource"`,
+		w.Header().Get("WWW-Authenticate"),
+	)
+	assert.Nil(t, identity)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request("secret"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, identity)
+	assert.Equal(t, "ci", identity.Subject)
+	require.NotNil(t, tokenInfo)
+	assert.Equal(t, "ci", tokenInfo.Extra["sub"])
+}
+
+func TestMetadataHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	MetadataHandler("", []string{"https://login.corp"}, []string{"mcp"})(w, request(""))
+
+	var metadata ProtectedResourceMetadata
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &metadata))
+	assert.Equal(t, "http://gateway:8811", metadata.Resource)
+	assert.Equal(t, []string{"https://login.corp"}, metadata.AuthorizationServers)
+	assert.Equal(t, []string{"mcp"}, metadata.ScopesSupported)
+}
+
+func TestOAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": "key-1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	oauth, err := NewOAuth(OAuthOptions{
+		Issuer:   "https://login.corp",
+		JWKSURL:  jwks.URL,
+		Audience: "https://gateway.corp/mcp",
+		Scopes:   []string{"mcp:tools"},
+	})
+	require.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://login.corp",
+			"aud":   "https://gateway.corp/mcp",
+			"sub":   "alice",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "mcp:tools mcp:prompts",
+		}
+	}
+
+	identity, err := oauth.Authenticate(request(sign(claims())))
+	require.NoError(t, err)
+	assert.Equal(t, "oauth:alice", identity.String())
+	assert.Equal(t, []string{"mcp:tools", "mcp:prompts"}, identity.Scopes)
+
+	wrongAudience := claims()
+	wrongAudience["aud"] = "https://other"
+	_, err = oauth.Authenticate(request(sign(wrongAudience)))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	noAudience := claims()
+	delete(noAudience, "aud")
+	_, err = oauth.Authenticate(request(sign(noAudience)))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	expired := claims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = oauth.Authenticate(request(sign(expired)))
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	missingScope := claims()
+	missingScope["scope"] = "mcp:prompts"
+	_, err = oauth.Authenticate(request(sign(missingScope)))
+	require.ErrorIs(t, err, ErrInsufficientScope)
+}
+
+func TestOAuthRequiresAnAudience(t *testing.T) {
+	_, err := NewOAuth(OAuthOptions{Issuer: "https://login.corp", JWKSURL: "https://login.corp/keys"})
+	require.ErrorContains(t, err, "an issuer and an audience are required")
+}
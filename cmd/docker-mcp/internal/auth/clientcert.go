@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ClientCertificate accepts the clients that present a certificate signed by a given CA (mTLS).
+type ClientCertificate struct {
+	pool *x509.CertPool
+}
+
+// NewClientCertificate reads the PEM encoded certificates of the CAs trusted to sign client certificates.
+func NewClientCertificate(caFile string) (*ClientCertificate, error) {
+	buf, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		return nil, fmt.Errorf("no certificate found in %s", caFile)
+	}
+
+	return &ClientCertificate{pool: pool}, nil
+}
+
+// ConfigureTLS asks the clients for a certificate, verified against the CAs, during the TLS handshake.
+// Clients without a certificate can still connect and be authenticated another way.
+func (c *ClientCertificate) ConfigureTLS(config *tls.Config) {
+	config.ClientCAs = c.pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+}
+
+func (c *ClientCertificate) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	subject := leaf.Subject.CommonName
+	if subject == "" {
+		subject = leaf.SerialNumber.String()
+	}
+
+	return &Identity{
+		Subject: subject,
+		Method:  MethodClientCertificate,
+		Expires: leaf.NotAfter,
+	}, nil
+}
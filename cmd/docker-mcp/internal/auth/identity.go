@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Authentication methods.
+const (
+	MethodToken             = "token"
+	MethodClientCertificate = "client-certificate"
+	MethodOAuth             = "oauth"
+)
+
+// Identity is the authenticated client of a gateway.
+type Identity struct {
+	Subject string
+	Method  string
+	Scopes  []string
+	Expires time.Time
+}
+
+func (i *Identity) String() string {
+	return i.Method + ":" + i.Subject
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context that carries an identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity carried by a context, or nil if none.
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// FromRequest returns the identity of the client that sent an MCP request, or nil if the
+// request was not authenticated.
+func FromRequest(req mcp.Request) *Identity {
+	if req == nil {
+		return nil
+	}
+	extra := req.GetExtra()
+	if extra == nil || extra.TokenInfo == nil {
+		return nil
+	}
+
+	subject, _ := extra.TokenInfo.Extra["sub"].(string)
+	method, _ := extra.TokenInfo.Extra["auth_method"].(string)
+	if subject == "" {
+		return nil
+	}
+
+	return &Identity{
+		Subject: subject,
+		Method:  method,
+		Scopes:  extra.TokenInfo.Scopes,
+		Expires: extra.TokenInfo.Expiration,
+	}
+}
+
+// tokenInfo converts an identity to the form the MCP SDK passes to the request handlers.
+func (i *Identity) tokenInfo() *mcpauth.TokenInfo {
+	expires := i.Expires
+	if expires.IsZero() {
+		// The SDK requires an expiration, static tokens and client certificates are checked on each request.
+		expires = time.Now().Add(24 * time.Hour)
+	}
+
+	return &mcpauth.TokenInfo{
+		Scopes:     i.Scopes,
+		Expiration: expires,
+		Extra: map[string]any{
+			"sub":         i.Subject,
+			"auth_method": i.Method,
+		},
+	}
+}
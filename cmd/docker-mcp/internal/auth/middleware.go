@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// ProtectedResourceMetadataPath is where the OAuth protected resource metadata (RFC 9728) is served.
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request has no credentials it understands.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned by an Authenticator when the credentials are not valid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInsufficientScope is returned by an Authenticator when the credentials lack a required scope.
+	ErrInsufficientScope = errors.New("insufficient scope")
+)
+
+// Authenticator authenticates the clients of the gateway.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// Chain tries authenticators in order, until one of them finds credentials it understands.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Identity, error) {
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Middleware rejects the requests that are not authenticated. The identity of the client is added to
+// the context of the request and passed to the MCP handlers, see FromRequest.
+// When oauth is set, rejections point the client to the protected resource metadata.
+func Middleware(authenticator Authenticator, oauth bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, err := authenticator.Authenticate(r)
+			if err != nil {
+				status := http.StatusUnauthorized
+				if errors.Is(err, ErrInsufficientScope) {
+					status = http.StatusForbidden
+				}
+				challenge := "Bearer"
+				if oauth {
+					challenge += fmt.Sprintf(" resource_metadata=%q", baseURL(r)+ProtectedResourceMetadataPath)
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, err.Error(), status)
+				return
+			}
+
+			r = r.WithContext(WithIdentity(r.Context(), identity))
+
+			// The SDK only passes bearer tokens to the MCP handlers. Clients authenticated by
+			// certificate don't send one, so give them a placeholder.
+			if r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", "Bearer "+identity.Method)
+			}
+			verifier := func(_ context.Context, _ string, _ *http.Request) (*mcpauth.TokenInfo, error) {
+				return identity.tokenInfo(), nil
+			}
+			mcpauth.RequireBearerToken(verifier, nil)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata of the gateway (RFC 9728).
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+}
+
+// MetadataHandler serves the protected resource metadata. The resource defaults to the URL of the gateway.
+func MetadataHandler(resource string, authorizationServers, scopes []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metadata := ProtectedResourceMetadata{
+			Resource:               resource,
+			AuthorizationServers:   authorizationServers,
+			ScopesSupported:        scopes,
+			BearerMethodsSupported: []string{"header"},
+		}
+		if metadata.Resource == "" {
+			metadata.Resource = baseURL(r)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(metadata)
+	}
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	portalauth "github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/auth"
+)
+
+type OAuthOptions struct {
+	// Issuer is the authorization server. Tokens from other issuers are rejected.
+	Issuer string
+	// JWKSURL is where the keys of the authorization server are published.
+	JWKSURL string
+	// Audience is the resource the tokens must be issued for, usually the URL of the gateway. Tokens
+	// issued for other resources are rejected.
+	Audience string
+	// Scopes are required in every token.
+	Scopes []string
+}
+
+// OAuth validates OAuth 2.1 access tokens, issued as JWTs, as a resource server.
+type OAuth struct {
+	options OAuthOptions
+	jwks    *portalauth.JWKSProvider
+}
+
+func NewOAuth(options OAuthOptions) (*OAuth, error) {
+	if options.JWKSURL == "" {
+		return nil, errors.New("a JWKS URL is required to validate OAuth tokens")
+	}
+	if options.Issuer == "" || options.Audience == "" {
+		return nil, errors.New("an issuer and an audience are required to validate OAuth tokens")
+	}
+
+	return &OAuth{
+		options: options,
+		jwks:    portalauth.CreateJWKSProvider(options.JWKSURL, nil),
+	}, nil
+}
+
+func (o *OAuth) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(o.options.Issuer),
+		jwt.WithAudience(o.options.Audience),
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.key(r, kid)
+	}, parserOptions...); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	scopes := tokenScopes(claims)
+	for _, scope := range o.options.Scopes {
+		if !slices.Contains(scopes, scope) {
+			return nil, fmt.Errorf("%w: %s is required", ErrInsufficientScope, scope)
+		}
+	}
+
+	identity := &Identity{
+		Subject: subject,
+		Method:  MethodOAuth,
+		Scopes:  scopes,
+	}
+	if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+		identity.Expires = expiresAt.Time
+	}
+
+	return identity, nil
+}
+
+// key finds the public key a token was signed with, refreshing the keys once if it's unknown.
+func (o *OAuth) key(r *http.Request, kid string) (any, error) {
+	if _, err := o.jwks.GetKeySet(r.Context()); err != nil {
+		return nil, err
+	}
+	if key := o.jwks.LookupKeyID(kid); key != nil {
+		return key, nil
+	}
+
+	if err := o.jwks.RefreshCache(r.Context()); err != nil {
+		return nil, err
+	}
+	if key := o.jwks.LookupKeyID(kid); key != nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// tokenScopes reads the scopes of a token, either from the standard "scope" claim
+// or from the "scp" claim used by some providers.
+func tokenScopes(claims jwt.MapClaims) []string {
+	if scopes, ok := claims["scope"].(string); ok {
+		return strings.Fields(scopes)
+	}
+
+	switch scopes := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scopes)
+	case []any:
+		var values []string
+		for _, scope := range scopes {
+			if value, ok := scope.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Tokens accepts static bearer tokens, usually read from the secret store.
+type Tokens struct {
+	// tokens maps the name of each token to its value.
+	tokens map[string]string
+}
+
+func NewTokens(tokens map[string]string) *Tokens {
+	return &Tokens{tokens: tokens}
+}
+
+func (t *Tokens) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	for name, value := range t.tokens {
+		if value != "" && subtle.ConstantTimeCompare([]byte(token), []byte(value)) == 1 {
+			return &Identity{Subject: name, Method: MethodToken}, nil
+		}
+	}
+
+	// Let the next authenticator, if any, validate the token.
+	return nil, ErrNoCredentials
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,432 @@
+// Package bridge serves a remote gateway to a local MCP client over stdio, for the clients that
+// can't connect to the HTTP transports. The tools, prompts and resources of the gateway are
+// mirrored locally, and the requests of the gateway to the client are forwarded back.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const maxReconnectDelay = time.Minute
+
+type Options struct {
+	URL string
+	// Transport is "streaming", the default, or "sse".
+	Transport string
+	// Headers are sent with every request, e.g. Authorization.
+	Headers map[string]string
+	// Servers selects the servers of a gateway running in central mode.
+	Servers []string
+	// ReconnectDelay is the delay before the first attempt to reconnect to the gateway, doubled
+	// after each failed attempt. Zero means one second.
+	ReconnectDelay time.Duration
+}
+
+// Bridge connects one local client to a remote gateway.
+type Bridge struct {
+	options Options
+
+	// mirrorMu serializes the updates of the mirrored capabilities.
+	mirrorMu sync.Mutex
+
+	mu     sync.Mutex
+	server *mcp.Server
+	client *mcp.Client
+	remote *mcp.ClientSession
+	local  *mcp.ServerSession
+	roots  []*mcp.Root
+
+	// The capabilities mirrored from the gateway.
+	tools     []string
+	prompts   []string
+	resources []string
+	templates []string
+}
+
+func New(options Options) *Bridge {
+	if options.ReconnectDelay <= 0 {
+		options.ReconnectDelay = time.Second
+	}
+
+	return &Bridge{options: options}
+}
+
+// Run serves the local client on the transport, until it disconnects or the context is done.
+func (b *Bridge) Run(ctx context.Context, transport mcp.Transport) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	remote, err := b.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", b.options.URL, err)
+	}
+	defer b.close()
+
+	initialized := remote.InitializeResult()
+	server := mcp.NewServer(initialized.ServerInfo, &mcp.ServerOptions{
+		Instructions: initialized.Instructions,
+		InitializedHandler: func(_ context.Context, req *mcp.InitializedRequest) {
+			go b.updateRoots(ctx, req.Session)
+		},
+		RootsListChangedHandler: func(_ context.Context, req *mcp.RootsListChangedRequest) {
+			go b.updateRoots(ctx, req.Session)
+		},
+		CompletionHandler: func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			remote, err := b.remoteSession()
+			if err != nil {
+				return nil, err
+			}
+			return remote.Complete(ctx, req.Params)
+		},
+		SubscribeHandler: func(ctx context.Context, req *mcp.SubscribeRequest) error {
+			remote, err := b.remoteSession()
+			if err != nil {
+				return err
+			}
+			return remote.Subscribe(ctx, req.Params)
+		},
+		UnsubscribeHandler: func(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+			remote, err := b.remoteSession()
+			if err != nil {
+				return err
+			}
+			return remote.Unsubscribe(ctx, req.Params)
+		},
+		HasTools:     true,
+		HasPrompts:   initialized.Capabilities.Prompts != nil,
+		HasResources: initialized.Capabilities.Resources != nil,
+	})
+	b.mu.Lock()
+	b.server = server
+	b.mu.Unlock()
+	if err := b.mirror(ctx, remote); err != nil {
+		return err
+	}
+
+	local, err := server.Connect(ctx, transport, nil)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.local = local
+	b.mu.Unlock()
+
+	go b.reconnect(ctx, remote)
+
+	return local.Wait()
+}
+
+// connect opens a new session with the gateway.
+func (b *Bridge) connect(ctx context.Context) (*mcp.ClientSession, error) {
+	headers := map[string]string{}
+	for name, value := range b.options.Headers {
+		headers[name] = value
+	}
+	if len(b.options.Servers) > 0 {
+		headers["x-mcp-servers"] = strings.Join(b.options.Servers, ",")
+	}
+	httpClient := &http.Client{
+		Transport: &headerRoundTripper{base: http.DefaultTransport, headers: headers},
+	}
+
+	var transport mcp.Transport
+	switch strings.ToLower(b.options.Transport) {
+	case "", "http", "streamable", "streaming", "streamable-http":
+		transport = &mcp.StreamableClientTransport{Endpoint: b.options.URL, HTTPClient: httpClient}
+	case "sse":
+		transport = &mcp.SSEClientTransport{Endpoint: b.options.URL, HTTPClient: httpClient}
+	default:
+		return nil, fmt.Errorf("unsupported transport %q, expected 'streaming' or 'sse'", b.options.Transport)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{
+		Name:    "docker-mcp-bridge",
+		Version: "1.0.0",
+	}, b.clientOptions())
+	b.mu.Lock()
+	client.AddRoots(b.roots...)
+	b.mu.Unlock()
+
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.client = client
+	b.remote = session
+	b.mu.Unlock()
+	return session, nil
+}
+
+// clientOptions forward the requests and notifications of the gateway to the local client.
+func (b *Bridge) clientOptions() *mcp.ClientOptions {
+	return &mcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			local, err := b.localSession()
+			if err != nil {
+				return nil, err
+			}
+			return local.CreateMessage(ctx, req.Params)
+		},
+		ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			local, err := b.localSession()
+			if err != nil {
+				return nil, err
+			}
+			return local.Elicit(ctx, req.Params)
+		},
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			if local, err := b.localSession(); err == nil {
+				_ = local.Log(ctx, req.Params)
+			}
+		},
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			if local, err := b.localSession(); err == nil {
+				_ = local.NotifyProgress(ctx, req.Params)
+			}
+		},
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			if server := b.localServer(); server != nil {
+				_ = server.ResourceUpdated(ctx, req.Params)
+			}
+		},
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			go b.remirror(context.WithoutCancel(ctx), req.Session)
+		},
+		PromptListChangedHandler: func(ctx context.Context, req *mcp.PromptListChangedRequest) {
+			go b.remirror(context.WithoutCancel(ctx), req.Session)
+		},
+		ResourceListChangedHandler: func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
+			go b.remirror(context.WithoutCancel(ctx), req.Session)
+		},
+	}
+}
+
+// reconnect opens a new session each time the gateway closes the current one, e.g. because it
+// restarted.
+func (b *Bridge) reconnect(ctx context.Context, remote *mcp.ClientSession) {
+	for {
+		err := remote.Wait()
+		if ctx.Err() != nil {
+			return
+		}
+		logf("! Lost connection to %s: %v", b.options.URL, err)
+
+		b.mu.Lock()
+		b.remote = nil
+		b.mu.Unlock()
+
+		delay := b.options.ReconnectDelay
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			remote, err = b.connect(ctx)
+			if err == nil {
+				if err = b.mirror(ctx, remote); err != nil {
+					b.close()
+				}
+			}
+			if err == nil {
+				logf("- Reconnected to %s", b.options.URL)
+				break
+			}
+			logf("! Reconnecting to %s: %v", b.options.URL, err)
+			delay = min(2*delay, maxReconnectDelay)
+		}
+	}
+}
+
+func (b *Bridge) remirror(ctx context.Context, remote *mcp.ClientSession) {
+	if b.localServer() == nil {
+		return
+	}
+	if err := b.mirror(ctx, remote); err != nil {
+		logf("! Listing the capabilities of %s: %v", b.options.URL, err)
+	}
+}
+
+// mirror registers the capabilities of the gateway on the local server, and removes the ones
+// the gateway doesn't have anymore. The local client is notified of the changes.
+func (b *Bridge) mirror(ctx context.Context, remote *mcp.ClientSession) error {
+	b.mirrorMu.Lock()
+	defer b.mirrorMu.Unlock()
+
+	server := b.localServer()
+	capabilities := remote.InitializeResult().Capabilities
+
+	var tools []string
+	for tool, err := range remote.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("listing tools: %w", err)
+		}
+		server.AddTool(tool, b.callTool)
+		tools = append(tools, tool.Name)
+	}
+
+	var prompts []string
+	if capabilities.Prompts != nil {
+		for prompt, err := range remote.Prompts(ctx, nil) {
+			if err != nil {
+				return fmt.Errorf("listing prompts: %w", err)
+			}
+			server.AddPrompt(prompt, b.getPrompt)
+			prompts = append(prompts, prompt.Name)
+		}
+	}
+
+	var resources, templates []string
+	if capabilities.Resources != nil {
+		for resource, err := range remote.Resources(ctx, nil) {
+			if err != nil {
+				return fmt.Errorf("listing resources: %w", err)
+			}
+			server.AddResource(resource, b.readResource)
+			resources = append(resources, resource.URI)
+		}
+		for template, err := range remote.ResourceTemplates(ctx, nil) {
+			if err != nil {
+				return fmt.Errorf("listing resource templates: %w", err)
+			}
+			server.AddResourceTemplate(template, b.readResource)
+			templates = append(templates, template.URITemplate)
+		}
+	}
+
+	server.RemoveTools(removed(b.tools, tools)...)
+	server.RemovePrompts(removed(b.prompts, prompts)...)
+	server.RemoveResources(removed(b.resources, resources)...)
+	server.RemoveResourceTemplates(removed(b.templates, templates)...)
+	b.tools, b.prompts, b.resources, b.templates = tools, prompts, resources, templates
+
+	return nil
+}
+
+func (b *Bridge) callTool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	remote, err := b.remoteSession()
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      req.Params.Meta,
+		Name:      req.Params.Name,
+		Arguments: req.Params.Arguments,
+	})
+}
+
+func (b *Bridge) getPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	remote, err := b.remoteSession()
+	if err != nil {
+		return nil, err
+	}
+	return remote.GetPrompt(ctx, req.Params)
+}
+
+func (b *Bridge) readResource(
+	ctx context.Context,
+	req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	remote, err := b.remoteSession()
+	if err != nil {
+		return nil, err
+	}
+	return remote.ReadResource(ctx, req.Params)
+}
+
+// updateRoots makes the roots of the local client the roots of the bridge.
+func (b *Bridge) updateRoots(ctx context.Context, local *mcp.ServerSession) {
+	result, err := local.ListRoots(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var uris []string
+	for _, root := range b.roots {
+		uris = append(uris, root.URI)
+	}
+	b.roots = result.Roots
+	if b.client != nil {
+		b.client.RemoveRoots(uris...)
+		b.client.AddRoots(result.Roots...)
+	}
+}
+
+func (b *Bridge) localServer() *mcp.Server {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.server
+}
+
+func (b *Bridge) remoteSession() (*mcp.ClientSession, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.remote == nil {
+		return nil, fmt.Errorf("not connected to %s, reconnecting", b.options.URL)
+	}
+	return b.remote, nil
+}
+
+func (b *Bridge) localSession() (*mcp.ServerSession, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.local == nil {
+		return nil, errors.New("no client connected yet")
+	}
+	return b.local, nil
+}
+
+func (b *Bridge) close() {
+	b.mu.Lock()
+	remote := b.remote
+	b.remote = nil
+	b.mu.Unlock()
+
+	if remote != nil {
+		_ = remote.Close()
+	}
+}
+
+// removed returns the elements of before that are not in after.
+func removed(before, after []string) []string {
+	var names []string
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// headerRoundTripper adds headers to all the requests.
+type headerRoundTripper struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range h.headers {
+		req.Header.Set(name, value)
+	}
+	return h.base.RoundTrip(req)
+}
@@ -0,0 +1,127 @@
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gateway is a remote gateway that can be restarted, losing all its sessions.
+type gateway struct {
+	mu      sync.Mutex
+	handler http.Handler
+	headers http.Header
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	handler := g.handler
+	g.headers = r.Header.Clone()
+	g.mu.Unlock()
+
+	handler.ServeHTTP(w, r)
+}
+
+func (g *gateway) restart(tools ...string) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1"}, nil)
+	for _, name := range tools {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(
+			context.Context,
+			*mcp.CallToolRequest,
+			struct{},
+		) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil, nil
+		})
+	}
+	server.AddPrompt(&mcp.Prompt{Name: "prompt"}, func(
+		context.Context,
+		*mcp.GetPromptRequest,
+	) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Description: "remote prompt"}, nil
+	})
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+}
+
+func (g *gateway) header(name string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.headers.Get(name)
+}
+
+func toolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+
+	var names []string
+	for tool, err := range session.Tools(t.Context(), nil) {
+		if err != nil {
+			return nil
+		}
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestBridge(t *testing.T) {
+	remote := &gateway{}
+	remote.restart("first")
+	httpServer := httptest.NewServer(remote)
+	defer httpServer.Close()
+
+	bridge := New(Options{
+		URL:            httpServer.URL,
+		Headers:        map[string]string{"Authorization": "Bearer token"},
+		Servers:        []string{"fetch", "github"},
+		ReconnectDelay: 10 * time.Millisecond,
+	})
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- bridge.Run(ctx, serverTransport) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "editor"}, nil)
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "gateway", session.InitializeResult().ServerInfo.Name)
+
+	// The capabilities of the gateway are mirrored.
+	assert.Equal(t, []string{"first"}, toolNames(t, session))
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{Name: "first"})
+	require.NoError(t, err)
+	assert.Equal(t, "first", result.Content[0].(*mcp.TextContent).Text)
+	prompt, err := session.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: "prompt"})
+	require.NoError(t, err)
+	assert.Equal(t, "remote prompt", prompt.Description)
+
+	assert.Equal(t, "Bearer token", remote.header("Authorization"))
+	assert.Equal(t, "fetch,github", remote.header("x-mcp-servers"))
+
+	// The bridge reconnects when the gateway loses the session.
+	remote.restart("second")
+	_, _ = session.CallTool(t.Context(), &mcp.CallToolParams{Name: "first"})
+	assert.Eventually(t, func() bool {
+		return slices.Equal([]string{"second"}, toolNames(t, session))
+	}, 5*time.Second, 20*time.Millisecond)
+	result, err = session.CallTool(t.Context(), &mcp.CallToolParams{Name: "second"})
+	require.NoError(t, err)
+	assert.Equal(t, "second", result.Content[0].(*mcp.TextContent).Text)
+
+	// The bridge stops when the client disconnects.
+	require.NoError(t, session.Close())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "bridge didn't stop")
+	}
+}
@@ -11,8 +11,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"gopkg.in/yaml.v3"
-
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/user"
 )
 
@@ -28,14 +26,15 @@ func ReadFrom(ctx context.Context, fileOrURLs []string) (Catalog, error) {
 	mergedServers := map[string]Server{}
 
 	for _, fileOrURL := range fileOrURLs {
-		servers, err := readMCPServers(ctx, fileOrURL)
+		servers, layered, err := readMCPServers(ctx, fileOrURL)
 		if err != nil {
 			return Catalog{}, err
 		}
 
-		// Merge servers into the combined map, checking for overlaps
+		// Merge servers into the combined map, checking for overlaps.
+		// A catalog that extends another one is expected to override its servers.
 		for key, server := range servers {
-			if _, exists := mergedServers[key]; exists {
+			if _, exists := mergedServers[key]; exists && !layered {
 				log.Printf(
 					"Warning: overlapping key '%s' found in catalog '%s', overwriting previous value",
 					key,
@@ -51,21 +50,25 @@ func ReadFrom(ctx context.Context, fileOrURLs []string) (Catalog, error) {
 	}, nil
 }
 
-func readMCPServers(ctx context.Context, fileOrURL string) (map[string]Server, error) {
-	buf, err := readFileOrURL(ctx, fileOrURL)
+func readMCPServers(ctx context.Context, fileOrURL string) (map[string]Server, bool, error) {
+	layer, err := resolveLayers(ctx, fileOrURL, false, map[string]bool{})
 	if err != nil {
 		if os.IsNotExist(err) {
-			return map[string]Server{}, nil
+			return map[string]Server{}, false, nil
 		}
-		return nil, err
+		return nil, false, err
 	}
 
-	var topLevel topLevel
-	if err := yaml.Unmarshal(buf, &topLevel); err != nil {
-		return nil, err
+	servers := map[string]Server{}
+	for name, raw := range layer.servers {
+		server, err := toServer(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("server %q in %q: %w", name, fileOrURL, err)
+		}
+		servers[name] = server
 	}
 
-	return topLevel.Registry, nil
+	return servers, layer.layered, nil
 }
 
 func readFileOrURL(ctx context.Context, fileOrURL string) ([]byte, error) {
@@ -0,0 +1,455 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/eval"
+)
+
+// JSONSchema is the published JSON Schema of the catalog format.
+//
+//go:embed schema.json
+var JSONSchema []byte
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single problem reported by Lint.
+type Finding struct {
+	Severity Severity `json:"severity"         yaml:"severity"`
+	Rule     string   `json:"rule"             yaml:"rule"`
+	Server   string   `json:"server,omitempty" yaml:"server,omitempty"`
+	Path     string   `json:"path,omitempty"   yaml:"path,omitempty"`
+	Message  string   `json:"message"          yaml:"message"`
+}
+
+// Lint checks a catalog YAML file for problems that would otherwise only surface when the gateway runs.
+func Lint(content []byte) ([]Finding, error) {
+	var raw any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("parsing catalog: %w", err)
+	}
+
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(JSONSchema, &schema); err != nil {
+		return nil, fmt.Errorf("parsing catalog schema: %w", err)
+	}
+
+	findings := unknownFields(&schema, &schema, raw, "")
+
+	// Only validate types once the field names are right, to avoid reporting the same problem twice.
+	if len(findings) == 0 {
+		if err := validateSchema(&schema, raw); err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Rule:     "schema",
+				Message:  schemaErrorMessage(err),
+			})
+		}
+	}
+
+	var catalog struct {
+		Extends  string            `yaml:"extends"`
+		Registry map[string]Server `yaml:"registry"`
+	}
+	if err := yaml.Unmarshal(content, &catalog); err == nil {
+		for name, server := range catalog.Registry {
+			findings = append(findings, lintServer(name, server, catalog.Extends != "")...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Server != findings[j].Server {
+			return findings[i].Server < findings[j].Server
+		}
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Message < findings[j].Message
+	})
+
+	return findings, nil
+}
+
+func validateSchema(schema *jsonschema.Schema, raw any) error {
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return err
+	}
+
+	// Go through JSON so that the instance only contains JSON types.
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var instance any
+	if err := json.Unmarshal(buf, &instance); err != nil {
+		return err
+	}
+
+	return resolved.Validate(instance)
+}
+
+// schemaErrorMessage keeps only the innermost part of a validation error,
+// the rest being the chain of schema locations that led to it.
+func schemaErrorMessage(err error) string {
+	message := err.Error()
+	if i := strings.LastIndex(message, "validating "); i != -1 {
+		if _, rest, found := strings.Cut(message[i:], ": "); found {
+			return rest
+		}
+	}
+	return message
+}
+
+// unknownFields reports the keys that the schema doesn't allow, suggesting the closest known key.
+func unknownFields(root, schema *jsonschema.Schema, value any, path string) []Finding {
+	schema = deref(root, schema)
+	if schema == nil {
+		return nil
+	}
+
+	var findings []Finding
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := joinPath(path, key)
+			if property, ok := schema.Properties[key]; ok {
+				findings = append(findings, unknownFields(root, property, v[key], childPath)...)
+				continue
+			}
+
+			additional := deref(root, schema.AdditionalProperties)
+			if additional == nil {
+				continue
+			}
+			if additional.Not == nil {
+				findings = append(findings, unknownFields(root, additional, v[key], childPath)...)
+				continue
+			}
+
+			finding := Finding{
+				Severity: SeverityWarning,
+				Rule:     "unknown-field",
+				Server:   serverOf(childPath),
+				Path:     childPath,
+				Message:  fmt.Sprintf("unknown field %q", key),
+			}
+			if suggestion := closestKey(key, schema.Properties); suggestion != "" {
+				finding.Severity = SeverityError
+				finding.Message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			findings = append(findings, finding)
+		}
+	case []any:
+		for i, item := range v {
+			findings = append(
+				findings,
+				unknownFields(root, schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return findings
+}
+
+func deref(root, schema *jsonschema.Schema) *jsonschema.Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	return root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+}
+
+// lintServer checks a single server. Servers of a catalog that extends another one are partial:
+// the checks that need the whole server definition are skipped.
+func lintServer(name string, server Server, partial bool) []Finding {
+	var findings []Finding
+	report := func(severity Severity, rule, path, format string, args ...any) {
+		findings = append(findings, Finding{
+			Severity: severity,
+			Rule:     rule,
+			Server:   name,
+			Path:     joinPath("registry."+name, path),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	hasContainerTools := false
+	for _, tool := range server.Tools {
+		if tool.Container.Image != "" {
+			hasContainerTools = true
+		}
+	}
+	if !partial && server.Image == "" && server.Remote.URL == "" && server.SSEEndpoint == "" &&
+		!hasContainerTools {
+		report(SeverityError, "no-runtime", "", "server has no image, remote or container tools")
+	}
+
+	for i, secret := range server.Secrets {
+		path := fmt.Sprintf("secrets[%d]", i)
+		if secret.Name == "" {
+			report(SeverityError, "secret-missing-name", path, "secret has no name")
+		}
+		if secret.Env == "" {
+			report(SeverityError, "secret-missing-env", path,
+				"secret %q has no env, its value would never reach the server", secret.Name)
+		}
+	}
+
+	for i, env := range server.Env {
+		if env.Name == "" {
+			report(SeverityError, "env-missing-name", fmt.Sprintf("env[%d]", i), "env has no name")
+		}
+	}
+
+	toolNames := map[string]bool{}
+	for i, tool := range server.Tools {
+		if toolNames[tool.Name] {
+			report(SeverityError, "duplicate-tool", fmt.Sprintf("tools[%d]", i),
+				"duplicate tool %q", tool.Name)
+		}
+		toolNames[tool.Name] = true
+	}
+
+	// Server templates are evaluated against the config, declared by the config schema.
+	declared := configKeys(server.Config)
+	used := map[string]bool{}
+	for path, expression := range templatedValues(server) {
+		for _, reference := range eval.References(expression) {
+			for _, f := range reference.Functions {
+				if !eval.IsKnownFunction(f) {
+					report(SeverityError, "unknown-function", path, "unknown function %q in %q", f, expression)
+				}
+			}
+
+			key := declaredKey(reference.Path, declared)
+			if key == "" && partial {
+				continue
+			}
+			if key == "" {
+				report(SeverityWarning, "config-undefined", path,
+					"%q is not declared in the config schema, it will evaluate to an empty value", reference.Path)
+				continue
+			}
+			used[key] = true
+		}
+	}
+	for _, key := range sortedKeys(declared) {
+		if !used[key] && !partial {
+			report(SeverityWarning, "config-unused", "config",
+				"config key %q is never used by env, command, volumes or user", key)
+		}
+	}
+
+	// POCI tool templates are evaluated against the tool call arguments.
+	for i, tool := range server.Tools {
+		for path, expression := range toolTemplatedValues(i, tool) {
+			for _, reference := range eval.References(expression) {
+				for _, f := range reference.Functions {
+					if !eval.IsKnownFunction(f) {
+						report(SeverityError, "unknown-function", path, "unknown function %q in %q", f, expression)
+					}
+				}
+
+				parameter, _, _ := strings.Cut(reference.Path, ".")
+				if _, ok := tool.Parameters.Properties[parameter]; !ok {
+					report(SeverityWarning, "parameter-undefined", path,
+						"%q is not a parameter of tool %q, it will evaluate to an empty value", parameter, tool.Name)
+				}
+			}
+		}
+	}
+
+	// Insecure settings.
+	if server.User == "root" || server.User == "0" || strings.HasPrefix(server.User, "0:") {
+		report(SeverityWarning, "insecure-root-user", "user", "server runs as root")
+	}
+	volumes := map[string][]string{"volumes": server.Volumes}
+	for i, tool := range server.Tools {
+		volumes[fmt.Sprintf("tools[%d].container.volumes", i)] = tool.Container.Volumes
+	}
+	for path, list := range volumes {
+		for _, volume := range list {
+			if strings.Contains(volume, "docker.sock") {
+				report(SeverityWarning, "insecure-docker-socket", path,
+					"mounting the Docker socket gives the server full control of the host")
+			}
+		}
+	}
+	for _, host := range server.AllowHosts {
+		if strings.HasPrefix(host, "*") {
+			report(SeverityWarning, "insecure-allow-hosts", "allowHosts",
+				"wildcard host %q defeats network filtering", host)
+		}
+	}
+	if remoteURL, err := url.Parse(server.Remote.URL); err == nil && remoteURL.Scheme == "http" {
+		if host := remoteURL.Hostname(); host != "localhost" && host != "127.0.0.1" && host != "::1" {
+			report(SeverityWarning, "insecure-remote-url", "remote.url",
+				"remote %q is not using https", server.Remote.URL)
+		}
+	}
+	if server.Image != "" && !strings.Contains(server.Image, "@sha256:") {
+		if !strings.Contains(imageName(server.Image), ":") || strings.HasSuffix(server.Image, ":latest") {
+			report(SeverityWarning, "unpinned-image", "image",
+				"image %q is neither pinned by digest nor by a version tag", server.Image)
+		}
+	}
+
+	return findings
+}
+
+// templatedValues lists the server values in which {{...}} placeholders are evaluated, by path.
+func templatedValues(server Server) map[string]string {
+	values := map[string]string{}
+
+	for i, env := range server.Env {
+		values[fmt.Sprintf("env[%d].value", i)] = env.Value
+	}
+	for i, arg := range server.Command {
+		values[fmt.Sprintf("command[%d]", i)] = arg
+	}
+	for i, volume := range server.Volumes {
+		values[fmt.Sprintf("volumes[%d]", i)] = volume
+	}
+	if server.User != "" {
+		values["user"] = server.User
+	}
+
+	return values
+}
+
+// toolTemplatedValues lists the container values of a POCI tool in which {{...}} placeholders
+// are evaluated, by path.
+func toolTemplatedValues(index int, tool Tool) map[string]string {
+	values := map[string]string{}
+
+	for i, arg := range tool.Container.Command {
+		values[fmt.Sprintf("tools[%d].container.command[%d]", index, i)] = arg
+	}
+	for i, volume := range tool.Container.Volumes {
+		values[fmt.Sprintf("tools[%d].container.volumes[%d]", index, i)] = volume
+	}
+	if tool.Container.User != "" {
+		values[fmt.Sprintf("tools[%d].container.user", index)] = tool.Container.User
+	}
+
+	return values
+}
+
+// configKeys lists the `<config name>.<property>` keys declared by the config schemas.
+func configKeys(config []any) map[string]bool {
+	keys := map[string]bool{}
+
+	for _, item := range config {
+		schema, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := schema["name"].(string)
+		properties, _ := schema["properties"].(map[string]any)
+		for property := range properties {
+			keys[name+"."+property] = true
+		}
+	}
+
+	return keys
+}
+
+// declaredKey finds which declared key a template path refers to, if any.
+// Templates can dig into object properties, hence the prefix match.
+func declaredKey(path string, declared map[string]bool) string {
+	for key := range declared {
+		if path == key || strings.HasPrefix(path, key+".") {
+			return key
+		}
+	}
+	return ""
+}
+
+func closestKey(key string, properties map[string]*jsonschema.Schema) string {
+	best, bestDistance := "", 3
+	for candidate := range properties {
+		if strings.EqualFold(candidate, key) {
+			return candidate
+		}
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func imageName(image string) string {
+	// Skip the registry host, which can contain a port.
+	if i := strings.LastIndex(image, "/"); i != -1 {
+		return image[i+1:]
+	}
+	return image
+}
+
+func serverOf(path string) string {
+	rest, found := strings.CutPrefix(path, "registry.")
+	if !found {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, ".")
+	return name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	if key == "" {
+		return path
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rulesOf(findings []Finding) []string {
+	var rules []string
+	for _, finding := range findings {
+		rules = append(rules, finding.Rule)
+	}
+	return rules
+}
+
+func TestLintValidCatalog(t *testing.T) {
+	findings, err := Lint([]byte(`
+name: valid
+registry:
+  db:
+    image: myorg/db@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+    secrets:
+      - name: db.password
+        env: DB_PASSWORD
+    env:
+      - name: DB_HOST
+        value: "{{db.host}}"
+    volumes:
+      - "{{db.data | volume}}:/data"
+    allowHosts:
+      - db.example.com:5432
+    config:
+      - name: db
+        type: object
+        properties:
+          host:
+            type: string
+          data:
+            type: array
+`))
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestLintMisspelledField(t *testing.T) {
+	findings, err := Lint([]byte(`
+registry:
+  db:
+    image: myorg/db:1.0
+    allowhosts:
+      - db.example.com
+    foo: bar
+`))
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+
+	assert.Equal(t, SeverityError, findings[0].Severity)
+	assert.Equal(t, "registry.db.allowhosts", findings[0].Path)
+	assert.Contains(t, findings[0].Message, `did you mean "allowHosts"?`)
+	assert.Equal(t, "db", findings[0].Server)
+
+	assert.Equal(t, SeverityWarning, findings[1].Severity)
+	assert.Equal(t, "registry.db.foo", findings[1].Path)
+}
+
+func TestLintSchemaTypes(t *testing.T) {
+	findings, err := Lint([]byte(`
+registry:
+  db:
+    image: myorg/db:1.0
+    longLived: "yes"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"schema"}, rulesOf(findings))
+}
+
+func TestLintSemanticChecks(t *testing.T) {
+	findings, err := Lint([]byte(`
+registry:
+  db:
+    image: myorg/db
+    user: root
+    secrets:
+      - name: db.password
+    command:
+      - "--host={{db.host | uppercase}}"
+      - "--port={{db.port}}"
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+    allowHosts:
+      - "*.example.com"
+    tools:
+      - name: query
+      - name: query
+    config:
+      - name: db
+        properties:
+          host:
+            type: string
+          unused:
+            type: string
+  remote:
+    remote:
+      url: http://mcp.example.com/sse
+  poci:
+    tools:
+      - name: run
+        container:
+          image: alpine:3.20
+          command: ["{{script}}", "{{missing}}"]
+        parameters:
+          type: object
+          properties:
+            script:
+              type: string
+  empty:
+    description: nothing to run
+`))
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"schema", // the secret is missing its env
+		"unknown-function",
+		"config-undefined",
+		"config-unused",
+		"duplicate-tool",
+		"unpinned-image",
+		"secret-missing-env",
+		"insecure-root-user",
+		"insecure-docker-socket",
+		"insecure-allow-hosts",
+		"no-runtime",
+		"parameter-undefined",
+		"insecure-remote-url",
+	}, rulesOf(findings))
+}
+
+func TestLintLayeredCatalog(t *testing.T) {
+	findings, err := Lint([]byte(`
+extends: docker-mcp
+registry:
+  github:
+    env:
+      - name: GITHUB_HOST
+        value: "{{github.host}}"
+    patch:
+      - op: add
+        path: /allowHosts/-
+        value: github.example.com:443
+`))
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	findings, err = Lint([]byte(`
+extends: docker-mcp
+registry:
+  github:
+    patch:
+      - op: move
+        path: allowHosts
+`))
+	require.NoError(t, err)
+	assert.Contains(t, rulesOf(findings), "schema")
+}
+
+func TestJSONSchemaIsValid(t *testing.T) {
+	_, err := Lint([]byte(`registry: {}`))
+	require.NoError(t, err)
+}
@@ -0,0 +1,252 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patchKey is the key, in a server of a layered catalog, holding a list of JSON Patch (RFC 6902)
+// operations to apply to the server after the fields of the overlay were merged.
+const patchKey = "patch"
+
+// layeredCatalog is a catalog that can extend another one. Servers of a catalog that extends
+// another one are merged into the base servers (RFC 7386 merge semantics) instead of replacing them.
+type layeredCatalog struct {
+	Extends  string                    `yaml:"extends,omitempty"`
+	Registry map[string]map[string]any `yaml:"registry"`
+}
+
+// ResolvedServer is a server of a catalog after its base catalogs and overlays were applied.
+type ResolvedServer struct {
+	Server Server `json:"server"  yaml:"server"`
+	// Sources tells which catalog each field comes from, keyed by dotted field path.
+	Sources map[string]string `json:"sources" yaml:"sources"`
+}
+
+// Resolve reads a catalog and applies all the catalogs it extends, keeping track of where each field comes from.
+func Resolve(ctx context.Context, fileOrURL string) (map[string]ResolvedServer, error) {
+	layer, err := resolveLayers(ctx, fileOrURL, false, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]ResolvedServer{}
+	for name, raw := range layer.servers {
+		server, err := toServer(raw)
+		if err != nil {
+			return nil, fmt.Errorf("server %q: %w", name, err)
+		}
+		resolved[name] = ResolvedServer{
+			Server:  server,
+			Sources: layer.sources[name],
+		}
+	}
+
+	return resolved, nil
+}
+
+type resolvedLayer struct {
+	servers map[string]map[string]any
+	sources map[string]map[string]string
+	layered bool
+}
+
+// resolveLayers reads a catalog and the catalogs it extends. A missing catalog is empty, unless
+// it's extended by another one.
+func resolveLayers(
+	ctx context.Context,
+	fileOrURL string,
+	extended bool,
+	visiting map[string]bool,
+) (resolvedLayer, error) {
+	if visiting[fileOrURL] {
+		return resolvedLayer{}, fmt.Errorf("catalog %q extends itself", fileOrURL)
+	}
+	visiting[fileOrURL] = true
+	defer delete(visiting, fileOrURL)
+
+	buf, err := readFileOrURL(ctx, fileOrURL)
+	if err != nil {
+		return resolvedLayer{}, err
+	}
+	if buf == nil && extended {
+		return resolvedLayer{}, fmt.Errorf("catalog %q not found", fileOrURL)
+	}
+
+	var catalog layeredCatalog
+	if err := yaml.Unmarshal(buf, &catalog); err != nil {
+		return resolvedLayer{}, err
+	}
+
+	layer := resolvedLayer{
+		servers: map[string]map[string]any{},
+		sources: map[string]map[string]string{},
+		layered: catalog.Extends != "",
+	}
+	if catalog.Extends != "" {
+		layer, err = resolveLayers(ctx, extendedCatalog(fileOrURL, catalog.Extends), true, visiting)
+		if err != nil {
+			return resolvedLayer{}, fmt.Errorf(
+				"resolving %q extended by %q: %w",
+				catalog.Extends,
+				fileOrURL,
+				err,
+			)
+		}
+		layer.layered = true
+	}
+
+	source := sourceName(fileOrURL)
+	for name, overlay := range catalog.Registry {
+		server, found := layer.servers[name]
+		serverSources := layer.sources[name]
+		if !found {
+			server = map[string]any{}
+			serverSources = map[string]string{}
+		}
+
+		operations, hasPatch := overlay[patchKey]
+		delete(overlay, patchKey)
+
+		mergePatch(server, overlay, source, "", serverSources)
+
+		if hasPatch {
+			if err := applyPatch(server, operations, source, serverSources); err != nil {
+				return resolvedLayer{}, fmt.Errorf("patching server %q in %q: %w", name, fileOrURL, err)
+			}
+		}
+
+		layer.servers[name] = server
+		layer.sources[name] = serverSources
+	}
+
+	return layer, nil
+}
+
+// mergePatch merges a patch into a server following RFC 7386: objects are merged recursively,
+// null removes a field and any other value, lists included, replaces the previous one.
+func mergePatch(
+	target map[string]any,
+	patch map[string]any,
+	source string,
+	prefix string,
+	sources map[string]string,
+) {
+	for key, value := range patch {
+		path := prefix + key
+
+		patchMap, patchIsMap := value.(map[string]any)
+		targetMap, targetIsMap := target[key].(map[string]any)
+		if patchIsMap && !targetIsMap {
+			// Recurse into new objects too, so that sources are tracked down to the leaves.
+			clearSources(sources, path)
+			targetMap, targetIsMap = map[string]any{}, true
+			target[key] = targetMap
+		}
+		if patchIsMap && targetIsMap {
+			mergePatch(targetMap, patchMap, source, path+".", sources)
+			continue
+		}
+
+		clearSources(sources, path)
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		target[key] = value
+		sources[path] = source
+	}
+}
+
+func clearSources(sources map[string]string, path string) {
+	for key := range sources {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(sources, key)
+		}
+	}
+}
+
+func toServer(raw map[string]any) (Server, error) {
+	buf, err := yaml.Marshal(raw)
+	if err != nil {
+		return Server{}, err
+	}
+
+	var server Server
+	if err := yaml.Unmarshal(buf, &server); err != nil {
+		return Server{}, err
+	}
+
+	return server, nil
+}
+
+// catalogFile converts the value of `extends` into something readFileOrURL understands.
+// A bare catalog name, like docker-mcp, refers to a catalog managed with `docker mcp catalog`.
+func catalogFile(extends string) string {
+	if isURL(extends) || filepath.IsAbs(extends) || isRelativePath(extends) ||
+		strings.HasSuffix(extends, ".yaml") || strings.HasSuffix(extends, ".yml") {
+		return extends
+	}
+	return extends + ".yaml"
+}
+
+// extendedCatalog returns the catalog extended by another one. Relative paths, like ./base.yaml,
+// are relative to the catalog that extends them, not to the current directory.
+func extendedCatalog(fileOrURL string, extends string) string {
+	extends = catalogFile(extends)
+	if !isRelativePath(extends) {
+		return extends
+	}
+
+	switch {
+	case isURL(fileOrURL):
+		base, err := url.Parse(fileOrURL)
+		if err != nil {
+			return extends
+		}
+		ref, err := url.Parse(filepath.ToSlash(extends))
+		if err != nil {
+			return extends
+		}
+		return base.ResolveReference(ref).String()
+
+	case filepath.IsAbs(fileOrURL) || isRelativePath(fileOrURL):
+		// readFileOrURL only reads absolute paths and paths starting with ./ from the disk.
+		path, err := filepath.Abs(filepath.Join(filepath.Dir(fileOrURL), extends))
+		if err != nil {
+			return extends
+		}
+		return path
+
+	default:
+		// Catalogs managed with `docker mcp catalog` are all in the same directory.
+		return filepath.Base(extends)
+	}
+}
+
+func isRelativePath(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../")
+}
+
+func sourceName(fileOrURL string) string {
+	if isURL(fileOrURL) {
+		return fileOrURL
+	}
+	return strings.TrimSuffix(filepath.Base(fileOrURL), filepath.Ext(fileOrURL))
+}
+
+// SortedSourceKeys returns the field paths of a resolved server in a stable order.
+func (s ResolvedServer) SortedSourceKeys() []string {
+	keys := make([]string, 0, len(s.Sources))
+	for key := range s.Sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,201 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseCatalog = `registry:
+  github:
+    image: mcp/github
+    description: GitHub
+    env:
+      - name: LOG_LEVEL
+        value: info
+    remote:
+      url: https://example.com
+      headers:
+        X-Team: base
+  slack:
+    image: mcp/slack
+`
+
+func writeCatalogs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestResolveMergesOverlay(t *testing.T) {
+	dir := writeCatalogs(t, map[string]string{"base.yaml": baseCatalog})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte(`extends: `+filepath.Join(dir, "base.yaml")+`
+registry:
+  github:
+    image: registry.example.com/mcp/github@sha256:abc
+    description: null
+    remote:
+      headers:
+        X-Team: platform
+  internal:
+    image: registry.example.com/internal
+`), 0o644))
+
+	servers, err := Resolve(t.Context(), filepath.Join(dir, "team.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, servers, 3)
+	github := servers["github"]
+	assert.Equal(t, "registry.example.com/mcp/github@sha256:abc", github.Server.Image)
+	assert.Empty(t, github.Server.Description)
+	assert.Equal(t, "https://example.com", github.Server.Remote.URL)
+	assert.Equal(t, "platform", github.Server.Remote.Headers["X-Team"])
+	assert.Equal(t, []Env{{Name: "LOG_LEVEL", Value: "info"}}, github.Server.Env)
+
+	assert.Equal(t, "team", github.Sources["image"])
+	assert.Equal(t, "base", github.Sources["env"])
+	assert.Equal(t, "base", github.Sources["remote.url"])
+	assert.Equal(t, "team", github.Sources["remote.headers.X-Team"])
+	assert.NotContains(t, github.Sources, "description")
+
+	assert.Equal(t, "mcp/slack", servers["slack"].Server.Image)
+	assert.Equal(t, "team", servers["internal"].Sources["image"])
+}
+
+func TestResolveAppliesPatch(t *testing.T) {
+	dir := writeCatalogs(t, map[string]string{"base.yaml": baseCatalog})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte(`extends: `+filepath.Join(dir, "base.yaml")+`
+registry:
+  github:
+    patch:
+      - op: test
+        path: /env/0/name
+        value: LOG_LEVEL
+      - op: add
+        path: /env/-
+        value:
+          name: GITHUB_HOST
+          value: github.example.com
+      - op: replace
+        path: /env/0/value
+        value: debug
+      - op: remove
+        path: /remote/headers/X-Team
+`), 0o644))
+
+	servers, err := Resolve(t.Context(), filepath.Join(dir, "team.yaml"))
+	require.NoError(t, err)
+
+	github := servers["github"]
+	assert.Equal(t, []Env{
+		{Name: "LOG_LEVEL", Value: "debug"},
+		{Name: "GITHUB_HOST", Value: "github.example.com"},
+	}, github.Server.Env)
+	assert.Empty(t, github.Server.Remote.Headers)
+	assert.Equal(t, "team (patch)", github.Sources["env"])
+	assert.Equal(t, "team (patch)", github.Sources["remote.headers.X-Team"])
+}
+
+func TestResolvePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{name: "failed test", patch: "{op: test, path: /image, value: mcp/other}"},
+		{name: "missing value", patch: "{op: replace, path: /user, value: root}"},
+		{name: "out of range", patch: "{op: remove, path: /env/3}"},
+		{name: "relative path", patch: "{op: add, path: image, value: x}"},
+		{name: "unsupported op", patch: "{op: move, path: /image}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeCatalogs(t, map[string]string{"base.yaml": baseCatalog})
+			overlay := "extends: " + filepath.Join(dir, "base.yaml") +
+				"\nregistry:\n  github:\n    patch:\n      - " + test.patch + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte(overlay), 0o644))
+
+			_, err := Resolve(t.Context(), filepath.Join(dir, "team.yaml"))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestResolveDetectsCycles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	require.NoError(t, os.WriteFile(a, []byte("extends: "+b+"\nregistry: {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("extends: "+a+"\nregistry: {}\n"), 0o644))
+
+	_, err := Resolve(t.Context(), a)
+	require.ErrorContains(t, err, "extends itself")
+}
+
+func TestResolveMissingExtendedCatalog(t *testing.T) {
+	dir := t.TempDir()
+	overlay := filepath.Join(dir, "team.yaml")
+	require.NoError(t, os.WriteFile(overlay, []byte(`extends: ./dockr-mcp.yaml
+registry:
+  github:
+    env:
+      - name: LOG_LEVEL
+        value: debug
+`), 0o644))
+
+	_, err := Resolve(t.Context(), overlay)
+	require.ErrorContains(t, err, "not found")
+}
+
+func TestResolveRelativeExtends(t *testing.T) {
+	dir := writeCatalogs(t, map[string]string{"base.yaml": baseCatalog})
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "teams"), 0o755))
+	overlay := filepath.Join(dir, "teams", "team.yaml")
+	require.NoError(t, os.WriteFile(overlay, []byte(`extends: ../base.yaml
+registry:
+  slack:
+    disableNetwork: true
+`), 0o644))
+
+	servers, err := Resolve(t.Context(), overlay)
+	require.NoError(t, err)
+	assert.Equal(t, "mcp/slack", servers["slack"].Server.Image)
+	assert.True(t, servers["slack"].Server.DisableNetwork)
+}
+
+func TestExtendedCatalog(t *testing.T) {
+	assert.Equal(t, "docker-mcp.yaml", extendedCatalog("/catalogs/team.yaml", "docker-mcp"))
+	assert.Equal(t, "/catalogs/base.yaml", extendedCatalog("/catalogs/teams/team.yaml", "../base.yaml"))
+	assert.Equal(t, "https://example.com/catalogs/base.yaml", extendedCatalog("https://example.com/catalogs/team.yaml", "./base.yaml"))
+	assert.Equal(t, "base.yaml", extendedCatalog("team.yaml", "./base.yaml"))
+}
+
+func TestReadFromLayeredCatalog(t *testing.T) {
+	dir := writeCatalogs(t, map[string]string{"base.yaml": baseCatalog})
+	overlay := filepath.Join(dir, "team.yaml")
+	require.NoError(t, os.WriteFile(overlay, []byte("extends: "+filepath.Join(dir, "base.yaml")+`
+registry:
+  slack:
+    disableNetwork: true
+`), 0o644))
+
+	catalog, err := ReadFrom(t.Context(), []string{filepath.Join(dir, "base.yaml"), overlay})
+	require.NoError(t, err)
+
+	assert.Equal(t, "mcp/slack", catalog.Servers["slack"].Image)
+	assert.True(t, catalog.Servers["slack"].DisableNetwork)
+	assert.Equal(t, "mcp/github", catalog.Servers["github"].Image)
+}
+
+func TestCatalogFile(t *testing.T) {
+	assert.Equal(t, "docker-mcp.yaml", catalogFile("docker-mcp"))
+	assert.Equal(t, "team.yml", catalogFile("team.yml"))
+	assert.Equal(t, "./team", catalogFile("./team"))
+	assert.Equal(t, "../team", catalogFile("../team"))
+	assert.Equal(t, "https://example.com/catalog", catalogFile("https://example.com/catalog"))
+}
@@ -0,0 +1,203 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patchOperation is a JSON Patch (RFC 6902) operation. move and copy are not supported.
+type patchOperation struct {
+	Op    string `yaml:"op"`
+	Path  string `yaml:"path"`
+	Value any    `yaml:"value"`
+}
+
+// applyPatch applies a list of JSON Patch operations, as found under the `patch` key of a server, to that server.
+func applyPatch(
+	server map[string]any,
+	operations any,
+	source string,
+	sources map[string]string,
+) error {
+	buf, err := yaml.Marshal(operations)
+	if err != nil {
+		return err
+	}
+
+	var ops []patchOperation
+	if err := yaml.Unmarshal(buf, &ops); err != nil {
+		return fmt.Errorf("patch must be a list of operations: %w", err)
+	}
+
+	for _, op := range ops {
+		tokens, err := parsePointer(op.Path)
+		if err != nil {
+			return err
+		}
+
+		if _, err := applyOperation(server, tokens, op); err != nil {
+			return fmt.Errorf("%s %s: %w", op.Op, op.Path, err)
+		}
+
+		if op.Op != "test" {
+			path := sourcePath(tokens)
+			clearSources(sources, path)
+			sources[path] = source + " (patch)"
+		}
+	}
+
+	return nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// sourcePath converts pointer tokens into the dotted path used to track sources.
+// Array elements are not tracked individually: the whole list is attributed to the patch.
+func sourcePath(tokens []string) string {
+	var path []string
+	for _, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil || token == "-" {
+			break
+		}
+		path = append(path, token)
+	}
+	return strings.Join(path, ".")
+}
+
+// applyOperation applies an operation to a node and returns the updated node.
+// Lists can be reallocated, so the caller must store the returned value.
+func applyOperation(node any, tokens []string, op patchOperation) (any, error) {
+	key := tokens[0]
+
+	if len(tokens) > 1 {
+		child, err := getChild(node, key)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := applyOperation(child, tokens[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		return setChild(node, key, updated)
+	}
+
+	switch node := node.(type) {
+	case map[string]any:
+		current, exists := node[key]
+		switch op.Op {
+		case "add":
+			node[key] = op.Value
+		case "replace":
+			if !exists {
+				return nil, fmt.Errorf("no value at %q", key)
+			}
+			node[key] = op.Value
+		case "remove":
+			if !exists {
+				return nil, fmt.Errorf("no value at %q", key)
+			}
+			delete(node, key)
+		case "test":
+			if !exists || !reflect.DeepEqual(current, op.Value) {
+				return nil, fmt.Errorf("test failed")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported operation %q", op.Op)
+		}
+		return node, nil
+
+	case []any:
+		if op.Op == "add" && key == "-" {
+			return append(node, op.Value), nil
+		}
+
+		index, err := listIndex(key, len(node), op.Op == "add")
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add":
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = op.Value
+		case "replace":
+			node[index] = op.Value
+		case "remove":
+			node = append(node[:index], node[index+1:]...)
+		case "test":
+			if !reflect.DeepEqual(node[index], op.Value) {
+				return nil, fmt.Errorf("test failed")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported operation %q", op.Op)
+		}
+		return node, nil
+
+	default:
+		return nil, fmt.Errorf("cannot %s %q: parent is not an object or a list", op.Op, key)
+	}
+}
+
+func getChild(node any, key string) (any, error) {
+	switch node := node.(type) {
+	case map[string]any:
+		child, exists := node[key]
+		if !exists {
+			return nil, fmt.Errorf("no value at %q", key)
+		}
+		return child, nil
+	case []any:
+		index, err := listIndex(key, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		return node[index], nil
+	default:
+		return nil, fmt.Errorf("no value at %q", key)
+	}
+}
+
+func setChild(node any, key string, value any) (any, error) {
+	switch node := node.(type) {
+	case map[string]any:
+		node[key] = value
+		return node, nil
+	case []any:
+		index, err := listIndex(key, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+		return node, nil
+	default:
+		return nil, fmt.Errorf("no value at %q", key)
+	}
+}
+
+func listIndex(key string, length int, inclusive bool) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid list index %q", key)
+	}
+	if index > length || (index == length && !inclusive) {
+		return 0, fmt.Errorf("list index %d out of range", index)
+	}
+	return index, nil
+}
@@ -1,11 +1,49 @@
 package config
 
 import (
+	"fmt"
+
 	"gopkg.in/yaml.v3"
 )
 
+// ToolsConfig lists the tools enabled for each server. A tool is given by its name or, to configure
+// it, by a mapping:
+//
+//	github:
+//	  - get_me
+//	  - name: search_issues
+//	    transform:
+//	      - yq: '.items | map({"title": .title, "url": .html_url})'
+//	      - format: table
 type ToolsConfig struct {
-	ServerTools map[string][]string `yaml:",inline"`
+	ServerTools map[string][]string `yaml:"-"`
+	// Tools configures some of the enabled tools, by server and by tool.
+	Tools map[string]map[string]ToolConfig `yaml:"-"`
+}
+
+// ToolConfig configures a tool.
+type ToolConfig struct {
+	// Transform is applied, step by step, to the JSON results of the tool.
+	Transform []TransformStep `yaml:"transform,omitempty"`
+	// OutputSchema is the schema of the transformed results. Without it, the transformed results
+	// aren't structured.
+	OutputSchema map[string]any `yaml:"outputSchema,omitempty"`
+}
+
+// TransformStep is a step of a transform. Only one of its fields is set.
+type TransformStep struct {
+	// JQ is an alias of YQ. It takes a yq expression: jq shorthands, such as {title}, aren't supported.
+	JQ       string `yaml:"jq,omitempty"`
+	YQ       string `yaml:"yq,omitempty"`
+	JSONPath string `yaml:"jsonpath,omitempty"`
+	// Format is how the result is rendered as text: json, yaml or table. It's the last step.
+	Format string `yaml:"format,omitempty"`
+}
+
+// toolEntry is a configured tool in tools.yaml.
+type toolEntry struct {
+	Name       string `yaml:"name"`
+	ToolConfig `yaml:",inline"`
 }
 
 func ParseToolsConfig(toolsYaml []byte) (ToolsConfig, error) {
@@ -17,6 +55,63 @@ func ParseToolsConfig(toolsYaml []byte) (ToolsConfig, error) {
 	if toolsConfig.ServerTools == nil {
 		toolsConfig.ServerTools = make(map[string][]string)
 	}
+	if toolsConfig.Tools == nil {
+		toolsConfig.Tools = make(map[string]map[string]ToolConfig)
+	}
 
 	return toolsConfig, nil
 }
+
+func (c *ToolsConfig) UnmarshalYAML(node *yaml.Node) error {
+	var servers map[string][]yaml.Node
+	if err := node.Decode(&servers); err != nil {
+		return err
+	}
+
+	c.ServerTools = make(map[string][]string, len(servers))
+	c.Tools = make(map[string]map[string]ToolConfig)
+	for serverName, entries := range servers {
+		tools := []string{}
+		for _, entry := range entries {
+			if entry.Kind == yaml.ScalarNode {
+				tools = append(tools, entry.Value)
+				continue
+			}
+
+			var tool toolEntry
+			if err := entry.Decode(&tool); err != nil {
+				return fmt.Errorf("tool of %s at line %d: %w", serverName, entry.Line, err)
+			}
+			if tool.Name == "" {
+				return fmt.Errorf("tool of %s at line %d has no name", serverName, entry.Line)
+			}
+			tools = append(tools, tool.Name)
+
+			if c.Tools[serverName] == nil {
+				c.Tools[serverName] = map[string]ToolConfig{}
+			}
+			c.Tools[serverName][tool.Name] = tool.ToolConfig
+		}
+		c.ServerTools[serverName] = tools
+	}
+
+	return nil
+}
+
+// MarshalYAML writes the configured tools as mappings, and the others by their name. The
+// configuration of the tools that aren't enabled is dropped.
+func (c ToolsConfig) MarshalYAML() (any, error) {
+	servers := make(map[string][]any, len(c.ServerTools))
+	for serverName, tools := range c.ServerTools {
+		entries := []any{}
+		for _, toolName := range tools {
+			if toolConfig, found := c.Tools[serverName][toolName]; found {
+				entries = append(entries, toolEntry{Name: toolName, ToolConfig: toolConfig})
+			} else {
+				entries = append(entries, toolName)
+			}
+		}
+		servers[serverName] = entries
+	}
+	return servers, nil
+}
@@ -38,6 +38,8 @@ type Client interface {
 	ImageExists(ctx context.Context, name string) (bool, error)
 	PullImage(ctx context.Context, name string) error
 	PullImages(ctx context.Context, names ...string) error
+	SaveImages(ctx context.Context, names ...string) (io.ReadCloser, error)
+	LoadImages(ctx context.Context, input io.Reader) error
 	CreateNetwork(ctx context.Context, name string, internal bool, labels map[string]string) error
 	RemoveNetwork(ctx context.Context, name string) error
 	ConnectNetwork(ctx context.Context, networkName, container, hostname string) error
@@ -11,6 +11,7 @@ import (
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -90,3 +91,26 @@ func (c *dockerClient) pullImage(
 
 	return nil
 }
+
+func (c *dockerClient) SaveImages(ctx context.Context, names ...string) (io.ReadCloser, error) {
+	out, err := c.apiClient().ImageSave(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("saving docker images: %w", err)
+	}
+
+	return out, nil
+}
+
+func (c *dockerClient) LoadImages(ctx context.Context, input io.Reader) error {
+	response, err := c.apiClient().ImageLoad(ctx, input, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return fmt.Errorf("loading docker images: %w", err)
+	}
+	defer response.Body.Close()
+
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return fmt.Errorf("loading docker images: %w", err)
+	}
+
+	return nil
+}
@@ -211,3 +211,24 @@ func TestEvaluateMountAs(t *testing.T) {
 		),
 	)
 }
+
+func TestReferences(t *testing.T) {
+	references := References("--config={{ top . key |or:default}}:{{other| volume |bogus}}")
+
+	assert.Equal(t, []Reference{
+		{Path: "top.key", Functions: []string{"or:default"}},
+		{Path: "other", Functions: []string{" volume ", "bogus"}},
+	}, references)
+	assert.Empty(t, References("constant"))
+}
+
+func TestIsKnownFunction(t *testing.T) {
+	assert.True(t, IsKnownFunction("volume"))
+	assert.True(t, IsKnownFunction("or:[]"))
+	assert.True(t, IsKnownFunction("mount_as:/data"))
+	assert.False(t, IsKnownFunction("bogus"))
+	assert.True(t, IsKnownFunction(" volume "))
+	// Evaluating the placeholder ignores an or: that doesn't follow the | directly.
+	assert.False(t, IsKnownFunction(" or:[]"))
+	assert.Equal(t, "", Evaluate("{{key| or:default}}", map[string]any{}))
+}
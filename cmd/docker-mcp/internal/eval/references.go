@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"regexp"
+	"strings"
+)
+
+var placeholders = regexp.MustCompile(`{{.*?}}`)
+
+// Reference is a {{...}} placeholder found in an expression.
+type Reference struct {
+	Path string
+	// Functions are as written, spaces included.
+	Functions []string
+}
+
+// References lists the placeholders used in an expression, with their path normalized
+// the same way it is when the expression is evaluated.
+func References(expression string) []Reference {
+	var references []Reference
+
+	for _, term := range placeholders.FindAllString(expression, -1) {
+		path, functions, foundFunction := strings.Cut(term[2:len(term)-2], "|")
+
+		var parts []string
+		for part := range strings.SplitSeq(path, ".") {
+			parts = append(parts, strings.TrimSpace(part))
+		}
+
+		reference := Reference{Path: strings.Join(parts, ".")}
+		if foundFunction {
+			for f := range strings.SplitSeq(functions, "|") {
+				reference.Functions = append(reference.Functions, f)
+			}
+		}
+
+		references = append(references, reference)
+	}
+
+	return references
+}
+
+// IsKnownFunction tells whether a function can be used in a {{...}} placeholder. As when the
+// placeholder is evaluated, spaces around a function name are ignored, but the functions taking an
+// argument, like or:, must directly follow the |.
+func IsKnownFunction(f string) bool {
+	switch strings.TrimSpace(f) {
+	case "volume", "volume-target", "into", "first", "last":
+		return true
+	default:
+		return strings.HasPrefix(f, "or:") || strings.HasPrefix(f, "mount_as:")
+	}
+}
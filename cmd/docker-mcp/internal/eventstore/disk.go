@@ -0,0 +1,72 @@
+package eventstore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// NewDisk creates a store that keeps the events in a LevelDB database, so that long sessions
+// with large results don't use much memory. Sessions don't survive a restart of the gateway,
+// so the events left in the database by a previous run are removed.
+func NewDisk(dir string, options Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("opening event store: %w", err)
+	}
+
+	d := &disk{db: db}
+	if err := d.clear(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("removing previous events: %w", err)
+	}
+
+	return newStore(d, options), nil
+}
+
+type disk struct {
+	db *leveldb.DB
+}
+
+func (d *disk) put(key string, data []byte) error {
+	return d.db.Put([]byte(key), data, nil)
+}
+
+func (d *disk) get(key string) ([]byte, error) {
+	return d.db.Get([]byte(key), nil)
+}
+
+func (d *disk) delete(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	batch := new(leveldb.Batch)
+	for _, key := range keys {
+		batch.Delete([]byte(key))
+	}
+	return d.db.Write(batch, nil)
+}
+
+func (d *disk) close() error {
+	return d.db.Close()
+}
+
+func (d *disk) clear() error {
+	iter := d.db.NewIterator(nil, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(iter.Key())
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return d.db.Write(batch, nil)
+}
@@ -0,0 +1,49 @@
+package eventstore
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewMemory creates a store that keeps the events in memory.
+func NewMemory(options Options) *Store {
+	return newStore(&memory{data: map[string][]byte{}}, options)
+}
+
+type memory struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func (m *memory) put(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[key] = data
+	return nil
+}
+
+func (m *memory) get(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, found := m.data[key]
+	if !found {
+		return nil, fmt.Errorf("event %q not found", key)
+	}
+	return data, nil
+}
+
+func (m *memory) delete(keys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *memory) close() error {
+	return nil
+}
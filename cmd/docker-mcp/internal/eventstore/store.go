@@ -0,0 +1,263 @@
+// Package eventstore keeps the events sent on the streams of the streamable HTTP transport,
+// so that clients can resume a stream with Last-Event-ID after a dropped connection.
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+const (
+	DefaultRetention = time.Hour
+	DefaultMaxBytes  = 64 << 20
+)
+
+type Options struct {
+	// Retention is how long events are kept. Zero means DefaultRetention.
+	Retention time.Duration
+	// MaxBytes bounds the size of the events, across all sessions. The oldest events are dropped
+	// first. Zero means DefaultMaxBytes.
+	MaxBytes int64
+}
+
+// backend stores the data of the events. The Store keeps track of which events exist.
+type backend interface {
+	put(key string, data []byte) error
+	get(key string) ([]byte, error)
+	delete(keys []string) error
+	close() error
+}
+
+// Store is an mcp.EventStore, shared by all the sessions of the gateway.
+type Store struct {
+	options Options
+	backend backend
+	now     func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]map[mcp.StreamID]*stream
+	bytes    int64
+}
+
+// stream tracks the events of a stream that are still stored.
+type stream struct {
+	// first is the index of the oldest stored event.
+	first  int
+	events []event
+}
+
+type event struct {
+	size int
+	at   time.Time
+}
+
+func newStore(backend backend, options Options) *Store {
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+
+	return &Store{
+		options:  options,
+		backend:  backend,
+		now:      time.Now,
+		sessions: map[string]map[mcp.StreamID]*stream{},
+	}
+}
+
+func (s *Store) Open(_ context.Context, sessionID string, streamID mcp.StreamID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stream(sessionID, streamID)
+	return nil
+}
+
+func (s *Store) Append(
+	_ context.Context,
+	sessionID string,
+	streamID mcp.StreamID,
+	data []byte,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stream(sessionID, streamID)
+	index := st.first + len(st.events)
+	if err := s.backend.put(key(sessionID, streamID, index), data); err != nil {
+		return err
+	}
+	st.events = append(st.events, event{size: len(data), at: s.now()})
+	s.bytes += int64(len(data))
+
+	return s.purge()
+}
+
+func (s *Store) After(
+	ctx context.Context,
+	sessionID string,
+	streamID mcp.StreamID,
+	index int,
+) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		keys, err := s.keysAfter(sessionID, streamID, index)
+		replay := replayFromContext(ctx)
+		if replay != nil && !replay.counted {
+			replay.counted = true
+			if err != nil {
+				telemetry.RecordStreamResumption(ctx, "purged")
+			} else {
+				telemetry.RecordStreamResumption(ctx, "replayed")
+				telemetry.RecordReplayedEvents(ctx, int64(len(keys)))
+			}
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, k := range keys {
+			data, err := s.backend.get(k)
+			if err != nil {
+				// The event was purged in the meantime.
+				yield(nil, fmt.Errorf("%w: %s", mcp.ErrEventsPurged, err))
+				return
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Store) SessionClosed(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for streamID, st := range s.sessions[sessionID] {
+		for i, e := range st.events {
+			keys = append(keys, key(sessionID, streamID, st.first+i))
+			s.bytes -= int64(e.size)
+		}
+	}
+	delete(s.sessions, sessionID)
+
+	return s.backend.delete(keys)
+}
+
+// Close releases the resources of the store.
+func (s *Store) Close() error {
+	return s.backend.close()
+}
+
+func (s *Store) keysAfter(sessionID string, streamID mcp.StreamID, index int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, found := s.sessions[sessionID][streamID]
+	if !found {
+		return nil, fmt.Errorf("unknown stream %q in session %q", streamID, sessionID)
+	}
+	start := index + 1
+	if start < st.first {
+		return nil, fmt.Errorf("index %d of stream %q in session %q: %w",
+			index, streamID, sessionID, mcp.ErrEventsPurged)
+	}
+
+	var keys []string
+	for i := start; i < st.first+len(st.events); i++ {
+		keys = append(keys, key(sessionID, streamID, i))
+	}
+	return keys, nil
+}
+
+// stream must be called with s.mu held.
+func (s *Store) stream(sessionID string, streamID mcp.StreamID) *stream {
+	streams, found := s.sessions[sessionID]
+	if !found {
+		streams = map[mcp.StreamID]*stream{}
+		s.sessions[sessionID] = streams
+	}
+
+	st, found := streams[streamID]
+	if !found {
+		st = &stream{}
+		streams[streamID] = st
+	}
+	return st
+}
+
+// purge drops the events older than the retention, then the oldest events until the store fits
+// in its maximum size. The last event is always kept, so that a stream can still be resumed
+// right after a large event. It must be called with s.mu held.
+func (s *Store) purge() error {
+	var keys []string
+	drop := func(sessionID string, streamID mcp.StreamID, st *stream) {
+		keys = append(keys, key(sessionID, streamID, st.first))
+		s.bytes -= int64(st.events[0].size)
+		st.events = st.events[1:]
+		st.first++
+	}
+
+	expired := s.now().Add(-s.options.Retention)
+	for sessionID, streams := range s.sessions {
+		for streamID, st := range streams {
+			for len(st.events) > 0 && st.events[0].at.Before(expired) {
+				drop(sessionID, streamID, st)
+			}
+		}
+	}
+
+	for s.bytes > s.options.MaxBytes {
+		var oldestSession string
+		var oldestStream mcp.StreamID
+		var oldest *stream
+		for sessionID, streams := range s.sessions {
+			for streamID, st := range streams {
+				if len(st.events) > 1 && (oldest == nil || st.events[0].at.Before(oldest.events[0].at)) {
+					oldestSession, oldestStream, oldest = sessionID, streamID, st
+				}
+			}
+		}
+		if oldest == nil {
+			break
+		}
+		drop(oldestSession, oldestStream, oldest)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.backend.delete(keys)
+}
+
+func key(sessionID string, streamID mcp.StreamID, index int) string {
+	return fmt.Sprintf("%s\x00%s\x00%016x", sessionID, streamID, index)
+}
+
+type replayKey struct{}
+
+type replay struct {
+	counted bool
+}
+
+// WithReplay marks the context of a request resuming a stream, so that the replayed events
+// are counted.
+func WithReplay(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayKey{}, &replay{})
+}
+
+func replayFromContext(ctx context.Context) *replay {
+	r, _ := ctx.Value(replayKey{}).(*replay)
+	return r
+}
@@ -0,0 +1,131 @@
+package eventstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func after(t *testing.T, s *Store, sessionID string, streamID mcp.StreamID, index int) ([]string, error) {
+	t.Helper()
+
+	var events []string
+	for data, err := range s.After(t.Context(), sessionID, streamID, index) {
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, string(data))
+	}
+	return events, nil
+}
+
+func stores(t *testing.T, options Options) map[string]*Store {
+	t.Helper()
+
+	disk, err := NewDisk(t.TempDir(), options)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = disk.Close() })
+
+	return map[string]*Store{
+		"memory": NewMemory(options),
+		"disk":   disk,
+	}
+}
+
+func TestStore(t *testing.T) {
+	for name, s := range stores(t, Options{}) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, s.Open(t.Context(), "session", "stream"))
+			for _, data := range []string{"a", "b", "c"} {
+				require.NoError(t, s.Append(t.Context(), "session", "stream", []byte(data)))
+			}
+
+			events, err := after(t, s, "session", "stream", -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b", "c"}, events)
+
+			events, err = after(t, s, "session", "stream", 1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"c"}, events)
+
+			_, err = after(t, s, "session", "unknown", -1)
+			require.Error(t, err)
+
+			require.NoError(t, s.SessionClosed(t.Context(), "session"))
+			_, err = after(t, s, "session", "stream", -1)
+			require.Error(t, err)
+			assert.Zero(t, s.bytes)
+		})
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	for name, s := range stores(t, Options{Retention: time.Minute}) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			s.now = func() time.Time { return now }
+
+			require.NoError(t, s.Append(t.Context(), "session", "stream", []byte("old")))
+			now = now.Add(2 * time.Minute)
+			require.NoError(t, s.Append(t.Context(), "session", "stream", []byte("new")))
+
+			_, err := after(t, s, "session", "stream", -1)
+			require.ErrorIs(t, err, mcp.ErrEventsPurged)
+
+			events, err := after(t, s, "session", "stream", 0)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"new"}, events)
+		})
+	}
+}
+
+func TestStoreMaxBytes(t *testing.T) {
+	for name, s := range stores(t, Options{MaxBytes: 4}) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			s.now = func() time.Time {
+				now = now.Add(time.Millisecond)
+				return now
+			}
+
+			require.NoError(t, s.Append(t.Context(), "first", "stream", []byte("aa")))
+			require.NoError(t, s.Append(t.Context(), "second", "stream", []byte("bb")))
+			require.NoError(t, s.Append(t.Context(), "first", "stream", []byte("cc")))
+
+			// The oldest event was dropped to make room.
+			_, err := after(t, s, "first", "stream", -1)
+			require.ErrorIs(t, err, mcp.ErrEventsPurged)
+			events, err := after(t, s, "first", "stream", 0)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"cc"}, events)
+			events, err = after(t, s, "second", "stream", -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bb"}, events)
+
+			// The last event of a stream is kept, even if it's larger than the store.
+			require.NoError(t, s.Append(t.Context(), "third", "stream", []byte("large event")))
+			events, err = after(t, s, "third", "stream", -1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"large event"}, events)
+		})
+	}
+}
+
+func TestDiskRemovesPreviousEvents(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewDisk(dir, Options{})
+	require.NoError(t, err)
+	require.NoError(t, s.Append(t.Context(), "session", "stream", []byte("a")))
+	require.NoError(t, s.Close())
+
+	s, err = NewDisk(dir, Options{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.backend.get(key("session", "stream", 0))
+	require.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
+)
+
+// configureAuth sets up the authentication of the HTTP transports and wraps the listener with TLS if needed.
+func (g *Gateway) configureAuth(ctx context.Context, ln net.Listener) (net.Listener, error) {
+	var chain auth.Chain
+	var clientCertificate *auth.ClientCertificate
+
+	if len(g.AuthTokenSecrets) > 0 {
+		tokens, err := g.readSecrets(ctx, g.AuthTokenSecrets)
+		if err != nil {
+			return nil, fmt.Errorf("reading auth tokens: %w", err)
+		}
+		chain = append(chain, auth.NewTokens(tokens))
+	}
+
+	if g.AuthClientCA != "" {
+		var err error
+		clientCertificate, err = auth.NewClientCertificate(g.AuthClientCA)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, clientCertificate)
+	}
+
+	if g.AuthJWKSURL != "" {
+		if g.AuthIssuer == "" {
+			return nil, errors.New("--auth-oauth-issuer is required to validate OAuth tokens")
+		}
+		// Tokens issued for other resources must never be accepted.
+		if g.AuthAudience == "" {
+			return nil, errors.New("--auth-oauth-audience is required to validate OAuth tokens")
+		}
+		oauth, err := auth.NewOAuth(auth.OAuthOptions{
+			Issuer:   g.AuthIssuer,
+			JWKSURL:  g.AuthJWKSURL,
+			Audience: g.AuthAudience,
+			Scopes:   g.AuthScopes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, oauth)
+	}
+
+	g.authenticator = chain
+	if len(chain) > 0 {
+		log("- Authentication enabled for the HTTP transports")
+	}
+
+	return g.configureTLS(ctx, ln, clientCertificate)
+}
+
+// authenticate only lets authenticated clients reach an MCP handler, when authentication is enabled.
+func (g *Gateway) authenticate(handler http.Handler) http.Handler {
+	if len(g.authenticator) == 0 {
+		return handler
+	}
+	return auth.Middleware(g.authenticator, g.AuthJWKSURL != "")(handler)
+}
+
+// registerAuthHandlers serves the OAuth protected resource metadata, so that clients can discover
+// the authorization server.
+func (g *Gateway) registerAuthHandlers(mux *http.ServeMux) {
+	if g.AuthJWKSURL == "" {
+		return
+	}
+	mux.Handle(
+		auth.ProtectedResourceMetadataPath,
+		auth.MetadataHandler(g.AuthAudience, []string{g.AuthIssuer}, g.AuthScopes),
+	)
+}
+
+// readSecrets reads named secrets from the same places as the secrets of the servers.
+func (g *Gateway) readSecrets(ctx context.Context, names []string) (map[string]string, error) {
+	var lastErr error
+	for secretsPath := range strings.SplitSeq(g.secretsPath, ":") {
+		var secrets map[string]string
+		var err error
+		if secretsPath == "docker-desktop" {
+			secrets, err = g.docker.ReadSecrets(ctx, names, true)
+		} else {
+			secrets, err = (&FileBasedConfiguration{}).readSecretsFromFile(ctx, secretsPath)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		found := map[string]string{}
+		for _, name := range names {
+			if value, ok := secrets[name]; ok && value != "" {
+				found[name] = value
+			}
+		}
+		if len(found) == len(names) {
+			return found, nil
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("secrets %s not found", strings.Join(names, ", "))
+}
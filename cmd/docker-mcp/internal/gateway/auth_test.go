@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureOAuthRequiresAnAudience(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	g := &Gateway{Options: Options{AuthIssuer: "https://login.corp", AuthJWKSURL: "https://login.corp/keys"}}
+	_, err = g.configureAuth(t.Context(), ln)
+	require.ErrorContains(t, err, "--auth-oauth-audience is required")
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/transforms"
 )
 
 type Capabilities struct {
@@ -25,21 +27,26 @@ type Capabilities struct {
 type ToolRegistration struct {
 	Tool    *mcp.Tool
 	Handler mcp.ToolHandler
+	// ServerName is the server or the tool group providing the tool.
+	ServerName string
 }
 
 type PromptRegistration struct {
-	Prompt  *mcp.Prompt
-	Handler mcp.PromptHandler
+	Prompt       *mcp.Prompt
+	Handler      mcp.PromptHandler
+	ServerConfig *catalog.ServerConfig
 }
 
 type ResourceRegistration struct {
-	Resource *mcp.Resource
-	Handler  mcp.ResourceHandler
+	Resource     *mcp.Resource
+	Handler      mcp.ResourceHandler
+	ServerConfig *catalog.ServerConfig
 }
 
 type ResourceTemplateRegistration struct {
 	ResourceTemplate mcp.ResourceTemplate
 	Handler          mcp.ResourceHandler
+	ServerConfig     *catalog.ServerConfig
 }
 
 func (g *Gateway) listCapabilities(
@@ -86,10 +93,11 @@ func (g *Gateway) listCapabilities(
 						if !isToolEnabled(configuration, serverConfig.Name, serverConfig.Spec.Image, tool.Name, g.ToolNames) {
 							continue
 						}
-						capabilities.Tools = append(capabilities.Tools, ToolRegistration{
-							Tool:    tool,
-							Handler: g.mcpServerToolHandler(serverConfig, g.mcpServer, tool.Annotations),
-						})
+						capabilities.Tools = append(capabilities.Tools, transformTool(configuration, ToolRegistration{
+							Tool:       tool,
+							Handler:    g.mcpServerToolHandler(serverConfig, g.mcpServer, tool.Annotations),
+							ServerName: serverConfig.Name,
+						}))
 					}
 				}
 
@@ -100,8 +108,9 @@ func (g *Gateway) listCapabilities(
 
 					for _, prompt := range prompts.Prompts {
 						capabilities.Prompts = append(capabilities.Prompts, PromptRegistration{
-							Prompt:  prompt,
-							Handler: g.mcpServerPromptHandler(serverConfig, g.mcpServer),
+							Prompt:       prompt,
+							Handler:      g.mcpServerPromptHandler(serverConfig, g.mcpServer),
+							ServerConfig: serverConfig,
 						})
 					}
 				}
@@ -115,8 +124,9 @@ func (g *Gateway) listCapabilities(
 						capabilities.Resources = append(
 							capabilities.Resources,
 							ResourceRegistration{
-								Resource: resource,
-								Handler:  g.mcpServerResourceHandler(serverConfig, g.mcpServer),
+								Resource:     resource,
+								Handler:      g.mcpServerResourceHandler(serverConfig, g.mcpServer),
+								ServerConfig: serverConfig,
 							},
 						)
 					}
@@ -141,6 +151,7 @@ func (g *Gateway) listCapabilities(
 									serverConfig,
 									g.mcpServer,
 								),
+								ServerConfig: serverConfig,
 							},
 						)
 					}
@@ -198,10 +209,11 @@ func (g *Gateway) listCapabilities(
 					// This is a complex conversion that needs proper implementation
 				}
 
-				capabilities.Tools = append(capabilities.Tools, ToolRegistration{
-					Tool:    &mcpTool,
-					Handler: g.mcpToolHandler(tool),
-				})
+				capabilities.Tools = append(capabilities.Tools, transformTool(configuration, ToolRegistration{
+					Tool:       &mcpTool,
+					Handler:    g.mcpToolHandler(serverName, tool),
+					ServerName: serverName,
+				}))
 			}
 
 			lock.Lock()
@@ -262,6 +274,32 @@ func (c *Capabilities) PromptNames() []string {
 	return names
 }
 
+// transformTool applies the result transform configured for a tool in tools.yaml, and advertises
+// the schema of the transformed results.
+func transformTool(configuration Configuration, tool ToolRegistration) ToolRegistration {
+	toolConfig, found := configuration.tools.Tools[tool.ServerName][tool.Tool.Name]
+	if !found || (len(toolConfig.Transform) == 0 && toolConfig.OutputSchema == nil) {
+		return tool
+	}
+
+	pipeline, err := transforms.New(toolConfig)
+	if err != nil {
+		logf("  > Can't transform the results of %s: %s", tool.Tool.Name, err)
+		return tool
+	}
+	outputSchema, err := pipeline.OutputSchema()
+	if err != nil {
+		logf("  > Can't transform the results of %s: %s", tool.Tool.Name, err)
+		return tool
+	}
+
+	transformed := *tool.Tool
+	transformed.OutputSchema = outputSchema
+	tool.Tool = &transformed
+	tool.Handler = pipeline.Handler(tool.Handler)
+	return tool
+}
+
 func isToolEnabled(
 	configuration Configuration,
 	serverName, serverImage, toolName string,
@@ -15,6 +15,7 @@ import (
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/eval"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/gateway/proxies"
 	mcpclient "github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mcp"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
 )
 
 type clientKey struct {
@@ -36,6 +37,11 @@ type clientPool struct {
 	networks    []string
 	docker      docker.Client
 	gateway     *Gateway
+
+	// leases counts, by server, the kept clients of the long-lived servers this replica holds the
+	// lease on, when replicas share their state.
+	leaseLock sync.Mutex
+	leases    map[string]int
 }
 
 type clientConfig struct {
@@ -98,7 +104,7 @@ func (cp *clientPool) AcquireClient(
 		getter = newClientGetter(serverConfig, cp, config)
 
 		// If the client is long running, save it for later
-		if cp.longLived(serverConfig, config) {
+		if cp.longLived(serverConfig, config) && cp.keepsServer(ctx, serverConfig.Name) {
 			c = context.Background()
 			cp.clientLock.Lock()
 			cp.keptClients[key] = keptClient{
@@ -117,8 +123,9 @@ func (cp *clientPool) AcquireClient(
 		defer cp.clientLock.Unlock()
 
 		// Wasn't successful, remove it
-		if cp.longLived(serverConfig, config) {
+		if _, kept := cp.keptClients[key]; kept && cp.longLived(serverConfig, config) {
 			delete(cp.keptClients, key)
+			cp.releaseServer(ctx, serverConfig.Name)
 		}
 
 		return nil, err
@@ -127,6 +134,27 @@ func (cp *clientPool) AcquireClient(
 	return client, nil
 }
 
+// KeptClient returns the client of a long-lived server kept for a session. It never starts a server.
+func (cp *clientPool) KeptClient(
+	ctx context.Context,
+	serverName string,
+	session *mcp.ServerSession,
+) (mcpclient.Client, bool) {
+	cp.clientLock.RLock()
+	kc, found := cp.keptClients[clientKey{serverName: serverName, session: session}]
+	cp.clientLock.RUnlock()
+	if !found {
+		return nil, false
+	}
+
+	// The client is either created, or being created.
+	client, err := kc.Getter.GetClient(ctx)
+	if err != nil {
+		return nil, false
+	}
+	return client, true
+}
+
 func (cp *clientPool) ReleaseClient(client mcpclient.Client) {
 	foundKept := false
 	cp.clientLock.RLock()
@@ -152,14 +180,23 @@ func (cp *clientPool) Close() {
 	cp.clientLock.Unlock()
 
 	// Close all clients
-	for _, keptClient := range existingMap {
+	for key, keptClient := range existingMap {
 		client, err := keptClient.Getter.GetClient(context.TODO()) // should be cached
 		if err == nil {
 			client.Session().Close()
 		}
+		cp.releaseServer(context.TODO(), key.serverName)
 	}
 }
 
+// replayer returns the recordings to replay instead of running the servers, if any.
+func (cp *clientPool) replayer() *recording.Replayer {
+	if cp.gateway == nil {
+		return nil
+	}
+	return cp.gateway.replayer
+}
+
 func (cp *clientPool) SetNetworks(networks []string) {
 	cp.networks = networks
 }
@@ -200,13 +237,14 @@ func (cp *clientPool) runToolContainer(
 	}
 
 	// Image
-	args = append(args, tool.Container.Image)
+	image := cp.RegistryMirrors.Rewrite(tool.Container.Image)
+	args = append(args, image)
 
 	// Command
 	command := eval.EvaluateList(tool.Container.Command, arguments)
 	args = append(args, command...)
 
-	log("  - Running container", tool.Container.Image, "with args", args)
+	log("  - Running container", image, "with args", args)
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	if cp.Verbose {
@@ -395,8 +433,14 @@ func (cg *clientGetter) GetClient(ctx context.Context) (mcpclient.Client, error)
 
 			var client mcpclient.Client
 
-			// Deprecated: Use Remote instead
-			if cg.serverConfig.Spec.SSEEndpoint != "" {
+			if replayer := cg.cp.replayer(); replayer != nil {
+				transport, err := replayer.Transport(cg.serverConfig.Name)
+				if err != nil {
+					return nil, err
+				}
+				client = mcpclient.NewTransportClient(cg.serverConfig.Name, transport)
+			} else if cg.serverConfig.Spec.SSEEndpoint != "" {
+				// Deprecated: Use Remote instead
 				client = mcpclient.NewRemoteMCPClient(cg.serverConfig)
 			} else if cg.serverConfig.Spec.Remote.URL != "" {
 				client = mcpclient.NewRemoteMCPClient(cg.serverConfig)
@@ -411,7 +455,7 @@ func (cg *clientGetter) GetClient(ctx context.Context) (mcpclient.Client, error)
 					}
 				}
 
-				image := cg.serverConfig.Spec.Image
+				image := cg.cp.RegistryMirrors.Rewrite(cg.serverConfig.Spec.Image)
 				var readOnly *bool
 				if cg.clientConfig != nil {
 					readOnly = cg.clientConfig.readOnly
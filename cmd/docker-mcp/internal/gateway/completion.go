@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+)
+
+// maxCompletionValues is the maximum number of values a completion can return, as per the MCP spec.
+const maxCompletionValues = 100
+
+// completionHandler answers completion/complete requests by routing them to the server that owns the
+// prompt or the resource template. Completions are requested as the user types, so they're only
+// routed to servers already running for the session: they never start a server.
+func (g *Gateway) completionHandler(
+	ctx context.Context,
+	req *mcp.CompleteRequest,
+) (*mcp.CompleteResult, error) {
+	ref := req.Params.Ref
+	if ref == nil {
+		return nil, errors.New("missing completion reference")
+	}
+
+	var owner *catalog.ServerConfig
+	g.ownersMu.RLock()
+	switch ref.Type {
+	case "ref/prompt":
+		owner = g.promptOwners[ref.Name]
+	case "ref/resource":
+		owner = g.resourceTemplateOwners[ref.URI]
+	}
+	g.ownersMu.RUnlock()
+
+	if owner == nil {
+		return completionResult(nil), nil
+	}
+
+	client, found := g.clientPool.KeptClient(ctx, owner.Name, req.Session)
+	if !found {
+		return completionResult(nil), nil
+	}
+
+	if initResult := client.Session().InitializeResult(); initResult == nil ||
+		initResult.Capabilities == nil || initResult.Capabilities.Completions == nil {
+		return completionResult(nil), nil
+	}
+
+	result, err := client.Session().Complete(ctx, req.Params)
+	if err != nil {
+		return nil, fmt.Errorf("completing with server %s: %w", owner.Name, err)
+	}
+	return result, nil
+}
+
+func completionResult(values []string) *mcp.CompleteResult {
+	result := &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values: []string{},
+			Total:  len(values),
+		},
+	}
+	if len(values) > maxCompletionValues {
+		result.Completion.Values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	} else if len(values) > 0 {
+		result.Completion.Values = values
+	}
+	return result
+}
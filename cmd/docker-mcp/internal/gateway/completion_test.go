@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
+)
+
+func completionGateway() *Gateway {
+	g := &Gateway{
+		promptOwners: map[string]*catalog.ServerConfig{"summarize": {Name: "notes"}},
+	}
+	g.clientPool = newClientPool(Options{}, nil, g)
+	return g
+}
+
+func complete(t *testing.T, g *Gateway, ref *mcp.CompleteReference, argument, value string) []string {
+	t.Helper()
+
+	result, err := g.completionHandler(t.Context(), &mcp.CompleteRequest{
+		Params: &mcp.CompleteParams{
+			Ref:      ref,
+			Argument: mcp.CompleteParamsArgument{Name: argument, Value: value},
+		},
+	})
+	require.NoError(t, err)
+	return result.Completion.Values
+}
+
+func TestCompleteUnknownReference(t *testing.T) {
+	g := completionGateway()
+
+	values := complete(t, g, &mcp.CompleteReference{Type: "ref/prompt", Name: "unknown"}, "name", "")
+	assert.Empty(t, values)
+
+	// Servers that aren't running aren't started to complete an argument.
+	values = complete(t, g, &mcp.CompleteReference{Type: "ref/prompt", Name: "summarize"}, "topic", "")
+	assert.Empty(t, values)
+
+	values = complete(t, g, &mcp.CompleteReference{Type: "ref/resource", URI: "file:///{path}"}, "path", "")
+	assert.Empty(t, values)
+}
+
+func TestCompleteWithKeptServer(t *testing.T) {
+	recordings := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(recordings, "notes.jsonl"), []byte(
+		`{"method":"initialize","result":{"protocolVersion":"2025-06-18","capabilities":{"completions":{}},"serverInfo":{"name":"notes","version":"1"}}}`+"\n"+
+			`{"method":"completion/complete","params":{"argument":{"name":"topic","value":"g"},"ref":{"name":"summarize","type":"ref/prompt"}},"result":{"completion":{"values":["golang","gateway"]}}}`+"\n",
+	), 0o644))
+	replayer, err := recording.NewReplayer(recordings, recording.ReplayOptions{})
+	require.NoError(t, err)
+
+	g := completionGateway()
+	g.replayer = replayer
+	ss := serverSession(t)
+	serverConfig := &catalog.ServerConfig{Name: "notes", Spec: catalog.Server{LongLived: true}}
+	_, err = g.clientPool.AcquireClient(t.Context(), serverConfig, &clientConfig{serverSession: ss})
+	require.NoError(t, err)
+
+	result, err := g.completionHandler(t.Context(), &mcp.CompleteRequest{
+		Session: ss,
+		Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "summarize"},
+			Argument: mcp.CompleteParamsArgument{Name: "topic", Value: "g"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang", "gateway"}, result.Completion.Values)
+}
+
+func TestCompletionResultIsCapped(t *testing.T) {
+	var values []string
+	for i := range 150 {
+		values = append(values, fmt.Sprintf("server-%d", i))
+	}
+
+	result := completionResult(values)
+	assert.Len(t, result.Completion.Values, maxCompletionValues)
+	assert.Equal(t, 150, result.Completion.Total)
+	assert.True(t, result.Completion.HasMore)
+}
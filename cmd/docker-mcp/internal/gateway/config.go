@@ -1,6 +1,11 @@
 package gateway
 
-import "github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+import (
+	"time"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
+)
 
 type Config struct {
 	Options
@@ -18,12 +23,29 @@ type Options struct {
 	Transport               string
 	ToolNames               []string
 	Interceptors            []string
+	InterceptorsFile        string
 	OciRef                  []string
 	Verbose                 bool
 	LongLived               bool
 	DebugDNS                bool
 	LogCalls                bool
 	BlockSecrets            bool
+	SecretScanMode          string
+	SecretScanRules         string
+	SecretScanEntropy       float64
+	SecretScanAllow         []string
+	PIIPolicies             []string
+	PIILocales              []string
+	PIIAuditLog             string
+	PromptInjection         []string
+	ResultLimits            []string
+	ResultRetention         time.Duration
+	ResultStoreMaxBytes     int64
+	ResultSessionMaxBytes   int64
+	Record                  string
+	Replay                  string
+	ReplayMatch             string
+	ReplayIgnore            []string
 	BlockNetwork            bool
 	VerifySignatures        bool
 	DryRun                  bool
@@ -34,4 +56,26 @@ type Options struct {
 	Central                 bool
 	OAuthInterceptorEnabled bool
 	DynamicTools            bool
+	RegistryMirrors         mirror.Rules
+	DisableSampling         bool
+	SamplingDisabledServers []string
+	SamplingMaxTokens       int64
+	SamplingAllowedModels   []string
+	SessionIdleTimeout      time.Duration
+	TLSCert                 string
+	TLSKey                  string
+	TLSSelfSigned           bool
+	EventStore              string
+	EventStorePath          string
+	EventRetention          time.Duration
+	EventStoreMaxBytes      int64
+	SharedState             string
+	ReplicaID               string
+	ReplicaURL              string
+	AuthTokenSecrets        []string
+	AuthClientCA            string
+	AuthIssuer              string
+	AuthJWKSURL             string
+	AuthAudience            string
+	AuthScopes              []string
 }
@@ -15,7 +15,9 @@ import (
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/oci"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/transforms"
 )
 
 type Configurator interface {
@@ -28,12 +30,23 @@ type Configuration struct {
 	config      map[string]map[string]any
 	tools       config.ToolsConfig
 	secrets     map[string]string
+	mirrors     mirror.Rules
 }
 
 func (c *Configuration) ServerNames() []string {
 	return c.serverNames
 }
 
+// CatalogServerNames returns the sorted names of all the servers in the catalogs, enabled or not.
+func (c *Configuration) CatalogServerNames() []string {
+	names := make([]string, 0, len(c.servers))
+	for name := range c.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (c *Configuration) DockerImages() []string {
 	uniqueDockerImages := map[string]bool{}
 
@@ -44,10 +57,10 @@ func (c *Configuration) DockerImages() []string {
 		case !found:
 			log("MCP server not found:", serverName)
 		case serverConfig != nil && serverConfig.Spec.Image != "":
-			uniqueDockerImages[serverConfig.Spec.Image] = true
+			uniqueDockerImages[c.mirrors.Rewrite(serverConfig.Spec.Image)] = true
 		case tools != nil:
 			for _, tool := range *tools {
-				uniqueDockerImages[tool.Container.Image] = true
+				uniqueDockerImages[c.mirrors.Rewrite(tool.Container.Image)] = true
 			}
 		}
 	}
@@ -100,6 +113,7 @@ type FileBasedConfiguration struct {
 	SecretsPath        string           // Optional, if not set, use Docker Desktop's secrets API
 	OciRef             []string         // OCI references to fetch server definitions from
 	MCPRegistryServers []catalog.Server // Servers fetched from MCP registries
+	RegistryMirrors    mirror.Rules     // Rewrite rules applied to the images of the servers
 	Watch              bool
 	Central            bool
 
@@ -360,6 +374,7 @@ func (c *FileBasedConfiguration) readOnce(ctx context.Context) (Configuration, e
 		config:      serversConfig,
 		tools:       serverToolsConfig,
 		secrets:     secrets,
+		mirrors:     c.RegistryMirrors,
 	}, nil
 }
 
@@ -461,6 +476,7 @@ func (c *FileBasedConfiguration) readToolsConfig(ctx context.Context) (config.To
 
 	mergedToolsConfig := config.ToolsConfig{
 		ServerTools: make(map[string][]string),
+		Tools:       make(map[string]map[string]config.ToolConfig),
 	}
 
 	for _, toolsPath := range c.ToolsPath {
@@ -491,6 +507,14 @@ func (c *FileBasedConfiguration) readToolsConfig(ctx context.Context) (config.To
 				)
 			}
 			mergedToolsConfig.ServerTools[serverName] = serverTools
+			mergedToolsConfig.Tools[serverName] = toolsConfig.Tools[serverName]
+
+			for toolName, toolConfig := range toolsConfig.Tools[serverName] {
+				if _, err := transforms.New(toolConfig); err != nil {
+					return config.ToolsConfig{}, fmt.Errorf("tool %s of server %s in tools file %s: %w",
+						toolName, serverName, toolsPath, err)
+				}
+			}
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
 )
 
 func TestReadServersFromOci(t *testing.T) {
@@ -133,3 +134,26 @@ func TestReadServersFromOciNoRefs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, servers, "Should return empty map when no OCI references provided")
 }
+
+func TestDockerImagesWithRegistryMirrors(t *testing.T) {
+	mirrors, err := mirror.ParseRules([]string{"docker.io/mcp/*=registry.corp/mcp-mirror/*"})
+	require.NoError(t, err)
+
+	configuration := Configuration{
+		serverNames: []string{"fetch", "poci", "remote"},
+		servers: map[string]catalog.Server{
+			"fetch": {Image: "mcp/fetch@sha256:abc"},
+			"poci": {Tools: []catalog.Tool{
+				{Name: "t", Container: catalog.Container{Image: "ghcr.io/org/tool:1"}},
+			}},
+			"remote": {Remote: catalog.Remote{URL: "https://example.com/mcp"}},
+		},
+		mirrors: mirrors,
+	}
+
+	assert.Equal(
+		t,
+		[]string{"ghcr.io/org/tool:1", "registry.corp/mcp-mirror/fetch@sha256:abc"},
+		configuration.DockerImages(),
+	)
+}
@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
 )
@@ -39,7 +40,9 @@ func inferServerType(serverConfig *catalog.ServerConfig) string {
 	return "unknown"
 }
 
-func (g *Gateway) mcpToolHandler(tool catalog.Tool) mcp.ToolHandler {
+// mcpToolHandler runs a tool in its own container. Its calls are recorded, and replayed instead of
+// running the container, like the calls to the other servers.
+func (g *Gateway) mcpToolHandler(serverName string, tool catalog.Tool) mcp.ToolHandler {
 	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Convert CallToolParamsRaw to CallToolParams
 		params := &mcp.CallToolParams{
@@ -54,7 +57,15 @@ func (g *Gateway) mcpToolHandler(tool catalog.Tool) mcp.ToolHandler {
 			}
 			params.Arguments = args
 		}
-		return g.clientPool.runToolContainer(ctx, tool, params)
+
+		if g.replayer != nil {
+			return g.replayer.CallTool(serverName, params)
+		}
+		result, err := g.clientPool.runToolContainer(ctx, tool, params)
+		if err == nil && g.recorder != nil {
+			g.recorder.RecordToolCall(serverName, params, result)
+		}
+		return result, err
 	}
 }
 
@@ -98,6 +109,9 @@ func (g *Gateway) mcpServerToolHandler(
 		} else if serverConfig.Spec.Remote.URL != "" {
 			spanAttrs = append(spanAttrs, attribute.String("mcp.server.endpoint", serverConfig.Spec.Remote.URL))
 		}
+		if identity := auth.FromRequest(req); identity != nil {
+			spanAttrs = append(spanAttrs, attribute.String("mcp.client.identity", identity.String()))
+		}
 
 		ctx, span := telemetry.StartToolCallSpan(ctx, req.Params.Name, spanAttrs...)
 		defer span.End()
@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
+)
+
+func TestContainerToolsAreReplayed(t *testing.T) {
+	recordings := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(recordings, "poci.jsonl"), []byte(
+		`{"method":"tools/call","params":{"name":"curl","arguments":{"url":"https://example.com"}},"result":{"content":[{"type":"text","text":"<html>"}]}}`+"\n",
+	), 0o644))
+	replayer, err := recording.NewReplayer(recordings, recording.ReplayOptions{})
+	require.NoError(t, err)
+
+	// The container would fail to start, the image doesn't exist.
+	g := &Gateway{replayer: replayer}
+	g.clientPool = newClientPool(Options{}, nil, g)
+	handler := g.mcpToolHandler("poci", catalog.Tool{Name: "curl", Container: catalog.Container{Image: "mcp/unknown"}})
+
+	call := func(url string) (*mcp.CallToolResult, error) {
+		return handler(t.Context(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+			Name:      "curl",
+			Arguments: json.RawMessage(`{"url":"` + url + `"}`),
+		}})
+	}
+
+	result, err := call("https://example.com")
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "<html>", result.Content[0].(*mcp.TextContent).Text)
+
+	_, err = call("https://example.org")
+	require.ErrorContains(t, err, "no recorded response to tools/call")
+}
@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// serverInstance is an isolated MCP server, with its own registrations, serving a selection of servers.
+type serverInstance struct {
+	gateway  *Gateway
+	handler  *streamableHandler
+	lastUsed time.Time
+	// held counts the long-lived connections, like WebSockets, using the instance.
+	held int
+}
+
+// serverInstances are the MCP servers of the central mode, one per selection of servers.
+type serverInstances struct {
+	mu        sync.Mutex
+	instances map[string]*serverInstance
+	// pending are the instances being created.
+	pending map[string]*pendingInstance
+}
+
+// pendingInstance is an instance being created. done is closed once it's created, or failed to.
+type pendingInstance struct {
+	done     chan struct{}
+	instance *serverInstance
+	err      error
+}
+
+func newServerInstances() *serverInstances {
+	return &serverInstances{
+		instances: map[string]*serverInstance{},
+		pending:   map[string]*pendingInstance{},
+	}
+}
+
+// selectionKey normalizes a selection of servers so that the same servers, in any order, share an instance.
+func selectionKey(serverNames []string) string {
+	names := slices.Clone(serverNames)
+	slices.Sort(names)
+	return strings.Join(slices.Compact(names), ",")
+}
+
+// Get returns the instance serving a selection of servers, creating it if needed. Instances are created
+// without holding the lock, so that creating one, which starts its servers, doesn't block the others.
+// Concurrent requests for the same selection wait for the same instance.
+func (s *serverInstances) Get(
+	key string,
+	create func() (*serverInstance, error),
+) (*serverInstance, error) {
+	s.mu.Lock()
+	if instance, found := s.instances[key]; found {
+		instance.lastUsed = time.Now()
+		s.mu.Unlock()
+		return instance, nil
+	}
+
+	pending, found := s.pending[key]
+	if found {
+		s.mu.Unlock()
+		<-pending.done
+	} else {
+		pending = &pendingInstance{done: make(chan struct{})}
+		s.pending[key] = pending
+		s.mu.Unlock()
+
+		pending.instance, pending.err = create()
+
+		s.mu.Lock()
+		delete(s.pending, key)
+		if pending.err == nil {
+			s.instances[key] = pending.instance
+		}
+		s.mu.Unlock()
+		close(pending.done)
+	}
+
+	if pending.err != nil {
+		return nil, pending.err
+	}
+
+	s.mu.Lock()
+	pending.instance.lastUsed = time.Now()
+	s.mu.Unlock()
+
+	return pending.instance, nil
+}
+
+// Hold keeps an instance from being evicted until the returned function is called.
+func (s *serverInstances) Hold(instance *serverInstance) func() {
+	s.mu.Lock()
+	instance.held++
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		instance.held--
+		instance.lastUsed = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// Find returns the gateway that owns an MCP server, if any.
+func (s *serverInstances) Find(server *mcp.Server) *Gateway {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, instance := range s.instances {
+		if instance.gateway.mcpServer == server {
+			return instance.gateway
+		}
+	}
+	return nil
+}
+
+// EvictIdle closes the instances that haven't been used since a given time, and aren't held.
+func (s *serverInstances) EvictIdle(before time.Time) []string {
+	s.mu.Lock()
+	var evicted []*serverInstance
+	var keys []string
+	for key, instance := range s.instances {
+		if instance.held == 0 && instance.lastUsed.Before(before) {
+			evicted = append(evicted, instance)
+			keys = append(keys, key)
+			delete(s.instances, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, instance := range evicted {
+		instance.close()
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Close closes all the instances.
+func (s *serverInstances) Close() {
+	s.mu.Lock()
+	instances := s.instances
+	s.instances = map[string]*serverInstance{}
+	s.mu.Unlock()
+
+	for _, instance := range instances {
+		instance.close()
+	}
+}
+
+func (i *serverInstance) close() {
+	if i.gateway == nil {
+		return
+	}
+	if i.gateway.mcpServer != nil {
+		for ss := range i.gateway.mcpServer.Sessions() {
+			_ = ss.Close()
+		}
+	}
+	i.gateway.subscriptions.Close()
+}
+
+// newInstance creates an isolated gateway, sharing the configuration and the clients of this gateway,
+// that only exposes a selection of servers.
+func (g *Gateway) newInstance(
+	ctx context.Context,
+	configuration Configuration,
+	serverNames []string,
+) (*serverInstance, error) {
+	configuration.serverNames = serverNames
+
+	instance := &Gateway{
+		Options:       g.Options,
+		docker:        g.docker,
+		configurator:  g.configurator,
+		configuration: configuration,
+		clientPool:    g.clientPool,
+		sharedState:   g.sharedState,
+		resultStore:   g.resultStore,
+		middlewares:   g.middlewares,
+		sessionCache:  make(map[*mcp.ServerSession]*ServerSessionCache),
+		subscriptions: newSubscriptions(),
+	}
+	instance.mcpServer = instance.newMCPServer()
+
+	if err := instance.reloadConfiguration(ctx, configuration, serverNames, nil); err != nil {
+		return nil, err
+	}
+
+	return &serverInstance{
+		gateway: instance,
+		handler: newStreamableHandler(func(_ *http.Request) *mcp.Server {
+			return instance.mcpServer
+		}, g.eventStore, g.sharedState),
+	}, nil
+}
+
+// evictIdleInstances regularly closes the instances that haven't received any request for a while.
+func (g *Gateway) evictIdleInstances(ctx context.Context) {
+	interval := min(g.SessionIdleTimeout, time.Minute)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, key := range g.instances.EvictIdle(now.Add(-g.SessionIdleTimeout)) {
+				log("- Evicted idle servers:", key)
+			}
+		}
+	}
+}
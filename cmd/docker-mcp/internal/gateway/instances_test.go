@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectionKey(t *testing.T) {
+	assert.Equal(t, "fetch,github", selectionKey([]string{"github", "fetch"}))
+	assert.Equal(t, "fetch,github", selectionKey([]string{"fetch", "github", "fetch"}))
+}
+
+func fakeInstance() *serverInstance {
+	return &serverInstance{
+		gateway: &Gateway{
+			mcpServer:     mcp.NewServer(&mcp.Implementation{Name: "test"}, nil),
+			subscriptions: newSubscriptions(),
+		},
+	}
+}
+
+func TestServerInstancesAreIsolatedPerSelection(t *testing.T) {
+	instances := newServerInstances()
+
+	created := 0
+	create := func() (*serverInstance, error) {
+		created++
+		return fakeInstance(), nil
+	}
+
+	a, err := instances.Get("fetch,github", create)
+	require.NoError(t, err)
+	again, err := instances.Get("fetch,github", create)
+	require.NoError(t, err)
+	b, err := instances.Get("fetch", create)
+	require.NoError(t, err)
+
+	assert.Same(t, a, again)
+	assert.NotSame(t, a, b)
+	assert.Equal(t, 2, created)
+
+	assert.Same(t, a.gateway, instances.Find(a.gateway.mcpServer))
+	assert.Nil(t, instances.Find(mcp.NewServer(&mcp.Implementation{Name: "other"}, nil)))
+
+	_, err = instances.Get("broken", func() (*serverInstance, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+}
+
+func TestServerInstancesEvictIdle(t *testing.T) {
+	instances := newServerInstances()
+
+	idle, err := instances.Get("fetch", func() (*serverInstance, error) { return fakeInstance(), nil })
+	require.NoError(t, err)
+	idle.lastUsed = time.Now().Add(-time.Hour)
+
+	_, err = instances.Get("github", func() (*serverInstance, error) { return fakeInstance(), nil })
+	require.NoError(t, err)
+
+	evicted := instances.EvictIdle(time.Now().Add(-30 * time.Minute))
+	assert.Equal(t, []string{"fetch"}, evicted)
+	assert.Nil(t, instances.Find(idle.gateway.mcpServer))
+}
+
+func TestServerInstancesAreCreatedWithoutBlockingOthers(t *testing.T) {
+	instances := newServerInstances()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	created := 0
+	slow := func() (*serverInstance, error) {
+		created++
+		close(started)
+		<-release
+		return fakeInstance(), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*serverInstance, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance, err := instances.Get("github", slow)
+			assert.NoError(t, err)
+			results[i] = instance
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+
+	// Another selection is served while github is starting.
+	_, err := instances.Get("fetch", func() (*serverInstance, error) { return fakeInstance(), nil })
+	require.NoError(t, err)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, created)
+	assert.Same(t, results[0], results[1])
+}
+
+type staticConfigurator struct {
+	configuration Configuration
+}
+
+func (c staticConfigurator) Read(context.Context) (Configuration, chan Configuration, func() error, error) {
+	return c.configuration, nil, func() error { return nil }, nil
+}
+
+func TestRefreshCapabilitiesOfAnInstance(t *testing.T) {
+	g := &Gateway{
+		configurator:  staticConfigurator{},
+		sessionCache:  make(map[*mcp.ServerSession]*ServerSessionCache),
+		subscriptions: newSubscriptions(),
+		instances:     newServerInstances(),
+	}
+	g.clientPool = newClientPool(g.Options, nil, g)
+
+	instance, err := g.instances.Get("fetch", func() (*serverInstance, error) {
+		return g.newInstance(t.Context(), Configuration{}, []string{"fetch"})
+	})
+	require.NoError(t, err)
+
+	// An upstream server of the instance notifies that its tools changed.
+	require.NoError(t, g.RefreshCapabilities(t.Context(), instance.gateway.mcpServer, nil))
+	assert.Equal(t, []string{"fetch"}, instance.gateway.configuration.ServerNames())
+}
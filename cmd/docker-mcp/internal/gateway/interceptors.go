@@ -0,0 +1,197 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/interceptors"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/pii"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/promptinjection"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/secretsscan"
+)
+
+// targetMiddleware tells the interceptors which server provides the tool, the resource or the
+// prompt a request is about, and the annotations of the tools called.
+func (g *Gateway) targetMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if serverName := g.requestServerName(method, req); serverName != "" {
+				ctx = interceptors.WithServerName(ctx, serverName)
+			}
+			if annotations := g.requestToolAnnotations(method, req); annotations != nil {
+				ctx = interceptors.WithToolAnnotations(ctx, annotations)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func (g *Gateway) requestServerName(method string, req mcp.Request) string {
+	switch params := req.GetParams().(type) {
+	case *mcp.CallToolParamsRaw:
+		if method == "tools/call" && params != nil {
+			g.ownersMu.RLock()
+			defer g.ownersMu.RUnlock()
+			return g.toolOwners[params.Name]
+		}
+	case *mcp.ReadResourceParams:
+		if method == "resources/read" && params != nil {
+			if serverConfig := g.resourceOwner(params.URI); serverConfig != nil {
+				return serverConfig.Name
+			}
+		}
+	case *mcp.GetPromptParams:
+		if method == "prompts/get" && params != nil {
+			g.ownersMu.RLock()
+			defer g.ownersMu.RUnlock()
+			if serverConfig := g.promptOwners[params.Name]; serverConfig != nil {
+				return serverConfig.Name
+			}
+		}
+	}
+	return ""
+}
+
+func (g *Gateway) requestToolAnnotations(method string, req mcp.Request) *mcp.ToolAnnotations {
+	params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+	if method != "tools/call" || !ok || params == nil {
+		return nil
+	}
+
+	g.ownersMu.RLock()
+	defer g.ownersMu.RUnlock()
+	return g.toolAnnotations[params.Name]
+}
+
+// secretsOptions configures how the secrets sent to and returned by the tools are handled, or
+// returns nil if they're not blocked.
+func (g *Gateway) secretsOptions() (*interceptors.SecretsOptions, error) {
+	if !g.BlockSecrets {
+		return nil, nil
+	}
+
+	options := &interceptors.SecretsOptions{}
+	switch g.SecretScanMode {
+	case "", "block":
+	case "redact":
+		options.Redact = true
+	default:
+		return nil, fmt.Errorf("invalid secret scan mode %q, expected block or redact", g.SecretScanMode)
+	}
+
+	if g.SecretScanRules != "" || g.SecretScanEntropy > 0 {
+		scanner, err := secretsscan.New(secretsscan.Options{
+			RulesFile: g.SecretScanRules,
+			Entropy:   g.SecretScanEntropy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading secret scan rules: %w", err)
+		}
+		options.Scanner = scanner
+	}
+
+	for _, allow := range g.SecretScanAllow {
+		server, rule, found := strings.Cut(allow, "=")
+		if !found || server == "" || rule == "" {
+			return nil, fmt.Errorf("invalid secret scan allow %q, expected server=rule", allow)
+		}
+		if options.Allowed == nil {
+			options.Allowed = map[string][]string{}
+		}
+		options.Allowed[server] = append(options.Allowed[server], rule)
+	}
+
+	if options.Redact {
+		log("- Redacting secrets sent to and received from the tools")
+	}
+	return options, nil
+}
+
+// piiOptions configures how the personal data sent to and returned by the servers is handled, or
+// returns nil if there are neither policies nor an audit log. closeAudit closes the audit log.
+func (g *Gateway) piiOptions() (options *interceptors.PIIOptions, closeAudit func(), err error) {
+	closeAudit = func() {}
+	if len(g.PIIPolicies) == 0 && g.PIIAuditLog == "" {
+		return nil, closeAudit, nil
+	}
+
+	options = &interceptors.PIIOptions{}
+	for _, spec := range g.PIIPolicies {
+		policy, err := pii.ParsePolicy(spec)
+		if err != nil {
+			return nil, closeAudit, err
+		}
+		options.Policies = append(options.Policies, policy)
+	}
+
+	options.Scanner, err = pii.NewScanner(g.PIILocales)
+	if err != nil {
+		return nil, closeAudit, err
+	}
+
+	if g.PIIAuditLog != "" {
+		file, err := os.OpenFile(g.PIIAuditLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, closeAudit, fmt.Errorf("opening pii audit log: %w", err)
+		}
+		options.Audit = file
+		closeAudit = func() { _ = file.Close() }
+	}
+
+	log("- Applying", len(options.Policies), "personal data policies")
+	return options, closeAudit, nil
+}
+
+// promptInjectionOptions configures how the content returned by the servers is inspected for
+// prompt injections, or returns nil if it isn't.
+func (g *Gateway) promptInjectionOptions() (*interceptors.PromptInjectionOptions, error) {
+	if len(g.PromptInjection) == 0 {
+		return nil, nil
+	}
+
+	options := &interceptors.PromptInjectionOptions{}
+	for _, spec := range g.PromptInjection {
+		policy, err := promptinjection.ParsePolicy(spec)
+		if err != nil {
+			return nil, err
+		}
+		options.Policies = append(options.Policies, policy)
+	}
+
+	log("- Inspecting the content returned by the servers for prompt injections")
+	return options, nil
+}
+
+// resultSizeOptions configures how the tool results larger than the limits are handled, or returns
+// nil if there are no limits. The results that spill over are kept in g.resultStore.
+func (g *Gateway) resultSizeOptions() (*interceptors.ResultSizeOptions, error) {
+	if len(g.ResultLimits) == 0 {
+		return nil, nil
+	}
+
+	options := &interceptors.ResultSizeOptions{}
+	for _, spec := range g.ResultLimits {
+		limit, err := interceptors.ParseResultLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		options.Limits = append(options.Limits, limit)
+
+		if limit.Spill && g.resultStore == nil {
+			g.resultStore = resultstore.New(resultstore.Options{
+				Retention:       g.ResultRetention,
+				MaxBytes:        g.ResultStoreMaxBytes,
+				SessionMaxBytes: g.ResultSessionMaxBytes,
+			})
+		}
+	}
+	options.Store = g.resultStore
+
+	log("- Limiting the size of the tool results")
+	return options, nil
+}
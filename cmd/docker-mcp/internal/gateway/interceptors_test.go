@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/interceptors"
+)
+
+func TestTargetMiddleware(t *testing.T) {
+	g := &Gateway{
+		toolOwners:             map[string]string{"get_issue": "github"},
+		toolAnnotations:        map[string]*mcp.ToolAnnotations{"get_issue": {ReadOnlyHint: true}},
+		promptOwners:           map[string]*catalog.ServerConfig{"summarize": {Name: "notes"}},
+		resourceTemplateOwners: map[string]*catalog.ServerConfig{"file:///{path}": {Name: "files"}},
+	}
+
+	var (
+		serverName  string
+		annotations *mcp.ToolAnnotations
+	)
+	handler := g.targetMiddleware()(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		serverName = interceptors.ServerName(ctx)
+		annotations = interceptors.ToolAnnotations(ctx)
+		return nil, nil
+	})
+	call := func(method string, req mcp.Request) string {
+		_, err := handler(t.Context(), method, req)
+		require.NoError(t, err)
+		return serverName
+	}
+
+	assert.Equal(t, "github", call("tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_issue"}}))
+	require.NotNil(t, annotations)
+	assert.True(t, annotations.ReadOnlyHint)
+	assert.Equal(t, "notes", call("prompts/get", &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Name: "summarize"}}))
+	assert.Equal(t, "files", call("resources/read", &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "file:///readme"}}))
+	assert.Empty(t, call("tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "unknown"}}))
+}
+
+func TestTargetMiddlewareInCentralMode(t *testing.T) {
+	var serverName string
+	g := &Gateway{
+		configurator:  staticConfigurator{},
+		sessionCache:  make(map[*mcp.ServerSession]*ServerSessionCache),
+		subscriptions: newSubscriptions(),
+		instances:     newServerInstances(),
+		middlewares: []mcp.Middleware{func(next mcp.MethodHandler) mcp.MethodHandler {
+			return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				if method == "tools/call" {
+					serverName = interceptors.ServerName(ctx)
+				}
+				return next(ctx, method, req)
+			}
+		}},
+	}
+	g.clientPool = newClientPool(g.Options, nil, g)
+	g.mcpServer = g.newMCPServer()
+
+	// The parent gateway has no servers, the instance knows which server owns the tool.
+	instance, err := g.newInstance(t.Context(), Configuration{}, []string{"github"})
+	require.NoError(t, err)
+	instance.gateway.toolOwners = map[string]string{"get_issue": "github"}
+	instance.gateway.mcpServer.AddTool(
+		&mcp.Tool{Name: "get_issue", InputSchema: &jsonschema.Schema{Type: "object"}},
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		},
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = instance.gateway.mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	_, err = session.CallTool(t.Context(), &mcp.CallToolParams{Name: "get_issue"})
+	require.NoError(t, err)
+	assert.Equal(t, "github", serverName)
+}
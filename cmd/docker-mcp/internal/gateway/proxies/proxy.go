@@ -26,6 +26,11 @@ type TargetConfig struct {
 	DNS         string
 }
 
+// Images returns the images used to proxy the traffic of the servers, for example to ship them to air-gapped hosts.
+func Images() []string {
+	return []string{dnsImage, l4Image, l7Image}
+}
+
 // RunNetworkProxies starts a set of Proxy and returns a TargetConfig that
 // should be applied to a target container to get all its traffic proxied, a
 // cleanup function to remove the network and proxies, and an error if any.
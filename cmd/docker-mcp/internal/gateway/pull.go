@@ -6,10 +6,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/mirror"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/signatures"
 )
 
 func (g *Gateway) pullAndVerify(ctx context.Context, configuration Configuration) error {
+	// Replayed servers don't run.
+	if g.replayer != nil {
+		return nil
+	}
+
 	dockerImages := configuration.DockerImages()
 	if len(dockerImages) == 0 {
 		return nil
@@ -17,11 +23,12 @@ func (g *Gateway) pullAndVerify(ctx context.Context, configuration Configuration
 
 	log("- Using images:")
 
+	// Mirrored images are verified against the signatures of the images they are copies of.
 	var verifiableImages []string
 	for _, image := range dockerImages {
 		log("  - " + image)
-		if strings.HasPrefix(image, "mcp/") {
-			verifiableImages = append(verifiableImages, image)
+		if original := g.RegistryMirrors.Original(image); strings.HasPrefix(original, "mcp/") {
+			verifiableImages = append(verifiableImages, original)
 		}
 	}
 
@@ -55,7 +62,8 @@ func (g *Gateway) verifyImages(ctx context.Context, images []string) error {
 	start := time.Now()
 	log("- Verifying images", imageBaseNames(images))
 
-	if err := signatures.Verify(ctx, images); err != nil {
+	signaturesRepository := g.RegistryMirrors.Rewrite(mirror.SignaturesRepository)
+	if err := signatures.Verify(ctx, images, signaturesRepository); err != nil {
 		return fmt.Errorf("verifying docker images: %w", err)
 	}
 
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
+)
+
+// configureRecording records the exchanges with the servers, or replays recorded exchanges instead
+// of running the servers. It returns a function that stops recording.
+func (g *Gateway) configureRecording() (func(), error) {
+	stop := func() {}
+
+	if g.Record != "" && g.Replay != "" {
+		return stop, errors.New("--record and --replay can't be used together")
+	}
+
+	if g.Record != "" {
+		recorder, err := recording.NewRecorder(g.Record)
+		if err != nil {
+			return stop, fmt.Errorf("recording: %w", err)
+		}
+		g.recorder = recorder
+		log("- Recording the exchanges with the servers in", g.Record)
+
+		stop = func() {
+			if err := recorder.Close(); err != nil {
+				logf("! Can't record the exchanges with the servers: %s", err)
+			}
+		}
+	}
+
+	if g.Replay != "" {
+		options := recording.ReplayOptions{Ignore: g.ReplayIgnore}
+		switch g.ReplayMatch {
+		case "", "exact":
+		case "fuzzy":
+			options.Fuzzy = true
+		default:
+			return stop, fmt.Errorf("unknown replay match %q, expected 'exact' or 'fuzzy'", g.ReplayMatch)
+		}
+
+		replayer, err := recording.NewReplayer(g.Replay, options)
+		if err != nil {
+			return stop, fmt.Errorf("replaying: %w", err)
+		}
+		g.replayer = replayer
+		log("- Replaying the exchanges with the servers recorded in", g.Replay)
+	}
+
+	return stop, nil
+}
+
+// WrapTransport records the exchanges with a server, when recording.
+func (g *Gateway) WrapTransport(serverName string, transport mcp.Transport) mcp.Transport {
+	if g == nil || g.recorder == nil {
+		return transport
+	}
+	return g.recorder.Transport(serverName, transport)
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+)
+
+// resultsTemplate lets the clients read, in pages, the tool results that were too large for them.
+var resultsTemplate = &mcp.ResourceTemplate{
+	URITemplate: resultstore.URITemplate,
+	Name:        "gateway-results",
+	Title:       "Large tool results",
+	Description: "Tool results larger than the limits, stored by the gateway. Pages start at 1.",
+	MIMEType:    "text/plain",
+}
+
+func (g *Gateway) readResult(
+	_ context.Context,
+	req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id, page, err := resultstore.ParseURI(uri)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	// The results can only be read by the session that got them.
+	var sessionID string
+	if req.Session != nil {
+		sessionID = req.Session.ID()
+	}
+	text, pages, err := g.resultStore.Page(sessionID, id, page)
+	if errors.Is(err, resultstore.ErrNotFound) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	meta := mcp.Meta{"page": page, "pages": pages}
+	if page < pages {
+		meta["nextPage"] = resultstore.URI(id, page+1)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: "text/plain",
+			Text:     text,
+			Meta:     meta,
+		}},
+	}, nil
+}
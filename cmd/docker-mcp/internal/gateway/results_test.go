@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+)
+
+func TestReadResult(t *testing.T) {
+	g := &Gateway{resultStore: resultstore.New(resultstore.Options{})}
+	id, pages, err := g.resultStore.Put("", "first\nsecond\n", 7)
+	require.NoError(t, err)
+	require.Equal(t, 2, pages)
+
+	read := func(uri string) (*mcp.ReadResourceResult, error) {
+		return g.readResult(t.Context(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	}
+
+	result, err := read(resultstore.URI(id, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", result.Contents[0].Text)
+	assert.Equal(t, mcp.Meta{"page": 1, "pages": 2, "nextPage": resultstore.URI(id, 2)}, result.Contents[0].Meta)
+
+	result, err = read(resultstore.URI(id, 2))
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", result.Contents[0].Text)
+	assert.NotContains(t, result.Contents[0].Meta, "nextPage")
+
+	_, err = read(resultstore.URI(id, 3))
+	require.ErrorContains(t, err, "out of range")
+
+	_, err = read(resultstore.URI("unknown", 1))
+	require.Error(t, err)
+	assert.Equal(t, mcp.ResourceNotFoundError(resultstore.URI("unknown", 1)).Error(), err.Error())
+}
+
+func TestReadResultFromAnInstance(t *testing.T) {
+	g := &Gateway{
+		configurator:  staticConfigurator{},
+		resultStore:   resultstore.New(resultstore.Options{}),
+		sessionCache:  make(map[*mcp.ServerSession]*ServerSessionCache),
+		subscriptions: newSubscriptions(),
+		instances:     newServerInstances(),
+	}
+	g.clientPool = newClientPool(g.Options, nil, g)
+	id, _, err := g.resultStore.Put("", "result", 100)
+	require.NoError(t, err)
+
+	instance, err := g.newInstance(t.Context(), Configuration{}, []string{"github"})
+	require.NoError(t, err)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = instance.gateway.mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	result, err := session.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: resultstore.URI(id, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, "result", result.Contents[0].Text)
+}
@@ -12,9 +12,15 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/otel"
 
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/docker"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/eventstore"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/health"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/interceptors"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/sharedstate"
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
 )
 
@@ -22,28 +28,25 @@ type ServerSessionCache struct {
 	Roots []*mcp.Root
 }
 
-// type SubsAction int
-
-// const (
-// subscribe   SubsAction = 0
-// unsubscribe SubsAction = 1
-// )
-
-// type SubsMessage struct {
-// uri    string
-// action SubsAction
-// ss     *mcp.ServerSession
-// }
-
 type Gateway struct {
 	Options
 	docker        docker.Client
+	secretsPath   string
+	authenticator auth.Chain
+	tlsEnabled    bool
+	eventStore    *eventstore.Store
+	resultStore   *resultstore.Store
+	recorder      *recording.Recorder
+	replayer      *recording.Replayer
+	sharedState   *sharedstate.State
 	configurator  Configurator
 	configuration Configuration
 	clientPool    *clientPool
 	mcpServer     *mcp.Server
 	health        health.State
-	// subsChannel  chan SubsMessage
+	subscriptions *subscriptions
+	middlewares   []mcp.Middleware
+	instances     *serverInstances
 
 	sessionCacheMu sync.RWMutex
 	sessionCache   map[*mcp.ServerSession]*ServerSessionCache
@@ -54,14 +57,23 @@ type Gateway struct {
 	registeredResourceURIs         []string
 	registeredResourceTemplateURIs []string
 
+	// Servers owning the registered prompts and resources, used to route completions and subscriptions
+	ownersMu               sync.RWMutex
+	toolOwners             map[string]string
+	toolAnnotations        map[string]*mcp.ToolAnnotations
+	promptOwners           map[string]*catalog.ServerConfig
+	resourceOwners         map[string]*catalog.ServerConfig
+	resourceTemplateOwners map[string]*catalog.ServerConfig
+
 	// Transport abstraction for channel separation
 	transport MCPTransport
 }
 
 func NewGateway(config Config, docker docker.Client) *Gateway {
 	g := &Gateway{
-		Options: config.Options,
-		docker:  docker,
+		Options:     config.Options,
+		docker:      docker,
+		secretsPath: config.SecretsPath,
 		configurator: &FileBasedConfiguration{
 			ServerNames:        config.ServerNames,
 			CatalogPath:        config.CatalogPath,
@@ -71,11 +83,14 @@ func NewGateway(config Config, docker docker.Client) *Gateway {
 			ToolsPath:          config.ToolsPath,
 			OciRef:             config.OciRef,
 			MCPRegistryServers: config.MCPRegistryServers,
+			RegistryMirrors:    config.RegistryMirrors,
 			Watch:              config.Watch,
 			Central:            config.Central,
 			docker:             docker,
 		},
-		sessionCache: make(map[*mcp.ServerSession]*ServerSessionCache),
+		sessionCache:  make(map[*mcp.ServerSession]*ServerSessionCache),
+		subscriptions: newSubscriptions(),
+		instances:     newServerInstances(),
 	}
 	g.clientPool = newClientPool(config.Options, docker, g)
 	return g
@@ -97,6 +112,26 @@ func (g *Gateway) Run(ctx context.Context) error {
 			return fmt.Errorf("failed to listen on port %d: %w", g.Port, err)
 		}
 		defer listener.Close()
+
+		listener, err = g.configureAuth(ctx, listener)
+		if err != nil {
+			return err
+		}
+	}
+
+	if isStreamableTransport(g.Transport) {
+		if err := g.configureEventStore(); err != nil {
+			return fmt.Errorf("configuring event store: %w", err)
+		}
+		defer g.eventStore.Close()
+	}
+
+	if g.SharedState != "" {
+		closeSharedState, err := g.configureSharedState(ctx)
+		if err != nil {
+			return fmt.Errorf("configuring shared state: %w", err)
+		}
+		defer closeSharedState()
 	}
 
 	factory := &TransportFactory{}
@@ -127,6 +162,8 @@ func (g *Gateway) Run(ctx context.Context) error {
 	}
 
 	defer g.clientPool.Close()
+	defer g.subscriptions.Close()
+	defer g.instances.Close()
 	defer func() {
 		// Clean up all session cache entries
 		g.sessionCacheMu.Lock()
@@ -154,49 +191,55 @@ func (g *Gateway) Run(ctx context.Context) error {
 		}
 		log("- Interceptors enabled:", strings.Join(g.Interceptors, ", "))
 	}
+	if g.InterceptorsFile != "" {
+		fileInterceptors, err := interceptors.ReadFile(g.InterceptorsFile)
+		if err != nil {
+			return fmt.Errorf("reading interceptors: %w", err)
+		}
+		parsedInterceptors = append(parsedInterceptors, fileInterceptors...)
+		log("- Interceptors enabled from", g.InterceptorsFile+":", len(fileInterceptors))
+	}
 
-	g.mcpServer = mcp.NewServer(&mcp.Implementation{
-		Name:    "Docker AI MCP Gateway",
-		Version: "2.0.1",
-	}, &mcp.ServerOptions{
-		SubscribeHandler: func(_ context.Context, req *mcp.SubscribeRequest) error {
-			log("- Client subscribed to URI:", req.Params.URI)
-			// The MCP SDK doesn't provide ServerSession in SubscribeHandler because it already
-			// keeps track of the mapping between ServerSession and subscribed resources in the Server
-			// g.subsChannel <- SubsMessage{uri: req.Params.URI, action: subscribe , ss: ss}
-			return nil
-		},
-		UnsubscribeHandler: func(_ context.Context, req *mcp.UnsubscribeRequest) error {
-			log("- Client unsubscribed from URI:", req.Params.URI)
-			// The MCP SDK doesn't provide ServerSession in UnsubscribeHandler because it already
-			// keeps track of the mapping ServerSession and subscribed resources in the Server
-			// g.subsChannel <- SubsMessage{uri: req.Params.URI, action: unsubscribe , ss: ss}
-			return nil
-		},
-		RootsListChangedHandler: func(ctx context.Context, req *mcp.RootsListChangedRequest) {
-			log("- Client roots list changed")
-			// We can't get the ServerSession from the request anymore, so we'll need to handle this differently
-			_, _ = req.Session.ListRoots(ctx, &mcp.ListRootsParams{})
-		},
-		CompletionHandler: nil,
-		InitializedHandler: func(_ context.Context, _ *mcp.InitializedRequest) {
-			log("- Client initialized")
-		},
-		HasPrompts:   true,
-		HasResources: true,
-		HasTools:     true,
-	})
+	// Start the long-running interceptors
+	plugins, err := interceptors.StartPlugins(ctx, parsedInterceptors)
+	if err != nil {
+		return err
+	}
+	defer plugins.Close()
 
-	// Add interceptor middleware to the server (includes telemetry)
-	middlewares := interceptors.Callbacks(
-		g.LogCalls,
-		g.BlockSecrets,
-		g.OAuthInterceptorEnabled,
-		parsedInterceptors,
-	)
-	if len(middlewares) > 0 {
-		g.mcpServer.AddReceivingMiddleware(middlewares...)
+	secrets, err := g.secretsOptions()
+	if err != nil {
+		return err
+	}
+	personalData, closeAuditLog, err := g.piiOptions()
+	if err != nil {
+		return err
 	}
+	defer closeAuditLog()
+	promptInjection, err := g.promptInjectionOptions()
+	if err != nil {
+		return err
+	}
+	resultSize, err := g.resultSizeOptions()
+	if err != nil {
+		return err
+	}
+	closeRecorder, err := g.configureRecording()
+	if err != nil {
+		return err
+	}
+	defer closeRecorder()
+
+	g.middlewares = interceptors.Callbacks(interceptors.CallbackOptions{
+		LogCalls:                g.LogCalls,
+		Secrets:                 secrets,
+		PersonalData:            personalData,
+		PromptInjection:         promptInjection,
+		ResultSize:              resultSize,
+		OAuthInterceptorEnabled: g.OAuthInterceptorEnabled,
+		Interceptors:            parsedInterceptors,
+	})
+	g.mcpServer = g.newMCPServer()
 
 	// Which docker images are used?
 	// Pull them and verify them if possible.
@@ -227,6 +270,11 @@ func (g *Gateway) Run(ctx context.Context) error {
 			return nil
 		}
 
+		if isWebSocketTransport(g.Transport) {
+			log("> Start websocket server on port", g.Port)
+			return g.startCentralWebSocketServer(ctx, listener, configuration)
+		}
+
 		log("> Start streaming server on port", g.Port)
 		return g.startCentralStreamingServer(ctx, listener, configuration)
 	}
@@ -278,9 +326,13 @@ func (g *Gateway) Run(ctx context.Context) error {
 		log("> Start streaming server on port", g.Port)
 		return g.startStreamingServer(ctx, listener)
 
+	case "websocket", "ws":
+		log("> Start websocket server on port", g.Port)
+		return g.startWebSocketServer(ctx, listener)
+
 	default:
 		return fmt.Errorf(
-			"unknown transport %q, expected 'stdio', 'sse' or 'streaming",
+			"unknown transport %q, expected 'stdio', 'sse', 'streaming' or 'websocket'",
 			g.Transport,
 		)
 	}
@@ -337,23 +389,32 @@ func (g *Gateway) reloadConfiguration(
 	g.registeredPromptNames = nil
 	g.registeredResourceURIs = nil
 	g.registeredResourceTemplateURIs = nil
+	toolOwners := map[string]string{}
+	toolAnnotations := map[string]*mcp.ToolAnnotations{}
+	promptOwners := map[string]*catalog.ServerConfig{}
+	resourceOwners := map[string]*catalog.ServerConfig{}
+	resourceTemplateOwners := map[string]*catalog.ServerConfig{}
 
 	// Add new capabilities and track them
 	for _, tool := range capabilities.Tools {
 		g.mcpServer.AddTool(tool.Tool, tool.Handler)
 		g.registeredToolNames = append(g.registeredToolNames, tool.Tool.Name)
+		toolOwners[tool.Tool.Name] = tool.ServerName
+		toolAnnotations[tool.Tool.Name] = tool.Tool.Annotations
 	}
 
 	// Prompts are handled directly with AddPrompt in SDK v0.5.0
 	for _, prompt := range capabilities.Prompts {
 		g.mcpServer.AddPrompt(prompt.Prompt, prompt.Handler)
 		g.registeredPromptNames = append(g.registeredPromptNames, prompt.Prompt.Name)
+		promptOwners[prompt.Prompt.Name] = prompt.ServerConfig
 	}
 
 	// Resources are handled directly
 	for _, resource := range capabilities.Resources {
 		g.mcpServer.AddResource(resource.Resource, resource.Handler)
 		g.registeredResourceURIs = append(g.registeredResourceURIs, resource.Resource.URI)
+		resourceOwners[resource.Resource.URI] = resource.ServerConfig
 	}
 
 	// Resource templates - use the original URI template directly
@@ -371,8 +432,17 @@ func (g *Gateway) reloadConfiguration(
 			g.registeredResourceTemplateURIs,
 			resource.URITemplate,
 		)
+		resourceTemplateOwners[resource.URITemplate] = template.ServerConfig
 	}
 
+	g.ownersMu.Lock()
+	g.toolOwners = toolOwners
+	g.toolAnnotations = toolAnnotations
+	g.promptOwners = promptOwners
+	g.resourceOwners = resourceOwners
+	g.resourceTemplateOwners = resourceTemplateOwners
+	g.ownersMu.Unlock()
+
 	g.health.SetHealthy()
 	// Always log success status to stderr (log function outputs to stderr)
 	log("Successfully reloaded configuration with", len(capabilities.Tools), "tools,",
@@ -381,12 +451,62 @@ func (g *Gateway) reloadConfiguration(
 	return nil
 }
 
+// newMCPServer creates the MCP server exposed to the clients, with the gateway's handlers and middlewares.
+func (g *Gateway) newMCPServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    "Docker AI MCP Gateway",
+		Version: "2.0.1",
+	}, &mcp.ServerOptions{
+		SubscribeHandler:   g.subscribeHandler,
+		UnsubscribeHandler: g.unsubscribeHandler,
+		RootsListChangedHandler: func(ctx context.Context, req *mcp.RootsListChangedRequest) {
+			log("- Client roots list changed")
+			// We can't get the ServerSession from the request anymore, so we'll need to handle this differently
+			_, _ = req.Session.ListRoots(ctx, &mcp.ListRootsParams{})
+		},
+		CompletionHandler: g.completionHandler,
+		InitializedHandler: func(_ context.Context, _ *mcp.InitializedRequest) {
+			log("- Client initialized")
+		},
+		HasPrompts:   true,
+		HasResources: true,
+		HasTools:     true,
+	})
+
+	// Add interceptor middleware to the server (includes telemetry). The interceptors need to know
+	// which of this gateway's servers and tools each request is about.
+	server.AddReceivingMiddleware(append([]mcp.Middleware{g.targetMiddleware()}, g.middlewares...)...)
+
+	// The tool results that spill over are read from the server that returned the link to them.
+	if g.resultStore != nil {
+		server.AddResourceTemplate(resultsTemplate, g.readResult)
+	}
+
+	return server
+}
+
 // RefreshCapabilities implements the CapabilityRefresher interface
 // This method updates the server's capabilities by reloading the configuration
 func (g *Gateway) RefreshCapabilities(
 	ctx context.Context,
 	server *mcp.Server,
 	serverSession *mcp.ServerSession,
+) error {
+	// In central mode, refresh the instance that owns the server
+	if g.instances != nil {
+		if instance := g.instances.Find(server); instance != nil {
+			return instance.refreshCapabilities(ctx, server, serverSession)
+		}
+	}
+
+	return g.refreshCapabilities(ctx, server, serverSession)
+}
+
+// refreshCapabilities reloads the capabilities of this gateway's servers.
+func (g *Gateway) refreshCapabilities(
+	ctx context.Context,
+	server *mcp.Server,
+	serverSession *mcp.ServerSession,
 ) error {
 	// Get current configuration
 	configuration, _, _, err := g.configurator.Read(ctx)
@@ -460,6 +580,7 @@ func (g *Gateway) ListRoots(ctx context.Context, ss *mcp.ServerSession) {
 		cache.Roots = rootsResult.Roots
 	}
 	g.clientPool.UpdateRoots(ss, cache.Roots)
+	g.shareRoots(ctx, ss, cache.Roots)
 }
 
 // periodicMetricExport periodically exports metrics for long-running gateways
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+// samplingPolicy decides which sampling requests from the servers are forwarded to the client.
+type samplingPolicy struct {
+	disabled        bool
+	disabledServers []string
+	maxTokens       int64
+	allowedModels   []string
+}
+
+func (g *Gateway) samplingPolicy() samplingPolicy {
+	return samplingPolicy{
+		disabled:        g.DisableSampling,
+		disabledServers: g.SamplingDisabledServers,
+		maxTokens:       g.SamplingMaxTokens,
+		allowedModels:   g.SamplingAllowedModels,
+	}
+}
+
+// apply returns the parameters to forward to the client, or an error if the request is denied.
+// The parameters of the server are never modified.
+func (p samplingPolicy) apply(
+	serverName string,
+	params *mcp.CreateMessageParams,
+) (*mcp.CreateMessageParams, error) {
+	if p.disabled {
+		return nil, errors.New("sampling is disabled on the gateway")
+	}
+	if slices.Contains(p.disabledServers, serverName) {
+		return nil, fmt.Errorf("sampling is disabled for server %s", serverName)
+	}
+	var forwarded mcp.CreateMessageParams
+	if params != nil {
+		forwarded = *params
+	}
+	if p.maxTokens > 0 && (forwarded.MaxTokens <= 0 || forwarded.MaxTokens > p.maxTokens) {
+		forwarded.MaxTokens = p.maxTokens
+	}
+
+	if len(p.allowedModels) > 0 {
+		preferences := mcp.ModelPreferences{}
+		if forwarded.ModelPreferences != nil {
+			preferences = *forwarded.ModelPreferences
+		}
+
+		var hints []*mcp.ModelHint
+		for _, hint := range preferences.Hints {
+			if hint != nil && p.modelAllowed(hint.Name) {
+				hints = append(hints, hint)
+			}
+		}
+		if len(preferences.Hints) > 0 && len(hints) == 0 {
+			return nil, fmt.Errorf("none of the models requested by server %s is allowed", serverName)
+		}
+		if len(hints) == 0 {
+			// Steer the client towards the allowed models that are not patterns.
+			for _, model := range p.allowedModels {
+				if !strings.ContainsAny(model, "*?[") {
+					hints = append(hints, &mcp.ModelHint{Name: model})
+				}
+			}
+		}
+
+		preferences.Hints = hints
+		forwarded.ModelPreferences = &preferences
+	}
+
+	return &forwarded, nil
+}
+
+// modelAllowed matches a model hint against the allowed models, which can be glob patterns.
+func (p samplingPolicy) modelAllowed(model string) bool {
+	for _, allowed := range p.allowedModels {
+		if matched, err := path.Match(allowed, model); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateMessage forwards a sampling request from a server to the client session it is serving.
+func (g *Gateway) CreateMessage(
+	ctx context.Context,
+	serverName string,
+	serverSession *mcp.ServerSession,
+	params *mcp.CreateMessageParams,
+) (*mcp.CreateMessageResult, error) {
+	if serverSession == nil {
+		telemetry.RecordSampling(ctx, serverName, "error")
+		return nil, errors.New("create message handled without server session")
+	}
+
+	forwarded, err := g.samplingPolicy().apply(serverName, params)
+	if err != nil {
+		logf("> Sampling request from %s denied: %s", serverName, err)
+		telemetry.RecordSampling(ctx, serverName, "denied")
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := serverSession.CreateMessage(ctx, forwarded)
+	telemetry.RecordSamplingDuration(ctx, serverName, float64(time.Since(start).Milliseconds()))
+	if err != nil {
+		telemetry.RecordSampling(ctx, serverName, "error")
+		return nil, err
+	}
+
+	telemetry.RecordSampling(ctx, serverName, "forwarded")
+	return result, nil
+}
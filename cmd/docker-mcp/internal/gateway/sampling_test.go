@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingPolicyDisabled(t *testing.T) {
+	_, err := samplingPolicy{disabled: true}.apply("fetch", &mcp.CreateMessageParams{})
+	require.Error(t, err)
+
+	policy := samplingPolicy{disabledServers: []string{"fetch"}}
+	_, err = policy.apply("fetch", &mcp.CreateMessageParams{})
+	require.ErrorContains(t, err, "disabled for server fetch")
+
+	_, err = policy.apply("github", &mcp.CreateMessageParams{})
+	require.NoError(t, err)
+}
+
+func TestSamplingPolicyMaxTokens(t *testing.T) {
+	policy := samplingPolicy{maxTokens: 500}
+
+	params := &mcp.CreateMessageParams{MaxTokens: 2000}
+	forwarded, err := policy.apply("fetch", params)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), forwarded.MaxTokens)
+	assert.Equal(t, int64(2000), params.MaxTokens)
+
+	forwarded, err = policy.apply("fetch", &mcp.CreateMessageParams{MaxTokens: 100})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), forwarded.MaxTokens)
+
+	forwarded, err = samplingPolicy{}.apply("fetch", &mcp.CreateMessageParams{MaxTokens: 2000})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), forwarded.MaxTokens)
+}
+
+func TestSamplingPolicyAllowedModels(t *testing.T) {
+	policy := samplingPolicy{allowedModels: []string{"claude-*", "gpt-4o"}}
+
+	forwarded, err := policy.apply("fetch", &mcp.CreateMessageParams{
+		ModelPreferences: &mcp.ModelPreferences{
+			Hints: []*mcp.ModelHint{{Name: "gemini-pro"}, {Name: "claude-sonnet"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*mcp.ModelHint{{Name: "claude-sonnet"}}, forwarded.ModelPreferences.Hints)
+
+	_, err = policy.apply("fetch", &mcp.CreateMessageParams{
+		ModelPreferences: &mcp.ModelPreferences{Hints: []*mcp.ModelHint{{Name: "gemini-pro"}}},
+	})
+	require.ErrorContains(t, err, "none of the models")
+
+	forwarded, err = policy.apply("fetch", &mcp.CreateMessageParams{})
+	require.NoError(t, err)
+	assert.Equal(t, []*mcp.ModelHint{{Name: "gpt-4o"}}, forwarded.ModelPreferences.Hints)
+}
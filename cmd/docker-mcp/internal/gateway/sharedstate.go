@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/sharedstate"
+)
+
+const (
+	// replicaHeader and replicaCookie tell clients and load balancers which replica owns a session,
+	// so that they can route the next requests of the session to it.
+	replicaHeader = "Mcp-Gateway-Replica"
+	replicaCookie = "mcp_gateway_replica"
+	// forwardedByHeader marks the requests forwarded by another replica, which are never forwarded
+	// again.
+	forwardedByHeader = "Mcp-Gateway-Forwarded-By"
+)
+
+// configureSharedState connects to the state shared by the replicas of the gateway. The returned
+// function removes the records of this replica and disconnects.
+func (g *Gateway) configureSharedState(ctx context.Context) (func(), error) {
+	replica := sharedstate.Replica{ID: g.ReplicaID, URL: g.ReplicaURL}
+	if replica.ID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("no replica id and no hostname: %w", err)
+		}
+		replica.ID = hostname
+	}
+	if replica.URL != "" {
+		if _, err := url.ParseRequestURI(replica.URL); err != nil {
+			return nil, fmt.Errorf("invalid replica url: %w", err)
+		}
+	}
+
+	redis, err := sharedstate.NewRedis(g.SharedState)
+	if err != nil {
+		return nil, err
+	}
+	state := sharedstate.New(redis, replica, sharedstate.DefaultTTL)
+	if err := state.Register(ctx); err != nil {
+		_ = redis.Close()
+		return nil, fmt.Errorf("registering replica %q: %w", replica.ID, err)
+	}
+	g.sharedState = state
+	go state.Run(ctx)
+
+	log("- Sharing the state of the sessions as replica", replica.ID)
+	if replica.URL == "" {
+		log("  - Without --replica-url, sessions owned by this replica can't be forwarded to it")
+	}
+
+	return func() {
+		_ = state.Close(context.WithoutCancel(ctx))
+		_ = redis.Close()
+	}, nil
+}
+
+// forward serves the request of a session owned by another replica, by forwarding it to that
+// replica, or by telling the client which replica to use. It returns false if the session is
+// unknown to all the replicas.
+func (h *streamableHandler) forward(w http.ResponseWriter, r *http.Request, sessionID string) bool {
+	if h.state == nil || r.Header.Get(forwardedByHeader) != "" {
+		return false
+	}
+
+	self := h.state.Replica()
+	session, found, err := h.state.Session(r.Context(), sessionID)
+	if err != nil {
+		log("! Looking up session in the shared state:", err)
+		return false
+	}
+	if !found || session.Replica == self.ID {
+		return false
+	}
+	owner, found, err := h.state.LookupReplica(r.Context(), session.Replica)
+	if err != nil || !found {
+		return false
+	}
+
+	if owner.URL == "" {
+		w.Header().Set(replicaHeader, owner.ID)
+		http.Error(w, "session is owned by replica "+owner.ID, http.StatusMisdirectedRequest)
+		return true
+	}
+	target, err := url.Parse(owner.URL)
+	if err != nil {
+		return false
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Set(forwardedByHeader, self.ID)
+		},
+		// Server-sent events must reach the client as soon as they're sent.
+		FlushInterval: -1,
+	}
+	proxy.ServeHTTP(w, r)
+	return true
+}
+
+// shareSession records a new session of this replica, and hints at it for the next requests.
+func (h *streamableHandler) shareSession(
+	ctx context.Context,
+	w http.ResponseWriter,
+	sessionID string,
+) {
+	if h.state == nil {
+		return
+	}
+
+	if err := h.state.PutSession(ctx, sharedstate.Session{ID: sessionID}); err != nil {
+		log("! Sharing session:", err)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     replicaCookie,
+		Value:    h.state.Replica().ID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// shareClientInfo adds the client, known once the session is initialized, to its shared metadata.
+func (h *streamableHandler) shareClientInfo(ctx context.Context, ss *mcp.ServerSession) {
+	if h.state == nil || ss.InitializeParams() == nil || ss.InitializeParams().ClientInfo == nil {
+		return
+	}
+
+	client := ss.InitializeParams().ClientInfo.Name
+	_ = h.state.UpdateSession(ctx, ss.ID(), func(s *sharedstate.Session) { s.Client = client })
+}
+
+// shareRoots adds the roots of a session to its shared metadata.
+func (g *Gateway) shareRoots(ctx context.Context, ss *mcp.ServerSession, roots []*mcp.Root) {
+	if g.sharedState == nil {
+		return
+	}
+
+	var uris []string
+	for _, root := range roots {
+		uris = append(uris, root.URI)
+	}
+	// Only the sessions of the streaming transport are shared.
+	_ = g.sharedState.UpdateSession(ctx, ss.ID(), func(s *sharedstate.Session) { s.Roots = uris })
+}
+
+// keepsServer tells whether this replica can keep a long-lived server. When replicas share their
+// state, the lease is on the server: the replica holding it keeps the server for all its sessions,
+// and the other replicas use the server short-lived. The lease is held until the last session of
+// this replica keeping the server releases it.
+func (cp *clientPool) keepsServer(ctx context.Context, serverName string) bool {
+	if cp.gateway == nil || cp.gateway.sharedState == nil {
+		return true
+	}
+	state := cp.gateway.sharedState
+
+	cp.leaseLock.Lock()
+	defer cp.leaseLock.Unlock()
+	if cp.leases == nil {
+		cp.leases = map[string]int{}
+	}
+
+	if cp.leases[serverName] == 0 {
+		owner, err := state.AcquireServer(ctx, serverName)
+		if err != nil {
+			// Better keep a server twice than not at all.
+			log("! Acquiring lease on server", serverName, ":", err)
+		} else if owner != state.Replica().ID {
+			log("- Server", serverName, "is kept by replica", owner, ", using it short-lived")
+			return false
+		}
+	}
+	cp.leases[serverName]++
+	return true
+}
+
+// releaseServer gives up the lease on a long-lived server, once no session of this replica keeps it.
+func (cp *clientPool) releaseServer(ctx context.Context, serverName string) {
+	if cp.gateway == nil || cp.gateway.sharedState == nil {
+		return
+	}
+
+	cp.leaseLock.Lock()
+	defer cp.leaseLock.Unlock()
+
+	if cp.leases[serverName] == 0 {
+		return
+	}
+	cp.leases[serverName]--
+	if cp.leases[serverName] > 0 {
+		return
+	}
+	delete(cp.leases, serverName)
+
+	if err := cp.gateway.sharedState.ReleaseServer(ctx, serverName); err != nil {
+		log("! Releasing lease on server", serverName, ":", err)
+	}
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/recording"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/sharedstate"
+)
+
+func newReplica(t *testing.T, shared *sharedstate.Memory, id string, withURL bool) *httptest.Server {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: id}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(
+		context.Context,
+		*mcp.CallToolRequest,
+		struct{},
+	) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: id}}}, nil, nil
+	})
+
+	handler := newStreamableHandler(func(*http.Request) *mcp.Server { return server }, nil, nil)
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	// The state is set once the URL of the replica is known, before any request.
+	replica := sharedstate.Replica{ID: id}
+	if withURL {
+		replica.URL = httpServer.URL
+	}
+	state := sharedstate.New(shared, replica, time.Minute)
+	handler.state = state
+	require.NoError(t, state.Register(t.Context()))
+	return httpServer
+}
+
+func TestSessionsLandOnAnyReplica(t *testing.T) {
+	shared := sharedstate.NewMemory()
+	first := newReplica(t, shared, "first", true)
+	second := newReplica(t, shared, "second", false)
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":` +
+		`{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	response, err := http.DefaultClient.Do(streamableRequest(t, http.MethodPost, first.URL, "", initialize))
+	require.NoError(t, err)
+	sessionID := response.Header.Get(sessionIDHeader)
+	assert.Equal(t, "first", response.Header.Get(replicaHeader))
+	require.NotEmpty(t, response.Cookies())
+	assert.Equal(t, replicaCookie, response.Cookies()[0].Name)
+	_, _ = firstEvent(t, response)
+
+	assert.Eventually(t, func() bool {
+		session, found, err := sharedstate.New(shared, sharedstate.Replica{}, 0).Session(t.Context(), sessionID)
+		return err == nil && found && session.Replica == "first" && session.Client == "test"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The second replica forwards the requests of the session to the first one.
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, second.URL, sessionID,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, second.URL, sessionID, call))
+	require.NoError(t, err)
+	assert.Equal(t, "first", response.Header.Get(replicaHeader))
+	_, data := firstEvent(t, response)
+	assert.Contains(t, data, `"text":"first"`)
+
+	// Sessions of replicas that can't be reached are hinted at.
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, second.URL, "", initialize))
+	require.NoError(t, err)
+	secondSessionID := response.Header.Get(sessionIDHeader)
+	_, _ = firstEvent(t, response)
+
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, first.URL, secondSessionID, call))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusMisdirectedRequest, response.StatusCode)
+	assert.Equal(t, "second", response.Header.Get(replicaHeader))
+
+	// Closed sessions are forgotten.
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodDelete, first.URL, sessionID, ""))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Eventually(t, func() bool {
+		_, found, err := sharedstate.New(shared, sharedstate.Replica{}, 0).Session(t.Context(), sessionID)
+		return err == nil && !found
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// replayingPool is the client pool of a replica that replays the recorded exchanges with the servers.
+func replayingPool(t *testing.T, shared *sharedstate.Memory, id string, recordings string) *clientPool {
+	t.Helper()
+
+	replayer, err := recording.NewReplayer(recordings, recording.ReplayOptions{})
+	require.NoError(t, err)
+	g := &Gateway{
+		sharedState: sharedstate.New(shared, sharedstate.Replica{ID: id}, time.Minute),
+		replayer:    replayer,
+	}
+	g.clientPool = newClientPool(Options{}, nil, g)
+	return g.clientPool
+}
+
+// serverSession opens a client session on a gateway.
+func serverSession(t *testing.T) *mcp.ServerSession {
+	t.Helper()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcp.NewServer(&mcp.Implementation{Name: "gateway"}, nil).Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+	return ss
+}
+
+func TestLongLivedServersAreKeptByOneReplica(t *testing.T) {
+	recordings := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(recordings, "server.jsonl"), []byte(
+		`{"method":"initialize","result":{"protocolVersion":"2025-06-18","capabilities":{},"serverInfo":{"name":"server","version":"1"}}}`+"\n",
+	), 0o644))
+
+	shared := sharedstate.NewMemory()
+	first := replayingPool(t, shared, "first", recordings)
+	second := replayingPool(t, shared, "second", recordings)
+	serverConfig := &catalog.ServerConfig{Name: "server", Spec: catalog.Server{LongLived: true}}
+	kept := func(pool *clientPool, ss *mcp.ServerSession) bool {
+		t.Helper()
+		client, err := pool.AcquireClient(t.Context(), serverConfig, &clientConfig{serverSession: ss})
+		require.NoError(t, err)
+		defer pool.ReleaseClient(client)
+
+		pool.clientLock.RLock()
+		defer pool.clientLock.RUnlock()
+		_, found := pool.keptClients[clientKey{serverName: "server", session: ss}]
+		return found
+	}
+
+	// The lease is on the server, whichever the session.
+	assert.True(t, kept(first, serverSession(t)))
+	assert.True(t, kept(first, serverSession(t)))
+	assert.False(t, kept(second, serverSession(t)))
+
+	// The lease is given up once the replica keeps no client of the server.
+	first.Close()
+	assert.True(t, kept(second, serverSession(t)))
+
+	// Without shared state, servers are always kept.
+	assert.True(t, (&clientPool{}).keepsServer(t.Context(), "server"))
+}
@@ -0,0 +1,241 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/eventstore"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/sharedstate"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+const (
+	sessionIDHeader       = "Mcp-Session-Id"
+	protocolVersionHeader = "Mcp-Protocol-Version"
+	lastEventIDHeader     = "Last-Event-ID"
+)
+
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// streamableHandler serves the streamable HTTP transport like mcp.StreamableHTTPHandler, but keeps
+// the events of all the sessions in a store of our choosing, so that clients can resume the
+// streams they lost with Last-Event-ID.
+type streamableHandler struct {
+	getServer  func(*http.Request) *mcp.Server
+	eventStore mcp.EventStore
+	// state, if not nil, shares the sessions with the other replicas of the gateway.
+	state *sharedstate.State
+
+	mu       sync.Mutex
+	sessions map[string]*streamableSession
+}
+
+type streamableSession struct {
+	transport *mcp.StreamableServerTransport
+	session   *mcp.ServerSession
+}
+
+func newStreamableHandler(
+	getServer func(*http.Request) *mcp.Server,
+	eventStore *eventstore.Store,
+	state *sharedstate.State,
+) *streamableHandler {
+	h := &streamableHandler{
+		getServer: getServer,
+		state:     state,
+		sessions:  map[string]*streamableSession{},
+	}
+	// Without a store, each session keeps its events in memory.
+	if eventStore != nil {
+		h.eventStore = eventStore
+	}
+	return h
+}
+
+func (h *streamableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !acceptsStreamable(r) {
+		http.Error(
+			w,
+			"Accept must contain 'text/event-stream', and 'application/json' for POST requests",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	sessionID := r.Header.Get(sessionIDHeader)
+	var session *streamableSession
+	if sessionID != "" {
+		h.mu.Lock()
+		session = h.sessions[sessionID]
+		h.mu.Unlock()
+		if session == nil {
+			if h.forward(w, r, sessionID) {
+				return
+			}
+			if r.Header.Get(lastEventIDHeader) != "" {
+				telemetry.RecordStreamResumption(r.Context(), "expired")
+			}
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodDelete:
+		if session == nil {
+			http.Error(w, "Bad Request: DELETE requires an Mcp-Session-Id header", http.StatusBadRequest)
+			return
+		}
+		h.mu.Lock()
+		delete(h.sessions, sessionID)
+		h.mu.Unlock()
+		_ = session.session.Close()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+		if session == nil {
+			http.Error(w, "GET requires an active session", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get(lastEventIDHeader) != "" {
+			r = r.WithContext(eventstore.WithReplay(r.Context()))
+		}
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(
+			w,
+			"Method Not Allowed: streamable MCP servers support GET, POST, and DELETE requests",
+			http.StatusMethodNotAllowed,
+		)
+		return
+	}
+
+	version := r.Header.Get(protocolVersionHeader)
+	if version != "" && !slices.Contains(supportedProtocolVersions, version) {
+		http.Error(w, fmt.Sprintf(
+			"Bad Request: Unsupported protocol version (supported versions: %s)",
+			strings.Join(supportedProtocolVersions, ","),
+		), http.StatusBadRequest)
+		return
+	}
+
+	if h.state != nil {
+		w.Header().Set(replicaHeader, h.state.Replica().ID)
+	}
+
+	var created *mcp.ServerSession
+	if session == nil {
+		server := h.getServer(r)
+		if server == nil {
+			http.Error(w, "no server available", http.StatusBadRequest)
+			return
+		}
+
+		transport := &mcp.StreamableServerTransport{
+			SessionID:  rand.Text(),
+			EventStore: h.eventStore,
+		}
+		// The context is detached by the SDK for the long-running session.
+		ss, err := server.Connect(r.Context(), transport, nil)
+		if err != nil {
+			http.Error(w, "failed connection", http.StatusInternalServerError)
+			return
+		}
+
+		session = &streamableSession{transport: transport, session: ss}
+		h.mu.Lock()
+		h.sessions[transport.SessionID] = session
+		h.mu.Unlock()
+		h.shareSession(r.Context(), w, transport.SessionID)
+		go func() {
+			_ = ss.Wait()
+			h.mu.Lock()
+			delete(h.sessions, transport.SessionID)
+			h.mu.Unlock()
+			if h.state != nil {
+				_ = h.state.DeleteSession(context.WithoutCancel(r.Context()), transport.SessionID)
+			}
+		}()
+		created = ss
+	}
+
+	session.transport.ServeHTTP(w, r)
+
+	if created != nil {
+		h.shareClientInfo(r.Context(), created)
+	}
+}
+
+func isStreamableTransport(transport string) bool {
+	switch strings.ToLower(transport) {
+	case "http", "streamable", "streaming", "streamable-http":
+		return true
+	default:
+		return false
+	}
+}
+
+func acceptsStreamable(r *http.Request) bool {
+	var jsonOK, streamOK bool
+	for accept := range strings.SplitSeq(strings.Join(r.Header.Values("Accept"), ","), ",") {
+		switch strings.TrimSpace(accept) {
+		case "application/json", "application/*":
+			jsonOK = true
+		case "text/event-stream", "text/*":
+			streamOK = true
+		case "*/*":
+			jsonOK = true
+			streamOK = true
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		return streamOK
+	case http.MethodPost:
+		return jsonOK && streamOK
+	default:
+		return true
+	}
+}
+
+// configureEventStore creates the store shared by the sessions of the streamable HTTP transport.
+func (g *Gateway) configureEventStore() error {
+	options := eventstore.Options{
+		Retention: g.EventRetention,
+		MaxBytes:  g.EventStoreMaxBytes,
+	}
+
+	switch g.EventStore {
+	case "", "memory":
+		g.eventStore = eventstore.NewMemory(options)
+	case "disk":
+		path := g.EventStorePath
+		if path == "" {
+			path = "events"
+		}
+		dir, err := config.FilePath(path)
+		if err != nil {
+			return err
+		}
+		store, err := eventstore.NewDisk(dir, options)
+		if err != nil {
+			return err
+		}
+		g.eventStore = store
+		log("- Storing the events of the streams in", dir)
+	default:
+		return fmt.Errorf("unknown event store %q, expected 'memory' or 'disk'", g.EventStore)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/eventstore"
+)
+
+func streamableRequest(t *testing.T, method, url, sessionID, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+	return req
+}
+
+// firstEvent reads the first server-sent event of a response, and closes it, like a dropped
+// connection.
+func firstEvent(t *testing.T, response *http.Response) (id, data string) {
+	t.Helper()
+	defer response.Body.Close()
+
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "id: "); ok {
+			id = value
+		}
+		if value, ok := strings.CutPrefix(line, "data: "); ok {
+			data = value
+		}
+		if line == "" && data != "" {
+			return id, data
+		}
+	}
+	require.Fail(t, "no event")
+	return "", ""
+}
+
+func TestStreamableHandlerResumesStreams(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "long"}, func(
+		ctx context.Context,
+		req *mcp.CallToolRequest,
+		_ struct{},
+	) (*mcp.CallToolResult, any, error) {
+		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: req.Params.GetProgressToken(),
+			Progress:      0.5,
+		})
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	handler := newStreamableHandler(
+		func(*http.Request) *mcp.Server { return server },
+		eventstore.NewMemory(eventstore.Options{}),
+		nil,
+	)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	url := httpServer.URL
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":` +
+		`{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	response, err := http.DefaultClient.Do(streamableRequest(t, http.MethodPost, url, "", initialize))
+	require.NoError(t, err)
+	sessionID := response.Header.Get(sessionIDHeader)
+	require.NotEmpty(t, sessionID)
+	_, _ = firstEvent(t, response)
+
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, url, sessionID,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+	response.Body.Close()
+
+	// The connection drops after the progress notification, before the result.
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":` +
+		`{"name":"long","arguments":{},"_meta":{"progressToken":"p"}}}`
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodPost, url, sessionID, call))
+	require.NoError(t, err)
+	eventID, data := firstEvent(t, response)
+	assert.Contains(t, data, "notifications/progress")
+
+	// The client resumes the stream, and gets the result without calling the tool again.
+	var replayed string
+	assert.Eventually(t, func() bool {
+		req := streamableRequest(t, http.MethodGet, url, sessionID, "")
+		req.Header.Set(lastEventIDHeader, eventID)
+		response, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		replayed = string(body)
+		return strings.Contains(replayed, `"id":2`)
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Contains(t, replayed, "done")
+	assert.NotContains(t, replayed, "notifications/progress")
+
+	// Unknown sessions can't be resumed.
+	req := streamableRequest(t, http.MethodGet, url, "unknown", "")
+	req.Header.Set(lastEventIDHeader, eventID)
+	response, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	// Deleted sessions are closed.
+	response, err = http.DefaultClient.Do(streamableRequest(t, http.MethodDelete, url, sessionID, ""))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	handler.mu.Lock()
+	assert.Empty(t, handler.sessions)
+	handler.mu.Unlock()
+}
+
+func TestIsStreamableTransport(t *testing.T) {
+	assert.True(t, isStreamableTransport("streaming"))
+	assert.True(t, isStreamableTransport("HTTP"))
+	assert.False(t, isStreamableTransport("sse"))
+	assert.False(t, isStreamableTransport("websocket"))
+}
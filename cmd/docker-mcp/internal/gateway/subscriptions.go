@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+)
+
+// upstreamKey identifies a subscription of the gateway to a resource of a server.
+type upstreamKey struct {
+	serverName string
+	uri        string
+}
+
+type upstreamSubscription struct {
+	refs        int
+	unsubscribe func()
+	// ready is closed once the gateway subscribed to the server, or failed to.
+	ready chan struct{}
+	err   error
+	// released is set when the last session is gone before the gateway subscribed to the server.
+	released bool
+}
+
+// subscriptions tracks which client sessions are subscribed to which resources. The gateway subscribes
+// only once to each resource of each server, whatever the number of subscribed client sessions.
+// Updates are fanned out to the subscribed sessions by mcp.Server.ResourceUpdated.
+type subscriptions struct {
+	mu        sync.Mutex
+	bySession map[*mcp.ServerSession]map[string]upstreamKey
+	upstream  map[upstreamKey]*upstreamSubscription
+	// watched are the sessions whose closing is watched, to remove their subscriptions.
+	watched map[*mcp.ServerSession]bool
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		bySession: map[*mcp.ServerSession]map[string]upstreamKey{},
+		upstream:  map[upstreamKey]*upstreamSubscription{},
+		watched:   map[*mcp.ServerSession]bool{},
+	}
+}
+
+// Add subscribes a session to a resource, calling subscribe if it's the first subscription to that
+// resource of that server. subscribe, which can start a server, is called without holding the lock:
+// the subscription is reserved first, then kept or rolled back. Add returns true the first time a
+// session subscribes, so that the caller watches for its closing.
+func (s *subscriptions) Add(
+	ss *mcp.ServerSession,
+	uri string,
+	serverName string,
+	subscribe func() (func(), error),
+) (bool, error) {
+	s.mu.Lock()
+	uris, sessionKnown := s.bySession[ss]
+	if _, subscribed := uris[uri]; subscribed {
+		s.mu.Unlock()
+		return false, nil
+	}
+
+	key := upstreamKey{serverName: serverName, uri: uri}
+	upstream, found := s.upstream[key]
+	if !found {
+		upstream = &upstreamSubscription{ready: make(chan struct{})}
+		s.upstream[key] = upstream
+	}
+	upstream.refs++
+
+	if !sessionKnown {
+		uris = map[string]upstreamKey{}
+		s.bySession[ss] = uris
+	}
+	uris[uri] = key
+	s.mu.Unlock()
+
+	if found {
+		<-upstream.ready
+	} else {
+		unsubscribe, err := subscribe()
+
+		s.mu.Lock()
+		upstream.unsubscribe, upstream.err = unsubscribe, err
+		if err != nil && s.upstream[key] == upstream {
+			delete(s.upstream, key)
+		}
+		released := upstream.released
+		s.mu.Unlock()
+		close(upstream.ready)
+
+		// Every session was gone before the gateway subscribed to the server.
+		if err == nil && released {
+			unsubscribe()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if upstream.err != nil {
+		if s.bySession[ss][uri] == key {
+			delete(s.bySession[ss], uri)
+			if len(s.bySession[ss]) == 0 {
+				delete(s.bySession, ss)
+			}
+		}
+		return false, upstream.err
+	}
+
+	if s.watched[ss] {
+		return false, nil
+	}
+	s.watched[ss] = true
+	return true, nil
+}
+
+// Remove unsubscribes a session from a resource, unsubscribing from the server if it was the last one.
+func (s *subscriptions) Remove(ss *mcp.ServerSession, uri string) {
+	s.mu.Lock()
+	key, subscribed := s.bySession[ss][uri]
+	if !subscribed {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.bySession[ss], uri)
+	if len(s.bySession[ss]) == 0 {
+		delete(s.bySession, ss)
+	}
+	unsubscribe := s.release(key)
+	s.mu.Unlock()
+
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+}
+
+// RemoveSession removes all the subscriptions of a session, usually because it was closed.
+func (s *subscriptions) RemoveSession(ss *mcp.ServerSession) {
+	s.mu.Lock()
+	var unsubscribes []func()
+	for _, key := range s.bySession[ss] {
+		if unsubscribe := s.release(key); unsubscribe != nil {
+			unsubscribes = append(unsubscribes, unsubscribe)
+		}
+	}
+	delete(s.bySession, ss)
+	delete(s.watched, ss)
+	s.mu.Unlock()
+
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+}
+
+// Close unsubscribes from all the servers.
+func (s *subscriptions) Close() {
+	s.mu.Lock()
+	upstream := s.upstream
+	s.upstream = map[upstreamKey]*upstreamSubscription{}
+	s.bySession = map[*mcp.ServerSession]map[string]upstreamKey{}
+	s.watched = map[*mcp.ServerSession]bool{}
+	var unsubscribes []func()
+	for _, subscription := range upstream {
+		if subscription.unsubscribe != nil {
+			unsubscribes = append(unsubscribes, subscription.unsubscribe)
+		} else {
+			subscription.released = true
+		}
+	}
+	s.mu.Unlock()
+
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+}
+
+// release decrements the references to an upstream subscription and returns the function
+// that unsubscribes from the server once there are none left. Must be called with the lock held.
+func (s *subscriptions) release(key upstreamKey) func() {
+	upstream, found := s.upstream[key]
+	if !found {
+		return nil
+	}
+	upstream.refs--
+	if upstream.refs > 0 {
+		return nil
+	}
+	delete(s.upstream, key)
+	if upstream.unsubscribe == nil {
+		// The gateway is still subscribing to the server, it unsubscribes once it's done.
+		upstream.released = true
+	}
+	return upstream.unsubscribe
+}
+
+func (g *Gateway) subscribeHandler(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	serverConfig := g.resourceOwner(uri)
+	if serverConfig == nil {
+		return fmt.Errorf("no server provides resource %s", uri)
+	}
+
+	first, err := g.subscriptions.Add(req.Session, uri, serverConfig.Name, func() (func(), error) {
+		return g.subscribeUpstream(ctx, serverConfig, uri)
+	})
+	if err != nil {
+		return err
+	}
+	log("- Client subscribed to URI:", uri)
+
+	// Forget the subscriptions of the session once it's closed.
+	if first && req.Session != nil {
+		go func() {
+			_ = req.Session.Wait()
+			g.subscriptions.RemoveSession(req.Session)
+		}()
+	}
+
+	return nil
+}
+
+func (g *Gateway) unsubscribeHandler(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	g.subscriptions.Remove(req.Session, req.Params.URI)
+	log("- Client unsubscribed from URI:", req.Params.URI)
+	return nil
+}
+
+// subscribeUpstream subscribes to a resource on a server, with a client dedicated to that subscription.
+// Its notifications are forwarded to the gateway's server, which sends them to the subscribed sessions.
+func (g *Gateway) subscribeUpstream(
+	ctx context.Context,
+	serverConfig *catalog.ServerConfig,
+	uri string,
+) (func(), error) {
+	// The client outlives the subscribe request.
+	client, err := g.clientPool.AcquireClient(
+		context.WithoutCancel(ctx),
+		serverConfig,
+		&clientConfig{server: g.mcpServer},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	initResult := client.Session().InitializeResult()
+	if initResult == nil || initResult.Capabilities == nil || initResult.Capabilities.Resources == nil ||
+		!initResult.Capabilities.Resources.Subscribe {
+		g.clientPool.ReleaseClient(client)
+		return nil, fmt.Errorf("server %s does not support resource subscriptions", serverConfig.Name)
+	}
+
+	if err := client.Session().Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		g.clientPool.ReleaseClient(client)
+		return nil, fmt.Errorf("subscribing to %s on server %s: %w", uri, serverConfig.Name, err)
+	}
+
+	return func() {
+		_ = client.Session().Unsubscribe(context.Background(), &mcp.UnsubscribeParams{URI: uri})
+		g.clientPool.ReleaseClient(client)
+	}, nil
+}
+
+// resourceOwner finds the server that provides a resource, either directly or through a template.
+func (g *Gateway) resourceOwner(uri string) *catalog.ServerConfig {
+	g.ownersMu.RLock()
+	defer g.ownersMu.RUnlock()
+
+	if serverConfig, found := g.resourceOwners[uri]; found {
+		return serverConfig
+	}
+	for uriTemplate, serverConfig := range g.resourceTemplateOwners {
+		template, err := uritemplate.New(uriTemplate)
+		if err != nil {
+			continue
+		}
+		if template.Regexp().MatchString(uri) {
+			return serverConfig
+		}
+	}
+	return nil
+}
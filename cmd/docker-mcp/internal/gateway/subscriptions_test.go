@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/catalog"
+)
+
+type fakeUpstream struct {
+	subscribed   int
+	unsubscribed int
+}
+
+func (f *fakeUpstream) subscribe() (func(), error) {
+	f.subscribed++
+	return func() { f.unsubscribed++ }, nil
+}
+
+func TestSubscriptionsAreReferenceCounted(t *testing.T) {
+	s := newSubscriptions()
+	upstream := &fakeUpstream{}
+	ss1, ss2 := &mcp.ServerSession{}, &mcp.ServerSession{}
+
+	first, err := s.Add(ss1, "file:///a", "fs", upstream.subscribe)
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	first, err = s.Add(ss2, "file:///a", "fs", upstream.subscribe)
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	// Subscribing twice is a no-op.
+	first, err = s.Add(ss1, "file:///a", "fs", upstream.subscribe)
+	require.NoError(t, err)
+	assert.False(t, first)
+	assert.Equal(t, 1, upstream.subscribed)
+
+	s.Remove(ss1, "file:///a")
+	assert.Equal(t, 0, upstream.unsubscribed)
+
+	s.Remove(ss2, "file:///a")
+	assert.Equal(t, 1, upstream.unsubscribed)
+
+	// Unknown subscriptions are ignored.
+	s.Remove(ss2, "file:///a")
+	assert.Equal(t, 1, upstream.unsubscribed)
+}
+
+func TestSubscriptionsRemoveSession(t *testing.T) {
+	s := newSubscriptions()
+	a, b := &fakeUpstream{}, &fakeUpstream{}
+	ss1, ss2 := &mcp.ServerSession{}, &mcp.ServerSession{}
+
+	_, err := s.Add(ss1, "file:///a", "fs", a.subscribe)
+	require.NoError(t, err)
+	first, err := s.Add(ss1, "file:///b", "fs", b.subscribe)
+	require.NoError(t, err)
+	assert.False(t, first)
+	_, err = s.Add(ss2, "file:///b", "fs", b.subscribe)
+	require.NoError(t, err)
+
+	s.RemoveSession(ss1)
+	assert.Equal(t, 1, a.unsubscribed)
+	assert.Equal(t, 0, b.unsubscribed)
+
+	s.Close()
+	assert.Equal(t, 1, b.unsubscribed)
+}
+
+func TestSubscriptionsUpstreamError(t *testing.T) {
+	s := newSubscriptions()
+	ss := &mcp.ServerSession{}
+
+	_, err := s.Add(ss, "file:///a", "fs", func() (func(), error) {
+		return nil, errors.New("not supported")
+	})
+	require.Error(t, err)
+
+	upstream := &fakeUpstream{}
+	first, err := s.Add(ss, "file:///a", "fs", upstream.subscribe)
+	require.NoError(t, err)
+	assert.True(t, first)
+	assert.Equal(t, 1, upstream.subscribed)
+}
+
+func TestSubscriptionsDontBlockWhileSubscribing(t *testing.T) {
+	s := newSubscriptions()
+	ss1, ss2, ss3 := &mcp.ServerSession{}, &mcp.ServerSession{}, &mcp.ServerSession{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := &fakeUpstream{}
+	slowSubscribe := func() (func(), error) {
+		close(started)
+		<-release
+		return slow.subscribe()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := s.Add(ss1, "file:///slow", "fs", slowSubscribe)
+		assert.NoError(t, err)
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		_, err := s.Add(ss2, "file:///slow", "fs", slowSubscribe)
+		assert.NoError(t, err)
+	}()
+
+	// Other resources can be subscribed to and unsubscribed from meanwhile.
+	other := &fakeUpstream{}
+	_, err := s.Add(ss3, "file:///other", "fs", other.subscribe)
+	require.NoError(t, err)
+	s.Remove(ss3, "file:///other")
+	assert.Equal(t, 1, other.unsubscribed)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, slow.subscribed)
+
+	s.RemoveSession(ss1)
+	s.RemoveSession(ss2)
+	assert.Equal(t, 1, slow.unsubscribed)
+}
+
+func TestSubscriptionsReleasedWhileSubscribing(t *testing.T) {
+	s := newSubscriptions()
+	ss := &mcp.ServerSession{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	upstream := &fakeUpstream{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := s.Add(ss, "file:///a", "fs", func() (func(), error) {
+			close(started)
+			<-release
+			return upstream.subscribe()
+		})
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	// The session is closed before the gateway subscribed to the server.
+	s.RemoveSession(ss)
+	close(release)
+	<-done
+	assert.Equal(t, 1, upstream.unsubscribed)
+}
+
+func TestResourceOwner(t *testing.T) {
+	fs := &catalog.ServerConfig{Name: "fs"}
+	github := &catalog.ServerConfig{Name: "github"}
+	g := &Gateway{
+		resourceOwners:         map[string]*catalog.ServerConfig{"file:///README.md": fs},
+		resourceTemplateOwners: map[string]*catalog.ServerConfig{"repo://{owner}/{repo}": github},
+	}
+
+	assert.Equal(t, fs, g.resourceOwner("file:///README.md"))
+	assert.Equal(t, github, g.resourceOwner("repo://docker/mcp-gateway"))
+	assert.Nil(t, g.resourceOwner("file:///unknown"))
+}
@@ -0,0 +1,357 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
+)
+
+// configureTLS wraps the listener with TLS, when a certificate is configured or a self-signed one
+// is requested.
+func (g *Gateway) configureTLS(
+	ctx context.Context,
+	ln net.Listener,
+	clientCertificate *auth.ClientCertificate,
+) (net.Listener, error) {
+	if (g.TLSCert == "") != (g.TLSKey == "") {
+		return nil, errors.New("--tls-cert and --tls-key must be used together")
+	}
+	if g.TLSSelfSigned && g.TLSCert != "" {
+		return nil, errors.New("--tls-self-signed can't be used with --tls-cert and --tls-key")
+	}
+	if g.TLSCert == "" && !g.TLSSelfSigned {
+		if clientCertificate != nil {
+			return nil, errors.New("--auth-client-ca requires --tls-cert and --tls-key, or --tls-self-signed")
+		}
+		return ln, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	if g.TLSSelfSigned {
+		certificate, err := selfSignedCertificate()
+		if err != nil {
+			return nil, fmt.Errorf("generating self-signed certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		log("- Using a self-signed certificate, SHA-256 fingerprint", fingerprint(certificate.Leaf))
+	} else {
+		reloader, err := newCertificateReloader(g.TLSCert, g.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := reloader.watch(ctx); err != nil {
+			return nil, fmt.Errorf("watching TLS certificate: %w", err)
+		}
+		tlsConfig.GetCertificate = reloader.GetCertificate
+	}
+
+	if clientCertificate != nil {
+		clientCertificate.ConfigureTLS(tlsConfig)
+	}
+
+	g.tlsEnabled = true
+	return newTLSListener(ln, tlsConfig), nil
+}
+
+// httpHandler is the handler of the HTTP transports. With TLS, clients that don't use it can only
+// reach /health, so that plain HTTP health checks keep working.
+func (g *Gateway) httpHandler(mux *http.ServeMux) http.Handler {
+	if !g.tlsEnabled {
+		return mux
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil && r.URL.Path != "/health" {
+			http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// certificateReloader serves a certificate read from files, and reads them again when they change.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+func (r *certificateReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.certificate = &certificate
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.certificate, nil
+}
+
+// watch reloads the certificate when its files change. The directories are watched, rather than
+// the files, so that files replaced atomically, like mounted Kubernetes secrets, are also reloaded.
+// On error, the previous certificate is kept.
+func (r *certificateReloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// Debounce: the certificate and the key are often written one after the other
+			debounce:
+				for {
+					select {
+					case <-time.After(300 * time.Millisecond):
+						break debounce
+					case _, ok := <-watcher.Events:
+						if !ok {
+							return
+						}
+					case err, ok := <-watcher.Errors:
+						if !ok {
+							return
+						}
+						log("! Watching the TLS certificate:", err)
+					}
+				}
+
+				if err := r.reload(); err != nil {
+					log("Can't reload the TLS certificate:", err)
+					continue
+				}
+				log("- TLS certificate reloaded")
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log("! Watching the TLS certificate:", err)
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// selfSignedCertificate generates a certificate for local use, valid for localhost and the name
+// of the machine.
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	dnsNames := []string{"localhost", "host.docker.internal"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		dnsNames = append(dnsNames, hostname)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "MCP Gateway", Organization: []string{"MCP Gateway"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              dnsNames,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+func fingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// tlsListener accepts both TLS and plain connections on the same port. The first byte sent by
+// the client tells them apart: TLS connections start with a handshake record.
+type tlsListener struct {
+	net.Listener
+	config *tls.Config
+
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+const (
+	tlsHandshakeRecord = 0x16
+	sniffTimeout       = 10 * time.Second
+)
+
+func newTLSListener(ln net.Listener, config *tls.Config) net.Listener {
+	l := &tlsListener{
+		Listener: ln,
+		config:   config,
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+	go l.acceptLoop()
+
+	return l
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+
+func (l *tlsListener) Close() error {
+	err := l.Listener.Close()
+	l.stop(net.ErrClosed)
+	return err
+}
+
+func (l *tlsListener) stop(err error) {
+	l.closeOnce.Do(func() {
+		l.err = err
+		close(l.done)
+	})
+}
+
+func (l *tlsListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				l.stop(net.ErrClosed)
+				return
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			l.stop(err)
+			return
+		}
+
+		// Sniff in the background so that a slow client doesn't block the others.
+		go l.sniff(conn)
+	}
+}
+
+func (l *tlsListener) sniff(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	first, err := reader.Peek(1)
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	var accepted net.Conn = &peekedConn{Conn: conn, reader: reader}
+	if first[0] == tlsHandshakeRecord {
+		accepted = tls.Server(accepted, l.config)
+	}
+
+	select {
+	case l.conns <- accepted:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// peekedConn is a connection whose first bytes were already read into a buffer.
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
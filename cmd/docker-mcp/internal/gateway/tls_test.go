@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCertificate(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	t.Helper()
+
+	certificate, err := selfSignedCertificate()
+	require.NoError(t, err)
+	key, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certificate.Leaf
+}
+
+func TestSelfSignedCertificate(t *testing.T) {
+	certificate, err := selfSignedCertificate()
+	require.NoError(t, err)
+
+	require.NoError(t, certificate.Leaf.VerifyHostname("localhost"))
+	require.NoError(t, certificate.Leaf.VerifyHostname("127.0.0.1"))
+	assert.True(t, certificate.Leaf.NotAfter.After(time.Now().AddDate(0, 11, 0)))
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	first := writeCertificate(t, certFile, keyFile)
+
+	reloader, err := newCertificateReloader(certFile, keyFile)
+	require.NoError(t, err)
+	require.NoError(t, reloader.watch(t.Context()))
+
+	current := func() []byte {
+		certificate, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		return certificate.Certificate[0]
+	}
+	assert.Equal(t, first.Raw, current())
+
+	second := writeCertificate(t, certFile, keyFile)
+	assert.Eventually(t, func() bool {
+		return string(current()) == string(second.Raw)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// A broken certificate is ignored and the previous one is kept.
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, second.Raw, current())
+}
+
+func TestTLSListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	g := &Gateway{Options: Options{TLSSelfSigned: true}}
+	tlsListener, err := g.configureTLS(t.Context(), ln, nil)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	mux.Handle("/mcp", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	server := &http.Server{Handler: g.httpHandler(mux)}
+	go func() { _ = server.Serve(tlsListener) }()
+	defer server.Close()
+
+	base := "://" + ln.Addr().String()
+	plain := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	// Plain HTTP can only reach /health.
+	response, err := plain.Get("http" + base + "/health")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = plain.Get("http" + base + "/mcp")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusPermanentRedirect, response.StatusCode)
+	assert.Equal(t, "https"+base+"/mcp", response.Header.Get("Location"))
+
+	// TLS clients negotiate HTTP/2.
+	secure := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		ForceAttemptHTTP2: true,
+	}}
+	response, err = secure.Get("https" + base + "/mcp")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+}
+
+func TestConfigureTLSValidation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	g := &Gateway{Options: Options{TLSCert: "cert.pem"}}
+	_, err = g.configureTLS(t.Context(), ln, nil)
+	require.ErrorContains(t, err, "must be used together")
+
+	g = &Gateway{Options: Options{TLSCert: "cert.pem", TLSKey: "key.pem", TLSSelfSigned: true}}
+	_, err = g.configureTLS(t.Context(), ln, nil)
+	require.ErrorContains(t, err, "can't be used with")
+
+	g = &Gateway{}
+	same, err := g.configureTLS(t.Context(), ln, nil)
+	require.NoError(t, err)
+	assert.Same(t, ln, same)
+}
@@ -6,7 +6,6 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -25,9 +24,10 @@ func (g *Gateway) startSseServer(ctx context.Context, ln net.Listener) error {
 	sseHandler := mcp.NewSSEHandler(func(_ *http.Request) *mcp.Server {
 		return g.mcpServer
 	})
-	mux.Handle("/sse", sseHandler)
+	mux.Handle("/sse", g.authenticate(sseHandler))
+	g.registerAuthHandlers(mux)
 	httpServer := &http.Server{
-		Handler: mux,
+		Handler: g.httpHandler(mux),
 	}
 	go func() {
 		<-ctx.Done()
@@ -40,12 +40,34 @@ func (g *Gateway) startStreamingServer(ctx context.Context, ln net.Listener) err
 	mux := http.NewServeMux()
 	mux.Handle("/health", healthHandler(&g.health))
 	mux.Handle("/", redirectHandler("/mcp"))
-	streamHandler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
+	streamHandler := newStreamableHandler(func(_ *http.Request) *mcp.Server {
 		return g.mcpServer
-	}, nil)
-	mux.Handle("/mcp", streamHandler)
+	}, g.eventStore, g.sharedState)
+	mux.Handle("/mcp", g.authenticate(streamHandler))
+	g.registerAuthHandlers(mux)
 	httpServer := &http.Server{
-		Handler: mux,
+		Handler: g.httpHandler(mux),
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	return httpServer.Serve(ln)
+}
+
+func (g *Gateway) startWebSocketServer(ctx context.Context, ln net.Listener) error {
+	ws := g.webSocketTransport(ln)
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", healthHandler(&g.health))
+	mux.Handle("/", redirectHandler("/ws"))
+	mux.Handle("/ws", g.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws.ServeMCP(w, r, g.mcpServer)
+	})))
+	g.registerAuthHandlers(mux)
+	httpServer := &http.Server{
+		Handler: g.httpHandler(mux),
 	}
 
 	go func() {
@@ -64,50 +86,111 @@ func (g *Gateway) startCentralStreamingServer(
 	mux.Handle("/health", healthHandler(&g.health))
 	mux.Handle("/", redirectHandler("/mcp"))
 
-	var lock sync.Mutex
-	handlersPerSelectionOfServers := map[string]*mcp.StreamableHTTPHandler{}
-	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-		serverNames := r.Header.Get("x-mcp-servers")
-		if len(serverNames) == 0 {
-			log("No server names provided in the request header 'x-mcp-servers'")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = io.WriteString(
-				w,
-				"No server names provided in the request header 'x-mcp-servers'",
-			)
+	g.registerAuthHandlers(mux)
+	mux.Handle("/mcp", g.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instance, ok := g.selectInstance(ctx, w, r, configuration)
+		if !ok {
 			return
 		}
 
-		lock.Lock()
-		handler := handlersPerSelectionOfServers[serverNames]
-		if handler == nil {
-			if err := g.reloadConfiguration(ctx, configuration, parseServerNames(serverNames), nil); err != nil {
-				lock.Unlock()
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = io.WriteString(w, "Failed to reload configuration")
-				return
-			}
-			handler = mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
-				return g.mcpServer
-			}, nil)
-			handlersPerSelectionOfServers[serverNames] = handler
+		instance.handler.ServeHTTP(w, r)
+	})))
+	httpServer := &http.Server{
+		Handler: g.httpHandler(mux),
+	}
+
+	return g.serveCentral(ctx, ln, httpServer)
+}
+
+func (g *Gateway) startCentralWebSocketServer(
+	ctx context.Context,
+	ln net.Listener,
+	configuration Configuration,
+) error {
+	ws := g.webSocketTransport(ln)
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", healthHandler(&g.health))
+	mux.Handle("/", redirectHandler("/ws"))
+
+	g.registerAuthHandlers(mux)
+	mux.Handle("/ws", g.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instance, ok := g.selectInstance(ctx, w, r, configuration)
+		if !ok {
+			return
 		}
-		lock.Unlock()
 
-		handler.ServeHTTP(w, r)
-	})
+		// The socket uses the instance for as long as it's open.
+		release := g.instances.Hold(instance)
+		defer release()
+
+		ws.ServeMCP(w, r, instance.gateway.mcpServer)
+	})))
 	httpServer := &http.Server{
-		Handler: mux,
+		Handler: g.httpHandler(mux),
 	}
 
+	return g.serveCentral(ctx, ln, httpServer)
+}
+
+func (g *Gateway) serveCentral(ctx context.Context, ln net.Listener, httpServer *http.Server) error {
 	go func() {
 		<-ctx.Done()
 		ln.Close()
 	}()
+	if g.SessionIdleTimeout > 0 {
+		go g.evictIdleInstances(ctx)
+	}
+
 	g.health.SetHealthy()
 	return httpServer.Serve(ln)
 }
 
+// selectInstance returns the MCP server serving the selection of servers of a request, read from the
+// 'x-mcp-servers' header or, for clients that can't set headers, the 'servers' query parameter.
+func (g *Gateway) selectInstance(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	configuration Configuration,
+) (*serverInstance, bool) {
+	serverNames := r.Header.Get("x-mcp-servers")
+	if serverNames == "" {
+		serverNames = r.URL.Query().Get("servers")
+	}
+	if len(serverNames) == 0 {
+		log("No server names provided in the request header 'x-mcp-servers'")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(
+			w,
+			"No server names provided in the request header 'x-mcp-servers'",
+		)
+		return nil, false
+	}
+
+	// Each selection of servers gets its own isolated MCP server.
+	selection := parseServerNames(serverNames)
+	instance, err := g.instances.Get(selectionKey(selection), func() (*serverInstance, error) {
+		return g.newInstance(ctx, configuration, selection)
+	})
+	if err != nil {
+		logf("Failed to start servers %s: %s", serverNames, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, "Failed to reload configuration")
+		return nil, false
+	}
+
+	return instance, true
+}
+
+// webSocketTransport returns the WebSocket transport of the gateway, creating one if needed.
+func (g *Gateway) webSocketTransport(ln net.Listener) *WebSocketTransportWrapper {
+	if ws, ok := g.transport.(*WebSocketTransportWrapper); ok {
+		return ws
+	}
+	return NewWebSocketTransportWrapper(ln, nil)
+}
+
 func parseServerNames(serverNames string) []string {
 	var names []string
 	for name := range strings.SplitSeq(serverNames, ",") {
@@ -2,14 +2,19 @@ package gateway
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	mcpauth "github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // WebSocketTransportWrapper wraps WebSocket for bidirectional MCP communication
@@ -80,10 +85,9 @@ func NewWebSocketTransportWrapper(
 		WriteBufferSize:   config.WriteBufferSize,
 		HandshakeTimeout:  config.HandshakeTimeout,
 		EnableCompression: config.EnableCompression,
-		CheckOrigin: func(r *http.Request) bool {
-			// In production, implement proper origin checking
-			return true
-		},
+		// The "mcp" subprotocol is negotiated when the client asks for it.
+		Subprotocols: []string{"mcp"},
+		// CheckOrigin is left to the default, which rejects browsers connecting from another origin.
 	}
 
 	return &WebSocketTransportWrapper{
@@ -361,3 +365,180 @@ func (w *WebSocketWriter) Write(p []byte) (n int, err error) {
 	}
 	return len(p), nil
 }
+
+func isWebSocketTransport(transport string) bool {
+	switch strings.ToLower(transport) {
+	case "websocket", "ws":
+		return true
+	default:
+		return false
+	}
+}
+
+// ServeMCP upgrades a request to a WebSocket and runs an MCP session over it, until the socket is closed.
+// Each socket is its own session.
+func (t *WebSocketTransportWrapper) ServeMCP(w http.ResponseWriter, r *http.Request, server *mcp.Server) {
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		t.logger.Logf("Failed to upgrade connection: %v", err)
+		if t.metrics != nil {
+			t.metrics.RecordConnectionFailure()
+		}
+		return
+	}
+
+	conn.SetReadLimit(t.config.MaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(t.config.PongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(t.config.PongTimeout))
+	})
+
+	wsConn := &WebSocketConnection{
+		conn:          conn,
+		id:            rand.Text(),
+		createdAt:     time.Now(),
+		lastMessageAt: time.Now(),
+	}
+	t.mu.Lock()
+	t.connections[wsConn.id] = wsConn
+	t.mu.Unlock()
+	defer t.removeConnection(wsConn.id)
+
+	if t.metrics != nil {
+		t.metrics.RecordConnection()
+		t.mu.RLock()
+		t.metrics.SetCustomMetric("ws_connections", int64(len(t.connections)))
+		t.mu.RUnlock()
+	}
+
+	connection := &webSocketMCPConnection{
+		conn:      conn,
+		sessionID: wsConn.id,
+		extra: &mcp.RequestExtra{
+			TokenInfo: mcpauth.TokenInfoFromContext(r.Context()),
+			Header:    r.Header,
+		},
+		metrics: t.metrics,
+		done:    make(chan struct{}),
+	}
+
+	session, err := server.Connect(r.Context(), &webSocketMCPTransport{connection: connection}, nil)
+	if err != nil {
+		t.logger.Logf("Failed to start session for %s: %v", r.RemoteAddr, err)
+		_ = connection.Close()
+		return
+	}
+
+	go connection.ping(t.config.PingInterval)
+	_ = session.Wait()
+}
+
+// webSocketMCPTransport is an mcp.Transport over an accepted WebSocket.
+type webSocketMCPTransport struct {
+	connection *webSocketMCPConnection
+}
+
+func (t *webSocketMCPTransport) Connect(context.Context) (mcp.Connection, error) {
+	return t.connection, nil
+}
+
+// webSocketMCPConnection exchanges one JSON-RPC message per WebSocket message.
+type webSocketMCPConnection struct {
+	conn      *websocket.Conn
+	sessionID string
+	// extra is attached to every request, so that handlers see who the client is.
+	extra   *mcp.RequestExtra
+	metrics *TransportMetrics
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (c *webSocketMCPConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		if messageType != websocket.TextMessage && messageType != websocket.BinaryMessage {
+			continue
+		}
+		if c.metrics != nil {
+			c.metrics.RecordMessage(false, len(data))
+		}
+
+		message, err := jsonrpc.DecodeMessage(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding message: %w", err)
+		}
+		if request, ok := message.(*jsonrpc.Request); ok && c.extra != nil {
+			request.Extra = c.extra
+		}
+
+		return message, nil
+	}
+}
+
+func (c *webSocketMCPConnection) Write(_ context.Context, message jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(message)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	if c.metrics != nil {
+		c.metrics.RecordMessage(true, len(data))
+	}
+
+	return nil
+}
+
+func (c *webSocketMCPConnection) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second),
+		)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *webSocketMCPConnection) SessionID() string {
+	return c.sessionID
+}
+
+// ping keeps the socket alive until the connection is closed. Pongs extend the read deadline.
+func (c *webSocketMCPConnection) ping(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				_ = c.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
+)
+
+type whoamiOutput struct {
+	Identity string `json:"identity"`
+}
+
+func startWebSocketGateway(t *testing.T, g *Gateway) string {
+	t.Helper()
+
+	g.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(g.mcpServer, &mcp.Tool{Name: "whoami"}, func(
+		_ context.Context,
+		req *mcp.CallToolRequest,
+		_ struct{},
+	) (*mcp.CallToolResult, whoamiOutput, error) {
+		var output whoamiOutput
+		if identity := auth.FromRequest(req); identity != nil {
+			output.Identity = identity.String()
+		}
+		return nil, output, nil
+	})
+	g.health.SetHealthy()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	go func() { _ = g.startWebSocketServer(ctx, ln) }()
+
+	return ln.Addr().String()
+}
+
+func connectWebSocket(t *testing.T, url string, header http.Header) (*mcp.ClientSession, error) {
+	t.Helper()
+
+	conn, response, err := websocket.DefaultDialer.DialContext(t.Context(), url, header)
+	if response != nil {
+		response.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &webSocketMCPTransport{connection: &webSocketMCPConnection{
+		conn: conn,
+		done: make(chan struct{}),
+	}}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	session, err := client.Connect(t.Context(), transport, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { _ = session.Close() })
+
+	return session, nil
+}
+
+func TestWebSocketServer(t *testing.T) {
+	g := &Gateway{}
+	addr := startWebSocketGateway(t, g)
+
+	response, err := http.Get("http://" + addr + "/health")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Each socket is its own session.
+	first, err := connectWebSocket(t, "ws://"+addr+"/ws", nil)
+	require.NoError(t, err)
+	second, err := connectWebSocket(t, "ws://"+addr+"/ws", nil)
+	require.NoError(t, err)
+	var sessionIDs []string
+	for session := range g.mcpServer.Sessions() {
+		sessionIDs = append(sessionIDs, session.ID())
+	}
+	require.Len(t, sessionIDs, 2)
+	assert.NotEqual(t, sessionIDs[0], sessionIDs[1])
+
+	tools, err := first.ListTools(t.Context(), nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "whoami", tools.Tools[0].Name)
+
+	result, err := second.CallTool(t.Context(), &mcp.CallToolParams{Name: "whoami"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestWebSocketServerAuthentication(t *testing.T) {
+	addr := startWebSocketGateway(t, &Gateway{
+		authenticator: auth.Chain{auth.NewTokens(map[string]string{"ci": "secret"})},
+	})
+
+	_, err := connectWebSocket(t, "ws://"+addr+"/ws", nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+
+	header := http.Header{"Authorization": {"Bearer secret"}}
+	session, err := connectWebSocket(t, "ws://"+addr+"/ws", header)
+	require.NoError(t, err)
+
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{Name: "whoami"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"identity": "token:ci"}, result.StructuredContent)
+}
+
+func TestWebSocketServerRejectsOtherOrigins(t *testing.T) {
+	addr := startWebSocketGateway(t, &Gateway{})
+
+	header := http.Header{"Origin": {"https://attacker.example"}}
+	_, err := connectWebSocket(t, "ws://"+addr+"/ws", header)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+}
+
+func TestCentralWebSocketServerRequiresServers(t *testing.T) {
+	g := &Gateway{instances: newServerInstances()}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go func() { _ = g.startCentralWebSocketServer(ctx, ln, Configuration{}) }()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	response, err := client.Get("http://" + ln.Addr().String() + "/ws")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestIsWebSocketTransport(t *testing.T) {
+	for _, transport := range []string{"websocket", "ws", "WebSocket"} {
+		assert.True(t, isWebSocketTransport(transport), transport)
+	}
+	for _, transport := range []string{"stdio", "sse", "streaming", strings.Repeat("ws", 2)} {
+		assert.False(t, isWebSocketTransport(transport), transport)
+	}
+}
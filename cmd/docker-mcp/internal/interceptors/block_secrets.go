@@ -4,13 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/secretsscan"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
 )
 
+// SecretsOptions configure how the secrets sent to and returned by the tools are handled.
+type SecretsOptions struct {
+	// Scanner finds the secrets. Defaults to the builtin rules.
+	Scanner *secretsscan.Scanner
+	// Redact masks the secrets instead of failing the calls.
+	Redact bool
+	// Allowed lists, by server, the rules whose secrets can be sent to and returned by the tools of
+	// the server. Both the servers and the rules are glob patterns.
+	Allowed map[string][]string
+}
+
 func BlockSecretsMiddleware() mcp.Middleware {
+	return SecretsMiddleware(SecretsOptions{})
+}
+
+func SecretsMiddleware(options SecretsOptions) mcp.Middleware {
+	if options.Scanner == nil {
+		options.Scanner = secretsscan.Default()
+	}
+
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
 			// Only check secrets for tools/call method
@@ -18,24 +39,37 @@ func BlockSecretsMiddleware() mcp.Middleware {
 				return next(ctx, method, req)
 			}
 
+			serverName := ServerName(ctx)
 			var toolName string
-			var arguments any
+			var params *mcp.CallToolParamsRaw
 
 			// Try to extract from request
 			if callReq, ok := req.(*mcp.CallToolRequest); ok && callReq.Params != nil {
-				toolName = callReq.Params.Name
-				arguments = callReq.Params.Arguments
+				params = callReq.Params
+				toolName = params.Name
 			}
 
 			if toolName != "" {
 				logf("  - Scanning tool call arguments for secrets...\n")
 
-				argumentsStr := argumentsToString(arguments)
-				if secretsscan.ContainsSecrets(argumentsStr) {
+				findings := options.find(serverName, argumentsToString(params.Arguments))
+				switch {
+				case len(findings) == 0:
+					logf("  > No secret found in arguments.\n")
+				case !options.Redact:
 					return nil, fmt.Errorf("a secret is being passed to tool %s", toolName)
+				default:
+					arguments, counts, err := options.redactJSON(serverName, params.Arguments)
+					if err != nil {
+						return nil, fmt.Errorf("redacting secrets passed to tool %s: %w", toolName, err)
+					}
+					// Secrets spanning several values, e.g. a key and its value, can't be redacted.
+					if len(options.find(serverName, string(arguments))) > 0 {
+						return nil, fmt.Errorf("a secret is being passed to tool %s", toolName)
+					}
+					params.Arguments = arguments
+					recordRedactions(ctx, serverName, "arguments", counts)
 				}
-
-				logf("  > No secret found in arguments.\n")
 			}
 
 			result, err := next(ctx, method, req)
@@ -47,28 +81,132 @@ func BlockSecretsMiddleware() mcp.Middleware {
 			if result != nil {
 				logf("  - Scanning tool call response for secrets...\n")
 
-				var contents string
+				callResult := toCallToolResult(result)
+				if callResult == nil {
+					return result, nil
+				}
+
+				counts := map[string]int{}
+				for _, content := range callResult.Content {
+					c, ok := content.(*mcp.TextContent)
+					if !ok {
+						continue
+					}
 
-				// Try to extract content from JSON result
-				if jsonData, err := json.Marshal(result); err == nil {
-					var callResult mcp.CallToolResult
-					if err := json.Unmarshal(jsonData, &callResult); err == nil {
-						for _, content := range callResult.Content {
-							if c, ok := content.(*mcp.TextContent); ok {
-								contents += c.Text
-							}
+					findings := options.find(serverName, c.Text)
+					if len(findings) == 0 {
+						continue
+					}
+					if !options.Redact {
+						return nil, fmt.Errorf("a secret is being returned by the %s tool", toolName)
+					}
+					c.Text = secretsscan.Redact(c.Text, findings)
+					countFindings(counts, findings)
+				}
+				if callResult.StructuredContent != nil {
+					buf, err := json.Marshal(callResult.StructuredContent)
+					if err == nil && len(options.find(serverName, string(buf))) > 0 {
+						if !options.Redact {
+							return nil, fmt.Errorf("a secret is being returned by the %s tool", toolName)
+						}
+						structured, structuredCounts, err := options.redactJSON(serverName, buf)
+						if err != nil || len(options.find(serverName, string(structured))) > 0 {
+							return nil, fmt.Errorf("a secret is being returned by the %s tool", toolName)
+						}
+						callResult.StructuredContent = structured
+						for ruleID, count := range structuredCounts {
+							counts[ruleID] += count
 						}
 					}
 				}
 
-				if contents != "" && secretsscan.ContainsSecrets(contents) {
-					return nil, fmt.Errorf("a secret is being returned by the %s tool", toolName)
+				if len(counts) == 0 {
+					logf("  > No secret found in response.\n")
+					return result, nil
 				}
-
-				logf("  > No secret found in response.\n")
+				recordRedactions(ctx, serverName, "result", counts)
+				return callResult, nil
 			}
 
 			return result, nil
 		}
 	}
 }
+
+// toCallToolResult returns a result as a CallToolResult, if possible.
+func toCallToolResult(result mcp.Result) *mcp.CallToolResult {
+	if callResult, ok := result.(*mcp.CallToolResult); ok {
+		return callResult
+	}
+
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	var callResult mcp.CallToolResult
+	if err := json.Unmarshal(jsonData, &callResult); err != nil {
+		return nil
+	}
+	return &callResult
+}
+
+// find returns the secrets found in a text that the server isn't allowed to see.
+func (o *SecretsOptions) find(serverName, text string) []secretsscan.Finding {
+	var findings []secretsscan.Finding
+	for _, finding := range o.Scanner.Find(text) {
+		if !o.allowed(serverName, finding.RuleID) {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func (o *SecretsOptions) allowed(serverName, ruleID string) bool {
+	for serverPattern, rulePatterns := range o.Allowed {
+		if matched, _ := path.Match(serverPattern, serverName); !matched {
+			continue
+		}
+		if matchesAny(rulePatterns, ruleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON masks the secrets in the strings of a JSON document.
+func (o *SecretsOptions) redactJSON(serverName string, document json.RawMessage) (json.RawMessage, map[string]int, error) {
+	var value any
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, nil, err
+	}
+
+	counts := map[string]int{}
+	value, _ = mapStrings(value, func(s string) (string, error) {
+		findings := o.find(serverName, s)
+		if len(findings) == 0 {
+			return s, nil
+		}
+		countFindings(counts, findings)
+		return secretsscan.Redact(s, findings), nil
+	})
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return redacted, counts, nil
+}
+
+func countFindings(counts map[string]int, findings []secretsscan.Finding) {
+	for _, finding := range findings {
+		counts[finding.RuleID]++
+	}
+}
+
+func recordRedactions(ctx context.Context, serverName, direction string, counts map[string]int) {
+	total := 0
+	for ruleID, count := range counts {
+		telemetry.RecordSecretRedactions(ctx, serverName, ruleID, direction, int64(count))
+		total += count
+	}
+	logf("  > Redacted %d secrets in %s.\n", total, direction)
+}
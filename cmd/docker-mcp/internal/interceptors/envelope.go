@@ -0,0 +1,224 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
+)
+
+// ProtocolV1 is the version of the envelopes sent to the interceptors, and of the decisions they
+// answer with.
+const ProtocolV1 = "v1"
+
+// envelope is what interceptors using ProtocolV1 receive.
+type envelope struct {
+	Version  string           `json:"version"`
+	Hook     string           `json:"hook"`
+	Method   string           `json:"method"`
+	Server   string           `json:"server,omitempty"`
+	Tool     *envelopeTool    `json:"tool,omitempty"`
+	Session  *envelopeSession `json:"session,omitempty"`
+	Request  json.RawMessage  `json:"request,omitempty"`
+	Response json.RawMessage  `json:"response,omitempty"`
+}
+
+type envelopeTool struct {
+	Name        string               `json:"name"`
+	Annotations *mcp.ToolAnnotations `json:"annotations,omitempty"`
+}
+
+type envelopeSession struct {
+	ID       string              `json:"id,omitempty"`
+	Client   *mcp.Implementation `json:"client,omitempty"`
+	Identity string              `json:"identity,omitempty"`
+}
+
+// decision is what interceptors using ProtocolV1 answer. An empty answer lets the request through
+// unchanged.
+type decision struct {
+	// Decision is either allow, the default, or deny.
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Request replaces fields of the request's params, e.g. the arguments of a tool call. Only
+	// before interceptors can change the request.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Response replaces the response. Before interceptors don't call the server then.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Meta is added to the _meta of the response.
+	Meta map[string]any `json:"_meta,omitempty"`
+}
+
+func newEnvelope(ctx context.Context, hook, method string, req mcp.Request) (*envelope, error) {
+	env := &envelope{
+		Version: ProtocolV1,
+		Hook:    hook,
+		Method:  method,
+		Server:  ServerName(ctx),
+	}
+
+	if params := req.GetParams(); params != nil {
+		request, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling request: %w", err)
+		}
+		env.Request = request
+
+		if call, ok := params.(*mcp.CallToolParamsRaw); ok && call != nil {
+			env.Tool = &envelopeTool{Name: call.Name, Annotations: ToolAnnotations(ctx)}
+		}
+	}
+
+	if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+		env.Session = &envelopeSession{ID: ss.ID()}
+		if initializeParams := ss.InitializeParams(); initializeParams != nil {
+			env.Session.Client = initializeParams.ClientInfo
+		}
+	}
+	if identity := auth.FromRequest(req); identity != nil {
+		if env.Session == nil {
+			env.Session = &envelopeSession{}
+		}
+		env.Session.Identity = identity.String()
+	}
+
+	return env, nil
+}
+
+// interceptEnvelope intercepts a request with ProtocolV1.
+func (i *Interceptor) interceptEnvelope(
+	ctx context.Context,
+	method string,
+	req mcp.Request,
+	next mcp.MethodHandler,
+) (mcp.Result, error) {
+	env, err := newEnvelope(ctx, i.When, method, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.When == "before" {
+		decision, err := i.decide(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+		if decision == nil {
+			return next(ctx, method, req)
+		}
+
+		if len(decision.Request) > 0 {
+			params := req.GetParams()
+			if params == nil {
+				return nil, fmt.Errorf("interceptor changed the request of %s, which has no params", method)
+			}
+			if err := json.Unmarshal(decision.Request, params); err != nil {
+				return nil, fmt.Errorf("unmarshalling interceptor request: %w", err)
+			}
+		}
+
+		result, err := i.apply(method, decision, nil)
+		if err != nil || result != nil {
+			return result, err
+		}
+
+		response, err := next(ctx, method, req)
+		if err != nil {
+			return nil, err
+		}
+		return withMeta(response, decision.Meta), nil
+	}
+
+	response, err := next(ctx, method, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if env.Response, err = json.Marshal(response); err != nil {
+		return nil, fmt.Errorf("marshalling response: %w", err)
+	}
+	decision, err := i.decide(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	if decision == nil {
+		return response, nil
+	}
+	return i.apply(method, decision, response)
+}
+
+// decide sends an envelope to the interceptor, and returns its decision, if any.
+func (i *Interceptor) decide(ctx context.Context, env *envelope) (*decision, error) {
+	message, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling envelope: %w", err)
+	}
+
+	out, err := i.call(ctx, env.Method, message)
+	if err != nil {
+		return nil, i.failed(env.Method, err)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var decision decision
+	if err := json.Unmarshal(out, &decision); err != nil {
+		return nil, i.failed(env.Method, fmt.Errorf("unmarshalling interceptor decision: %w", err))
+	}
+	if decision.Decision != "" && decision.Decision != "allow" && decision.Decision != "deny" {
+		return nil, i.failed(env.Method, fmt.Errorf("invalid interceptor decision '%s'", decision.Decision))
+	}
+	return &decision, nil
+}
+
+// apply applies a decision to the response, if any. It returns a nil result if the request should
+// go on.
+func (i *Interceptor) apply(method string, decision *decision, response mcp.Result) (mcp.Result, error) {
+	if decision.Decision == "deny" {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		logf("  - Interceptor %s:%s denied %s: %s", i.Type, i.Argument, method, reason)
+
+		// Denied tool calls are errors the LLM can see.
+		if method == "tools/call" {
+			return withMeta(&mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "Denied: " + reason}},
+			}, decision.Meta), nil
+		}
+		return nil, fmt.Errorf("denied by interceptor: %s", reason)
+	}
+
+	if len(decision.Response) > 0 {
+		replacement, err := decodeResult(method, decision.Response)
+		if err != nil {
+			return nil, err
+		}
+		response = replacement
+	}
+	if response == nil {
+		return nil, nil
+	}
+	return withMeta(response, decision.Meta), nil
+}
+
+// withMeta adds metadata to a result.
+func withMeta(result mcp.Result, meta map[string]any) mcp.Result {
+	if len(meta) == 0 {
+		return result
+	}
+
+	merged := maps.Clone(result.GetMeta())
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	maps.Copy(merged, meta)
+	result.SetMeta(merged)
+	return result
+}
@@ -0,0 +1,133 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decider is an http interceptor answering with a fixed decision.
+func decider(t *testing.T, answer string, received *envelope) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, received)
+		_, _ = io.WriteString(w, answer)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestEnvelopeBefore(t *testing.T) {
+	var received envelope
+	interceptor := Interceptor{
+		When:     "before",
+		Type:     "http",
+		Protocol: ProtocolV1,
+		Argument: decider(t, `{"request":{"arguments":{"path":"/safe"}},"_meta":{"checked":true}}`, &received),
+	}
+
+	var arguments string
+	handler := interceptor.ToMiddleware()(func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		arguments = string(req.(*mcp.CallToolRequest).Params.Arguments)
+		return &mcp.CallToolResult{}, nil
+	})
+
+	ctx := WithToolAnnotations(WithServerName(t.Context(), "files"), &mcp.ToolAnnotations{ReadOnlyHint: true})
+	result, err := handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+		Name:      "read_file",
+		Arguments: json.RawMessage(`{"path":"/etc/passwd"}`),
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, ProtocolV1, received.Version)
+	assert.Equal(t, "before", received.Hook)
+	assert.Equal(t, "files", received.Server)
+	require.NotNil(t, received.Tool)
+	assert.Equal(t, "read_file", received.Tool.Name)
+	assert.True(t, received.Tool.Annotations.ReadOnlyHint)
+	assert.JSONEq(t, `{"name":"read_file","arguments":{"path":"/etc/passwd"}}`, string(received.Request))
+
+	// The arguments are rewritten, and the response annotated.
+	assert.JSONEq(t, `{"path":"/safe"}`, arguments)
+	assert.Equal(t, true, result.GetMeta()["checked"])
+}
+
+func TestEnvelopeDeny(t *testing.T) {
+	var received envelope
+	interceptor := Interceptor{
+		When:     "before",
+		Type:     "http",
+		Protocol: ProtocolV1,
+		Argument: decider(t, `{"decision":"deny","reason":"not allowed"}`, &received),
+		Methods:  []string{"tools/call", "resources/read"},
+	}
+
+	called := false
+	handler := interceptor.ToMiddleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		called = true
+		return &mcp.CallToolResult{}, nil
+	})
+
+	result, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}})
+	require.NoError(t, err)
+	assert.True(t, result.(*mcp.CallToolResult).IsError)
+	assert.Equal(t, "Denied: not allowed", result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text)
+
+	_, err = handler(t.Context(), "resources/read", &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "file:///x"}})
+	require.ErrorContains(t, err, "not allowed")
+	assert.False(t, called)
+}
+
+func TestEnvelopeAfter(t *testing.T) {
+	var received envelope
+	interceptor := Interceptor{
+		When:     "after",
+		Type:     "http",
+		Protocol: ProtocolV1,
+		Argument: decider(t, `{"response":{"content":[{"type":"text","text":"redacted"}]},"_meta":{"redacted":1}}`, &received),
+	}
+
+	handler := interceptor.ToMiddleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "secret"}}}, nil
+	})
+	result, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}})
+	require.NoError(t, err)
+
+	// After interceptors see both the request and the response.
+	assert.Equal(t, "after", received.Hook)
+	assert.JSONEq(t, `{"name":"tool"}`, string(received.Request))
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"secret"}]}`, string(received.Response))
+
+	assert.Equal(t, "redacted", result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text)
+	assert.InDelta(t, 1, result.GetMeta()["redacted"], 0)
+}
+
+func TestEnvelopeInvalidDecision(t *testing.T) {
+	interceptor := Interceptor{
+		When:     "before",
+		Type:     "http",
+		Protocol: ProtocolV1,
+		Argument: decider(t, `{"decision":"maybe"}`, &envelope{}),
+	}
+	handler := interceptor.ToMiddleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+
+	_, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}})
+	require.ErrorContains(t, err, "invalid interceptor decision")
+
+	interceptor.FailOpen = true
+	_, err = interceptor.ToMiddleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	})(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileInterceptor is an interceptor, as described in a YAML interceptors file.
+type fileInterceptor struct {
+	When     string        `yaml:"when"`
+	Type     string        `yaml:"type"`
+	Argument string        `yaml:"argument"`
+	Methods  []string      `yaml:"methods"`
+	Servers  []string      `yaml:"servers"`
+	Tools    []string      `yaml:"tools"`
+	Priority int           `yaml:"priority"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Fail     string        `yaml:"fail"`
+	Protocol string        `yaml:"protocol"`
+}
+
+// ReadFile reads the interceptors listed in a YAML file.
+func ReadFile(path string) ([]Interceptor, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []fileInterceptor
+	if err := yaml.Unmarshal(buf, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var interceptors []Interceptor
+	for n, entry := range entries {
+		failOpen, err := parseFailMode(entry.Fail)
+		if err != nil {
+			return nil, fmt.Errorf("interceptor #%d in %s: %w", n+1, path, err)
+		}
+
+		interceptor := Interceptor{
+			When:     strings.ToLower(entry.When),
+			Type:     strings.ToLower(entry.Type),
+			Argument: entry.Argument,
+			Methods:  entry.Methods,
+			Servers:  entry.Servers,
+			Tools:    entry.Tools,
+			Priority: entry.Priority,
+			Timeout:  entry.Timeout,
+			FailOpen: failOpen,
+			Protocol: entry.Protocol,
+		}
+		if err := interceptor.validate(); err != nil {
+			return nil, fmt.Errorf("interceptor #%d in %s: %w", n+1, path, err)
+		}
+		interceptors = append(interceptors, interceptor)
+	}
+
+	return interceptors, nil
+}
@@ -13,3 +13,28 @@ func argumentsToString(args any) string {
 
 	return string(buf)
 }
+
+// mapStrings replaces the strings of a decoded JSON value.
+func mapStrings(value any, f func(string) (string, error)) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return f(v)
+	case []any:
+		for i := range v {
+			mapped, err := mapStrings(v[i], f)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = mapped
+		}
+	case map[string]any:
+		for key := range v {
+			mapped, err := mapStrings(v[key], f)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = mapped
+		}
+	}
+	return value, nil
+}
@@ -2,14 +2,20 @@ package interceptors
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/shlex"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -17,11 +23,21 @@ import (
 	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/logs"
 )
 
-func Callbacks(
-	logCalls, blockSecrets bool,
-	oauthInterceptorEnabled bool,
-	interceptors []Interceptor,
-) []mcp.Middleware {
+// CallbackOptions configure the middlewares of the gateway. The middlewares with nil options are
+// left out.
+type CallbackOptions struct {
+	LogCalls bool
+	// Secrets is nil to let the secrets through.
+	Secrets                 *SecretsOptions
+	PersonalData            *PIIOptions
+	PromptInjection         *PromptInjectionOptions
+	ResultSize              *ResultSizeOptions
+	OAuthInterceptorEnabled bool
+	Interceptors            []Interceptor
+}
+
+// Callbacks returns the middlewares of the gateway.
+func Callbacks(options CallbackOptions) []mcp.Middleware {
 	var middleware []mcp.Middleware
 
 	// Add telemetry middleware (always enabled)
@@ -29,23 +45,42 @@ func Callbacks(
 
 	// Add GitHub unauthorized interceptor only if the feature is enabled
 	// This ensures GitHub 401 responses are handled with OAuth links when requested
-	if oauthInterceptorEnabled {
+	if options.OAuthInterceptorEnabled {
 		middleware = append(middleware, GitHubUnauthorizedMiddleware())
 	}
 
-	// Add custom interceptors
+	// Add custom interceptors, the lowest priorities first
+	interceptors := slices.Clone(options.Interceptors)
+	slices.SortStableFunc(interceptors, func(a, b Interceptor) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
 	for _, interceptor := range interceptors {
 		middleware = append(middleware, interceptor.ToMiddleware())
 	}
 
 	// Add log calls middleware
-	if logCalls {
+	if options.LogCalls {
 		middleware = append(middleware, LogCallsMiddleware())
 	}
 
+	// Add result size middleware, which sees the results once the middlewares below changed them
+	if options.ResultSize != nil {
+		middleware = append(middleware, ResultSizeMiddleware(*options.ResultSize))
+	}
+
 	// Add block secrets middleware
-	if blockSecrets {
-		middleware = append(middleware, BlockSecretsMiddleware())
+	if options.Secrets != nil {
+		middleware = append(middleware, SecretsMiddleware(*options.Secrets))
+	}
+
+	// Add personal data middleware
+	if options.PersonalData != nil {
+		middleware = append(middleware, PIIMiddleware(*options.PersonalData))
+	}
+
+	// Add prompt injection middleware
+	if options.PromptInjection != nil {
+		middleware = append(middleware, PromptInjectionMiddleware(*options.PromptInjection))
 	}
 
 	return middleware
@@ -55,11 +90,42 @@ type Interceptor struct {
 	When     string
 	Type     string
 	Argument string
+
+	// Methods are the MCP methods intercepted. Defaults to tools/call.
+	Methods []string
+	// Servers and Tools are glob patterns restricting the interceptor to some servers and some tools.
+	// tools/list isn't sent to a particular server, so they don't apply to it.
+	Servers []string
+	Tools   []string
+	// Priority orders the interceptors: the lower the priority, the closer to the client.
+	Priority int
+	// Timeout limits each run of the interceptor.
+	Timeout time.Duration
+	// FailOpen lets the request through when the interceptor fails, instead of failing it.
+	FailOpen bool
+	// Protocol is either empty, to exchange the raw requests and responses, or ProtocolV1, to
+	// exchange envelopes and decisions.
+	Protocol string
+
+	// plugin runs the plugin interceptors, once started.
+	plugin *plugin
 }
 
+// waitDelay is how long to wait for the children of a killed interceptor to close its output.
+const waitDelay = time.Second
+
+// Types of interceptors.
+var types = []string{"exec", "docker", "http", "plugin", "docker-plugin"}
+
+// Methods that can be intercepted.
+var methods = []string{"tools/call", "tools/list", "resources/read", "prompts/get"}
+
 // --interceptor=before:exec:/bin/path
 // --interceptor=after:docker:image
 // --interceptor=around:http:localhost:8080/url
+// --interceptor=before:plugin:/bin/guardrails
+// --interceptor=after:docker-plugin:image
+// --interceptor=after[method=resources/read,server=github*,timeout=5s,fail=open]:http:localhost:8080/url
 func Parse(specs []string) ([]Interceptor, error) {
 	var interceptors []Interceptor
 
@@ -67,44 +133,208 @@ func Parse(specs []string) ([]Interceptor, error) {
 		parts := strings.SplitN(spec, ":", 3)
 		if len(parts) != 3 {
 			return nil, fmt.Errorf(
-				"invalid interceptor spec '%s', expected format is 'when:type:path'",
+				"invalid interceptor spec '%s', expected format is 'when[options]:type:path'",
 				spec,
 			)
 		}
 
-		w := strings.ToLower(parts[0])
-		if w != "before" && w != "after" {
-			return nil, fmt.Errorf(
-				"invalid interceptor when: '%s', expected 'before' or 'after''",
-				w,
-			)
+		// The options keep their case: the server and tool patterns are case sensitive.
+		when, options, hasOptions := strings.Cut(parts[0], "[")
+		interceptor := Interceptor{
+			When:     strings.ToLower(when),
+			Type:     strings.ToLower(parts[1]),
+			Argument: parts[2],
+		}
+		if hasOptions {
+			if err := interceptor.parseOptions(options); err != nil {
+				return nil, fmt.Errorf("invalid interceptor spec '%s': %w", spec, err)
+			}
+		}
+		if err := interceptor.validate(); err != nil {
+			return nil, err
 		}
 
-		t := strings.ToLower(parts[1])
-		if t != "exec" && t != "docker" && t != "http" {
-			return nil, fmt.Errorf(
-				"invalid interceptor type: '%s', expected 'exec', 'docker', or 'http'",
-				t,
+		interceptors = append(interceptors, interceptor)
+	}
+
+	return interceptors, nil
+}
+
+// parseOptions parses the comma separated key=value options of a spec, e.g.
+// method=resources/read,server=github*,tool=get_*,priority=10,timeout=5s,fail=open,protocol=v1.
+// method, server and tool can be repeated.
+func (i *Interceptor) parseOptions(options string) error {
+	options, found := strings.CutSuffix(options, "]")
+	if !found {
+		return errors.New("missing closing ']'")
+	}
+
+	for option := range strings.SplitSeq(options, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(option), "=")
+		if !found {
+			return fmt.Errorf("invalid option '%s', expected key=value", option)
+		}
+		key = strings.ToLower(key)
+
+		switch key {
+		case "method":
+			i.Methods = append(i.Methods, value)
+		case "server":
+			i.Servers = append(i.Servers, value)
+		case "tool":
+			i.Tools = append(i.Tools, value)
+		case "priority":
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid priority '%s'", value)
+			}
+			i.Priority = priority
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid timeout '%s'", value)
+			}
+			i.Timeout = timeout
+		case "protocol":
+			i.Protocol = strings.ToLower(value)
+		case "fail":
+			failOpen, err := parseFailMode(strings.ToLower(value))
+			if err != nil {
+				return err
+			}
+			i.FailOpen = failOpen
+		default:
+			return fmt.Errorf("unknown option '%s'", key)
+		}
+	}
+
+	return nil
+}
+
+func parseFailMode(mode string) (bool, error) {
+	switch mode {
+	case "", "closed":
+		return false, nil
+	case "open":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid fail mode '%s', expected 'open' or 'closed'", mode)
+}
+
+func (i *Interceptor) validate() error {
+	if i.When != "before" && i.When != "after" {
+		return fmt.Errorf(
+			"invalid interceptor when: '%s', expected 'before' or 'after''",
+			i.When,
+		)
+	}
+
+	if !slices.Contains(types, i.Type) {
+		return fmt.Errorf(
+			"invalid interceptor type: '%s', expected 'exec', 'docker', 'http', 'plugin' or 'docker-plugin'",
+			i.Type,
+		)
+	}
+
+	for _, method := range i.Methods {
+		if !slices.Contains(methods, method) {
+			return fmt.Errorf(
+				"invalid interceptor method: '%s', expected one of %s",
+				method,
+				strings.Join(methods, ", "),
 			)
 		}
+	}
 
-		interceptors = append(interceptors, Interceptor{
-			When:     w,
-			Type:     t,
-			Argument: parts[2],
-		})
+	for _, pattern := range slices.Concat(i.Servers, i.Tools) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid interceptor pattern: '%s'", pattern)
+		}
 	}
 
-	return interceptors, nil
+	if i.Protocol != "" && i.Protocol != ProtocolV1 {
+		return fmt.Errorf("invalid interceptor protocol: '%s', expected '%s'", i.Protocol, ProtocolV1)
+	}
+
+	if i.Timeout < 0 {
+		return fmt.Errorf("invalid interceptor timeout: %s", i.Timeout)
+	}
+
+	return nil
+}
+
+// matches tells whether the interceptor applies to a request.
+func (i *Interceptor) matches(ctx context.Context, method string, req mcp.Request) bool {
+	if len(i.Methods) == 0 {
+		if method != "tools/call" {
+			return false
+		}
+	} else if !slices.Contains(i.Methods, method) {
+		return false
+	}
+
+	if method == "tools/list" {
+		return true
+	}
+	if len(i.Servers) > 0 && !matchesAny(i.Servers, ServerName(ctx)) {
+		return false
+	}
+	if len(i.Tools) > 0 && method == "tools/call" {
+		var tool string
+		if params, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && params != nil {
+			tool = params.Name
+		}
+		if !matchesAny(i.Tools, tool) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type serverNameKey struct{}
+
+// WithServerName tells the interceptors which server a request is sent to.
+func WithServerName(ctx context.Context, serverName string) context.Context {
+	return context.WithValue(ctx, serverNameKey{}, serverName)
+}
+
+// ServerName returns the server a request is sent to, if known.
+func ServerName(ctx context.Context) string {
+	serverName, _ := ctx.Value(serverNameKey{}).(string)
+	return serverName
+}
+
+type toolAnnotationsKey struct{}
+
+// WithToolAnnotations tells the interceptors the annotations of the tool a request calls.
+func WithToolAnnotations(ctx context.Context, annotations *mcp.ToolAnnotations) context.Context {
+	return context.WithValue(ctx, toolAnnotationsKey{}, annotations)
+}
+
+// ToolAnnotations returns the annotations of the tool a request calls, if known.
+func ToolAnnotations(ctx context.Context) *mcp.ToolAnnotations {
+	annotations, _ := ctx.Value(toolAnnotationsKey{}).(*mcp.ToolAnnotations)
+	return annotations
 }
 
 func (i *Interceptor) ToMiddleware() mcp.Middleware {
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
-			// Only intercept tools/call method
-			if method != "tools/call" {
+			if !i.matches(ctx, method, req) {
 				return next(ctx, method, req)
 			}
+			if i.Protocol == ProtocolV1 {
+				return i.interceptEnvelope(ctx, method, req, next)
+			}
 
 			if i.When == "before" {
 				message, err := json.Marshal(req)
@@ -112,22 +342,17 @@ func (i *Interceptor) ToMiddleware() mcp.Middleware {
 					return nil, fmt.Errorf("marshalling request: %w", err)
 				}
 
-				out, err := i.run(ctx, message)
-				if err != nil {
-					return nil, fmt.Errorf("executing interceptor: %w", err)
-				}
-
 				// If the interceptor returns a response, we use it instead of calling the next handler.
-				if len(out) > 0 {
-					var result mcp.CallToolResult
-					if err := json.Unmarshal(out, &result); err != nil {
-						return nil, fmt.Errorf("unmarshalling interceptor response: %w", err)
-					}
-					return &result, nil
+				result, err := i.intercept(ctx, method, message)
+				if err != nil || result != nil {
+					return result, err
 				}
 			}
 
 			response, err := next(ctx, method, req)
+			if err != nil {
+				return nil, err
+			}
 
 			if i.When == "after" {
 				message, err := json.Marshal(response)
@@ -135,28 +360,81 @@ func (i *Interceptor) ToMiddleware() mcp.Middleware {
 					return nil, fmt.Errorf("marshalling response: %w", err)
 				}
 
-				out, err := i.run(ctx, message)
-				if err != nil {
-					return nil, fmt.Errorf("executing interceptor: %w", err)
-				}
-
 				// If the interceptor returns a response, we use it instead.
-				if len(out) > 0 {
-					var result mcp.CallToolResult
-					if err := json.Unmarshal(out, &result); err != nil {
-						return nil, fmt.Errorf("unmarshalling interceptor response: %w", err)
-					}
-					return &result, nil
+				result, err := i.intercept(ctx, method, message)
+				if err != nil || result != nil {
+					return result, err
 				}
 			}
 
-			return response, err
+			return response, nil
 		}
 	}
 }
 
-func (i *Interceptor) run(ctx context.Context, message []byte) ([]byte, error) {
+// intercept runs the interceptor and decodes its response, if any.
+func (i *Interceptor) intercept(ctx context.Context, method string, message []byte) (mcp.Result, error) {
+	out, err := i.call(ctx, method, message)
+	if err == nil {
+		var result mcp.Result
+		if result, err = decodeResult(method, out); err == nil {
+			return result, nil
+		}
+	}
+	return nil, i.failed(method, err)
+}
+
+// call runs the interceptor, within its timeout.
+func (i *Interceptor) call(ctx context.Context, method string, message []byte) ([]byte, error) {
+	if i.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.Timeout)
+		defer cancel()
+	}
+
+	out, err := i.run(ctx, method, message)
+	if err != nil {
+		return nil, fmt.Errorf("executing interceptor: %w", err)
+	}
+	return out, nil
+}
+
+// failed returns the error to fail the request with, if the interceptor doesn't fail open.
+func (i *Interceptor) failed(method string, err error) error {
+	if i.FailOpen {
+		logf("! Interceptor %s:%s failed, letting %s through: %s", i.Type, i.Argument, method, err)
+		return nil
+	}
+	return err
+}
+
+// decodeResult decodes the result of a method, if any.
+func decodeResult(method string, out []byte) (mcp.Result, error) {
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var result mcp.Result
+	switch method {
+	case "tools/list":
+		result = &mcp.ListToolsResult{}
+	case "resources/read":
+		result = &mcp.ReadResourceResult{}
+	case "prompts/get":
+		result = &mcp.GetPromptResult{}
+	default:
+		result = &mcp.CallToolResult{}
+	}
+	if err := json.Unmarshal(out, result); err != nil {
+		return nil, fmt.Errorf("unmarshalling interceptor response: %w", err)
+	}
+	return result, nil
+}
+
+func (i *Interceptor) run(ctx context.Context, method string, message []byte) ([]byte, error) {
 	switch i.Type {
+	case "plugin", "docker-plugin":
+		return i.runPlugin(ctx, method, message)
 	case "exec":
 		return i.runExec(ctx, message)
 	case "docker":
@@ -172,6 +450,7 @@ func (i *Interceptor) runExec(ctx context.Context, message []byte) ([]byte, erro
 	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", i.Argument)
 	cmd.Stdin = bytes.NewBuffer(message)
 	cmd.Stderr = logs.NewPrefixer(os.Stderr, "  - ")
+	cmd.WaitDelay = waitDelay
 	return cmd.Output()
 }
 
@@ -190,6 +469,7 @@ func (i *Interceptor) runDocker(ctx context.Context, message []byte) ([]byte, er
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Stdin = bytes.NewBuffer(message)
 	cmd.Stderr = logs.NewPrefixer(os.Stderr, "  - ")
+	cmd.WaitDelay = waitDelay
 	return cmd.Output()
 }
 
@@ -2,7 +2,11 @@ package interceptors
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +22,7 @@ func TestCallbacksWithOAuthInterceptorEnabled(t *testing.T) {
 	defer func() { getGitHubOAuthURL = oldGetOAuthURL }()
 
 	// When oauth-interceptor is enabled
-	middlewares := Callbacks(false, false, true, nil)
+	middlewares := Callbacks(CallbackOptions{OAuthInterceptorEnabled: true})
 
 	// Should have telemetry middleware + GitHub interceptor
 	assert.Len(t, middlewares, 2, "should have telemetry and GitHub interceptor when enabled")
@@ -51,7 +55,7 @@ func TestCallbacksWithOAuthInterceptorEnabled(t *testing.T) {
 
 func TestCallbacksWithOAuthInterceptorDisabled(t *testing.T) {
 	// When oauth-interceptor is disabled
-	middlewares := Callbacks(false, false, false, nil)
+	middlewares := Callbacks(CallbackOptions{})
 
 	// Should only have telemetry middleware, no GitHub interceptor
 	assert.Len(t, middlewares, 1, "should only have telemetry middleware when oauth disabled")
@@ -84,7 +88,7 @@ func TestCallbacksEndToEndWithFeatureToggle(t *testing.T) {
 
 		mockHandler := createMockHandler()
 
-		middlewares := Callbacks(false, false, true, nil) // OAuth enabled
+		middlewares := Callbacks(CallbackOptions{OAuthInterceptorEnabled: true})
 		require.NotEmpty(t, middlewares)
 
 		wrappedHandler := middlewares[1](mockHandler)
@@ -104,7 +108,7 @@ func TestCallbacksEndToEndWithFeatureToggle(t *testing.T) {
 	t.Run("with feature disabled - should pass through", func(t *testing.T) {
 		mockHandler := createMockHandler()
 
-		middlewares := Callbacks(false, false, false, nil) // OAuth disabled
+		middlewares := Callbacks(CallbackOptions{})
 
 		// No middleware means the handler runs unchanged
 		if len(middlewares) == 0 {
@@ -139,7 +143,7 @@ func TestOAuthInterceptorIntegration(t *testing.T) {
 		}
 
 		// Get middlewares with OAuth enabled
-		middlewares := Callbacks(true, true, true, nil) // logCalls, blockSecrets, oauthEnabled
+		middlewares := Callbacks(CallbackOptions{LogCalls: true, Secrets: &SecretsOptions{}, OAuthInterceptorEnabled: true})
 
 		// Apply all middlewares
 		handler := baseHandler
@@ -172,7 +176,7 @@ func TestOAuthInterceptorIntegration(t *testing.T) {
 		}
 
 		// Get middlewares with OAuth disabled
-		middlewares := Callbacks(true, true, false, nil) // logCalls, blockSecrets, oauthDisabled
+		middlewares := Callbacks(CallbackOptions{LogCalls: true, Secrets: &SecretsOptions{}})
 
 		// Apply all middlewares (OAuth interceptor won't be in the chain)
 		handler := baseHandler
@@ -200,7 +204,7 @@ func TestCallbacksOAuthInterceptorWithOtherMiddleware(t *testing.T) {
 	// Test that OAuth interceptor plays nicely with other middleware
 
 	// With OAuth enabled and logCalls enabled
-	middlewares := Callbacks(true, false, true, nil)
+	middlewares := Callbacks(CallbackOptions{LogCalls: true, OAuthInterceptorEnabled: true})
 	assert.Len(
 		t,
 		middlewares,
@@ -209,6 +213,174 @@ func TestCallbacksOAuthInterceptorWithOtherMiddleware(t *testing.T) {
 	)
 
 	// With OAuth disabled but logCalls enabled
-	middlewares = Callbacks(true, false, false, nil)
+	middlewares = Callbacks(CallbackOptions{LogCalls: true})
 	assert.Len(t, middlewares, 2, "should have telemetry and log calls middleware")
 }
+
+func TestParseOptions(t *testing.T) {
+	parsed, err := Parse([]string{
+		"before:exec:/bin/path",
+		"AFTER[method=resources/read,method=prompts/get,server=github*,tool=get_*,priority=-1,timeout=5s,fail=open,protocol=v1]:http:localhost:8080/url",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Interceptor{
+		{When: "before", Type: "exec", Argument: "/bin/path"},
+		{
+			When:     "after",
+			Type:     "http",
+			Argument: "localhost:8080/url",
+			Methods:  []string{"resources/read", "prompts/get"},
+			Servers:  []string{"github*"},
+			Tools:    []string{"get_*"},
+			Priority: -1,
+			Timeout:  5 * time.Second,
+			FailOpen: true,
+			Protocol: ProtocolV1,
+		},
+	}, parsed)
+
+	for _, spec := range []string{
+		"after[method=resources/list]:exec:/bin/path",
+		"after[server=[]:exec:/bin/path",
+		"after[timeout=soon]:exec:/bin/path",
+		"after[fail=maybe]:exec:/bin/path",
+		"after[color=blue]:exec:/bin/path",
+		"after[protocol=v2]:exec:/bin/path",
+		"after[method=tools/call:exec:/bin/path",
+	} {
+		_, err := Parse([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseKeepsTheCaseOfThePatterns(t *testing.T) {
+	parsed, err := Parse([]string{"Before[tool=getIssue,server=GitHub*]:exec:/bin/path"})
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+
+	interceptor := parsed[0]
+	assert.Equal(t, "before", interceptor.When)
+	assert.Equal(t, []string{"getIssue"}, interceptor.Tools)
+	assert.Equal(t, []string{"GitHub*"}, interceptor.Servers)
+
+	request := func(tool string) mcp.Request {
+		return &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tool}}
+	}
+	ctx := WithServerName(t.Context(), "GitHub-Enterprise")
+	assert.True(t, interceptor.matches(ctx, "tools/call", request("getIssue")))
+	assert.False(t, interceptor.matches(ctx, "tools/call", request("getissue")))
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interceptors.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- when: after
+  type: http
+  argument: http://localhost:8080/redact
+  methods: [resources/read]
+  servers: [github]
+  priority: 10
+  timeout: 5s
+  fail: open
+`), 0o644))
+
+	parsed, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Interceptor{{
+		When:     "after",
+		Type:     "http",
+		Argument: "http://localhost:8080/redact",
+		Methods:  []string{"resources/read"},
+		Servers:  []string{"github"},
+		Priority: 10,
+		Timeout:  5 * time.Second,
+		FailOpen: true,
+	}}, parsed)
+
+	require.NoError(t, os.WriteFile(path, []byte("- when: during\n  type: exec\n"), 0o644))
+	_, err = ReadFile(path)
+	assert.Error(t, err)
+}
+
+func TestInterceptorMatchers(t *testing.T) {
+	replace := `echo '{"contents":[{"uri":"file:///x","text":"intercepted"}]}'`
+	interceptor := Interceptor{
+		When:     "after",
+		Type:     "exec",
+		Argument: replace,
+		Methods:  []string{"resources/read"},
+		Servers:  []string{"git*"},
+	}
+	next := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: "file:///x", Text: "original"}}}, nil
+	}
+	handler := interceptor.ToMiddleware()(next)
+
+	read := func(ctx context.Context, method string) string {
+		result, err := handler(ctx, method, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "file:///x"}})
+		require.NoError(t, err)
+		return result.(*mcp.ReadResourceResult).Contents[0].Text
+	}
+	assert.Equal(t, "intercepted", read(WithServerName(t.Context(), "github"), "resources/read"))
+	assert.Equal(t, "original", read(WithServerName(t.Context(), "fetch"), "resources/read"))
+	assert.Equal(t, "original", read(t.Context(), "resources/read"))
+
+	// Tools are matched by name.
+	interceptor = Interceptor{When: "before", Type: "exec", Argument: `echo '{"content":[]}'`, Tools: []string{"get_*"}}
+	called := false
+	handler = interceptor.ToMiddleware()(func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		called = true
+		return &mcp.CallToolResult{}, nil
+	})
+	call := func(name string) {
+		_, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name}})
+		require.NoError(t, err)
+	}
+	call("get_issue")
+	assert.False(t, called)
+	call("create_issue")
+	assert.True(t, called)
+}
+
+func TestInterceptorFailureModes(t *testing.T) {
+	next := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "original"}}}, nil
+	}
+	request := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool", Arguments: json.RawMessage("{}")}}
+
+	failing := Interceptor{When: "after", Type: "exec", Argument: "exit 1"}
+	_, err := failing.ToMiddleware()(next)(t.Context(), "tools/call", request)
+	require.Error(t, err)
+
+	failing.FailOpen = true
+	result, err := failing.ToMiddleware()(next)(t.Context(), "tools/call", request)
+	require.NoError(t, err)
+	assert.Equal(t, "original", result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text)
+
+	slow := Interceptor{When: "before", Type: "exec", Argument: "sleep 10", Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err = slow.ToMiddleware()(next)(t.Context(), "tools/call", request)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCallbacksOrderInterceptorsByPriority(t *testing.T) {
+	middlewares := Callbacks(CallbackOptions{Interceptors: []Interceptor{
+		{When: "after", Type: "exec", Argument: `echo '{"content":[{"type":"text","text":"last"}]}'`, Priority: 10},
+		{When: "after", Type: "exec", Argument: `echo '{"content":[{"type":"text","text":"first"}]}'`, Priority: 1},
+	}})
+	require.Len(t, middlewares, 3)
+
+	handler := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	// The lowest priority is the closest to the client, so its response wins.
+	result, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}})
+	require.NoError(t, err)
+	assert.Equal(t, "first", result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text)
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/auth"
 )
 
 func LogCallsMiddleware() mcp.Middleware {
@@ -27,10 +29,16 @@ func LogCallsMiddleware() mcp.Middleware {
 				arguments = callReq.Params.Arguments
 			}
 
+			var caller string
+			if identity := auth.FromRequest(req); identity != nil {
+				caller = " for " + identity.String()
+			}
+
 			if toolName != "" {
 				logf(
-					"  - Calling tool %s with arguments: %s\n",
+					"  - Calling tool %s%s with arguments: %s\n",
 					toolName,
+					caller,
 					argumentsToString(arguments),
 				)
 			} else {
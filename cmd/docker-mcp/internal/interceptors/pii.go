@@ -0,0 +1,264 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/pii"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+// PIIOptions configure how the personal data sent to and returned by the servers is handled.
+type PIIOptions struct {
+	Scanner  *pii.Scanner
+	Policies pii.Policies
+	// Vault keeps the tokenized personal data. Defaults to a new vault.
+	Vault *pii.Vault
+	// Audit receives a JSON line for each request or response with personal data.
+	Audit io.Writer
+}
+
+// piiAuditRecord is a record of the personal data seen in a request or a response.
+type piiAuditRecord struct {
+	Time       time.Time                   `json:"time"`
+	Session    string                      `json:"session,omitempty"`
+	Server     string                      `json:"server,omitempty"`
+	Method     string                      `json:"method"`
+	Name       string                      `json:"name,omitempty"`
+	Direction  string                      `json:"direction"`
+	Categories map[pii.Category]int        `json:"categories"`
+	Actions    map[pii.Category]pii.Action `json:"actions"`
+}
+
+// errPIIBlocked is returned when personal data is blocked.
+var errPIIBlocked = errors.New("personal data is blocked")
+
+// PIIMiddleware finds the personal data in the arguments and the results of the tools, resources
+// and prompts, and allows, masks, tokenizes or blocks it according to the policies of the servers.
+func PIIMiddleware(options PIIOptions) mcp.Middleware {
+	if options.Vault == nil {
+		options.Vault = pii.NewVault()
+	}
+	auditMu := &sync.Mutex{}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" && method != "resources/read" && method != "prompts/get" {
+				return next(ctx, method, req)
+			}
+
+			scan := &piiScan{
+				options:    &options,
+				auditMu:    auditMu,
+				serverName: ServerName(ctx),
+				method:     method,
+			}
+			if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+				scan.sessionID = ss.ID()
+			}
+
+			// Personal data sent to the servers.
+			switch params := req.GetParams().(type) {
+			case *mcp.CallToolParamsRaw:
+				if params != nil && len(params.Arguments) > 0 {
+					scan.name = params.Name
+					arguments, err := scan.applyJSON(ctx, "arguments", params.Arguments)
+					if err != nil {
+						return nil, err
+					}
+					params.Arguments = arguments
+				}
+			case *mcp.GetPromptParams:
+				if params != nil {
+					scan.name = params.Name
+					arguments := map[string]any{}
+					for key, value := range params.Arguments {
+						arguments[key] = value
+					}
+					if err := scan.apply(ctx, "arguments", arguments); err != nil {
+						return nil, err
+					}
+					for key, value := range arguments {
+						params.Arguments[key], _ = value.(string)
+					}
+				}
+			case *mcp.ReadResourceParams:
+				if params != nil {
+					scan.name = params.URI
+				}
+			}
+
+			result, err := next(ctx, method, req)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			// Personal data returned by the servers.
+			buf, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("marshalling result: %w", err)
+			}
+			applied, err := scan.applyJSON(ctx, "result", buf)
+			if err != nil {
+				return nil, err
+			}
+			if scan.changed {
+				return decodeResult(method, applied)
+			}
+			return result, nil
+		}
+	}
+}
+
+// piiScan applies the policies to one request and its response.
+type piiScan struct {
+	options    *PIIOptions
+	auditMu    *sync.Mutex
+	sessionID  string
+	serverName string
+	method     string
+	name       string
+	// changed tells whether the last value was changed.
+	changed bool
+}
+
+func (s *piiScan) applyJSON(
+	ctx context.Context,
+	direction string,
+	document json.RawMessage,
+) (json.RawMessage, error) {
+	var value any
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, direction, value); err != nil {
+		return nil, err
+	}
+	if !s.changed {
+		return document, nil
+	}
+	return json.Marshal(value)
+}
+
+// apply applies the policies to the strings of a decoded JSON object or array, in place.
+func (s *piiScan) apply(ctx context.Context, direction string, value any) error {
+	s.changed = false
+	categories := map[pii.Category]int{}
+	actions := map[pii.Category]pii.Action{}
+
+	_, err := mapStrings(value, func(text string) (string, error) {
+		findings := s.options.Scanner.Find(text)
+
+		var mapped strings.Builder
+		last := 0
+		for _, finding := range findings {
+			action := s.options.Policies.Action(s.serverName, finding.Category)
+			categories[finding.Category]++
+			actions[finding.Category] = action
+
+			var replacement string
+			switch {
+			case action == pii.Block:
+				return "", errPIIBlocked
+			case action == pii.Mask:
+				replacement = pii.MaskOf(finding.Category)
+			case action == pii.Tokenize && direction == "result":
+				value := text[finding.Start:finding.End]
+				replacement = s.options.Vault.Tokenize(s.sessionID, finding.Category, value)
+			default:
+				continue
+			}
+			mapped.WriteString(text[last:finding.Start])
+			mapped.WriteString(replacement)
+			last = finding.End
+		}
+		mapped.WriteString(text[last:])
+
+		result := mapped.String()
+		// The tokens sent to a server are replaced back with the data, unless the server isn't
+		// allowed to see it.
+		if direction == "arguments" {
+			result = s.options.Vault.Detokenize(s.sessionID, result, func(category pii.Category) bool {
+				action := s.options.Policies.Action(s.serverName, category)
+				return action == pii.Allow || action == pii.Tokenize
+			})
+		}
+		if result != text {
+			s.changed = true
+		}
+		return result, nil
+	})
+
+	if len(categories) > 0 {
+		s.audit(ctx, direction, categories, actions)
+	}
+	if errors.Is(err, errPIIBlocked) {
+		var blocked []string
+		for category, action := range actions {
+			if action == pii.Block {
+				blocked = append(blocked, string(category))
+			}
+		}
+		slices.Sort(blocked)
+		if direction == "arguments" {
+			return fmt.Errorf("personal data (%s) is being sent to %s",
+				strings.Join(blocked, ", "), s.target())
+		}
+		return fmt.Errorf("personal data (%s) is being returned by %s",
+			strings.Join(blocked, ", "), s.target())
+	}
+	return err
+}
+
+func (s *piiScan) target() string {
+	if s.serverName == "" {
+		return s.name
+	}
+	return s.serverName
+}
+
+// audit records the categories of personal data seen.
+func (s *piiScan) audit(
+	ctx context.Context,
+	direction string,
+	categories map[pii.Category]int,
+	actions map[pii.Category]pii.Action,
+) {
+	var seen []string
+	for _, category := range slices.Sorted(maps.Keys(categories)) {
+		seen = append(seen, fmt.Sprintf("%s=%d (%s)", category, categories[category], actions[category]))
+		telemetry.RecordPIIDetections(ctx, s.serverName, string(category), direction,
+			string(actions[category]), int64(categories[category]))
+	}
+	logf("  - Personal data in %s of %s: %s", direction, s.target(), strings.Join(seen, ", "))
+
+	if s.options.Audit == nil {
+		return
+	}
+	buf, err := json.Marshal(piiAuditRecord{
+		Time:       time.Now().UTC(),
+		Session:    s.sessionID,
+		Server:     s.serverName,
+		Method:     s.method,
+		Name:       s.name,
+		Direction:  direction,
+		Categories: categories,
+		Actions:    actions,
+	})
+	if err != nil {
+		return
+	}
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	_, _ = s.options.Audit.Write(append(buf, '\n'))
+}
@@ -0,0 +1,141 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/pii"
+)
+
+func piiOptions(t *testing.T, specs ...string) PIIOptions {
+	t.Helper()
+
+	scanner, err := pii.NewScanner(nil)
+	require.NoError(t, err)
+
+	options := PIIOptions{Scanner: scanner, Vault: pii.NewVault()}
+	for _, spec := range specs {
+		policy, err := pii.ParsePolicy(spec)
+		require.NoError(t, err)
+		options.Policies = append(options.Policies, policy)
+	}
+	return options
+}
+
+// callWithPII calls a tool of a server that returns some text, and returns the arguments the server
+// received and the text it returned.
+func callWithPII(t *testing.T, middleware mcp.Middleware, serverName, arguments, text string) (string, string, error) {
+	t.Helper()
+
+	var received string
+	handler := middleware(func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		received = string(req.(*mcp.CallToolRequest).Params.Arguments)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+	})
+
+	result, err := handler(WithServerName(t.Context(), serverName), "tools/call", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "tool", Arguments: json.RawMessage(arguments)},
+	})
+	if err != nil {
+		return received, "", err
+	}
+	return received, result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text, nil
+}
+
+func TestPIIAllowedByDefault(t *testing.T) {
+	middleware := PIIMiddleware(piiOptions(t))
+
+	arguments, text, err := callWithPII(t, middleware, "crm", `{"to":"jane@example.com"}`, "card 4111 1111 1111 1111")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"to":"jane@example.com"}`, arguments)
+	assert.Equal(t, "card 4111 1111 1111 1111", text)
+}
+
+func TestPIIMasked(t *testing.T) {
+	middleware := PIIMiddleware(piiOptions(t, "crm:email=mask"))
+
+	arguments, text, err := callWithPII(t, middleware, "crm", `{"to":"jane@example.com"}`, "from bob@example.com, +33 6 12 34 56 78")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"to":"[EMAIL]"}`, arguments)
+	assert.Equal(t, "from [EMAIL], +33 6 12 34 56 78", text)
+}
+
+func TestPIIBlocked(t *testing.T) {
+	middleware := PIIMiddleware(piiOptions(t, "crm:credit-card=block"))
+
+	_, _, err := callWithPII(t, middleware, "crm", `{"card":"4111 1111 1111 1111"}`, "")
+	require.ErrorContains(t, err, "personal data (credit-card) is being sent to crm")
+
+	_, _, err = callWithPII(t, middleware, "crm", `{}`, "card 4111 1111 1111 1111")
+	require.ErrorContains(t, err, "personal data (credit-card) is being returned by crm")
+}
+
+func TestPIITokenized(t *testing.T) {
+	middleware := PIIMiddleware(piiOptions(t, "crm=tokenize", "fetch=mask"))
+
+	_, text, err := callWithPII(t, middleware, "crm", `{}`, "customer jane@example.com")
+	require.NoError(t, err)
+	assert.Regexp(t, `^customer \[EMAIL:[0-9a-f]{8}\]$`, text)
+	token := text[len("customer "):]
+
+	// The token is replaced back with the data for the servers allowed to see it.
+	arguments, _, err := callWithPII(t, middleware, "mailer", `{"to":"`+token+`"}`, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"to":"jane@example.com"}`, arguments)
+
+	arguments, _, err = callWithPII(t, middleware, "fetch", `{"to":"`+token+`"}`, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"to":"`+token+`"}`, arguments)
+}
+
+func TestPIIAudit(t *testing.T) {
+	var audit bytes.Buffer
+	options := piiOptions(t, "crm:phone=mask")
+	options.Audit = &audit
+
+	_, _, err := callWithPII(t, PIIMiddleware(options), "crm", `{"to":"jane@example.com"}`, "call +33 6 12 34 56 78")
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(audit.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var record piiAuditRecord
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "crm", record.Server)
+	assert.Equal(t, "tools/call", record.Method)
+	assert.Equal(t, "tool", record.Name)
+	assert.Equal(t, "arguments", record.Direction)
+	assert.Equal(t, map[pii.Category]int{pii.Email: 1}, record.Categories)
+	assert.Equal(t, map[pii.Category]pii.Action{pii.Email: pii.Allow}, record.Actions)
+
+	record = piiAuditRecord{}
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.Equal(t, "result", record.Direction)
+	assert.Equal(t, map[pii.Category]pii.Action{pii.Phone: pii.Mask}, record.Actions)
+	assert.NotContains(t, audit.String(), "jane@example.com")
+}
+
+func TestPIIInPrompts(t *testing.T) {
+	middleware := PIIMiddleware(piiOptions(t, "*=mask"))
+
+	var received map[string]string
+	handler := middleware(func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		received = req.(*mcp.GetPromptRequest).Params.Arguments
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: "write to bob@example.com"}},
+		}}, nil
+	})
+
+	result, err := handler(t.Context(), "prompts/get", &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{Name: "email", Arguments: map[string]string{"to": "jane@example.com"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"to": "[EMAIL]"}, received)
+	assert.Equal(t, "write to [EMAIL]", result.(*mcp.GetPromptResult).Messages[0].Content.(*mcp.TextContent).Text)
+}
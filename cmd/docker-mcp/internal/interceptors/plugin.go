@@ -0,0 +1,357 @@
+package interceptors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/shlex"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/logs"
+)
+
+// Plugins are long-running interceptors, started once with the gateway. A plugin is a process
+// (type plugin) or a container (type docker-plugin) speaking JSON-RPC 2.0 over stdio, one message
+// per line. The gateway sends:
+//
+//	{"jsonrpc":"2.0","id":1,"method":"before","params":{"method":"tools/call","server":"github","message":{...}}}
+//	{"jsonrpc":"2.0","id":2,"method":"after","params":{"method":"tools/call","server":"github","message":{...}}}
+//	{"jsonrpc":"2.0","id":3,"method":"health"}
+//
+// before receives the request and after the response. Both return {"message":{...}} to replace the
+// response, or {} to let it through. An error fails the request, unless the interceptor fails open.
+// Plugins that stop, or don't answer health checks, are restarted.
+
+const (
+	pluginHealthInterval  = 10 * time.Second
+	pluginHealthTimeout   = 5 * time.Second
+	pluginStopTimeout     = 10 * time.Second
+	pluginMaxRestartDelay = 30 * time.Second
+)
+
+var errPluginStopped = errors.New("plugin is not running")
+
+type Plugins struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartPlugins starts the plugins used by the interceptors, once for all the interceptors with the
+// same type and argument, and connects the interceptors to them.
+func StartPlugins(ctx context.Context, interceptors []Interceptor) (*Plugins, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	plugins := &Plugins{cancel: cancel}
+
+	byKey := map[string]*plugin{}
+	for n := range interceptors {
+		interceptor := &interceptors[n]
+		if !interceptor.isPlugin() {
+			continue
+		}
+
+		key := interceptor.Type + ":" + interceptor.Argument
+		p, found := byKey[key]
+		if !found {
+			p = &plugin{kind: interceptor.Type, argument: interceptor.Argument}
+			if err := p.start(ctx); err != nil {
+				plugins.Close()
+				return nil, fmt.Errorf("starting interceptor plugin %s: %w", key, err)
+			}
+			byKey[key] = p
+
+			plugins.wg.Add(1)
+			go func() {
+				defer plugins.wg.Done()
+				p.supervise(ctx)
+			}()
+		}
+		interceptor.plugin = p
+	}
+
+	return plugins, nil
+}
+
+// Close stops the plugins.
+func (p *Plugins) Close() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (i *Interceptor) isPlugin() bool {
+	return i.Type == "plugin" || i.Type == "docker-plugin"
+}
+
+type pluginHookParams struct {
+	Method  string          `json:"method"`
+	Server  string          `json:"server,omitempty"`
+	Message json.RawMessage `json:"message"`
+}
+
+type pluginHookResult struct {
+	Message json.RawMessage `json:"message,omitempty"`
+}
+
+func (i *Interceptor) runPlugin(ctx context.Context, method string, message []byte) ([]byte, error) {
+	if i.plugin == nil {
+		return nil, errPluginStopped
+	}
+
+	// Envelopes and decisions are exchanged as they are.
+	if i.Protocol == ProtocolV1 {
+		result, err := i.plugin.call(ctx, i.When, json.RawMessage(message))
+		if err != nil || string(result) == "null" {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result, err := i.plugin.call(ctx, i.When, pluginHookParams{
+		Method:  method,
+		Server:  ServerName(ctx),
+		Message: message,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hook pluginHookResult
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &hook); err != nil {
+			return nil, fmt.Errorf("decoding plugin result: %w", err)
+		}
+	}
+	if string(hook.Message) == "null" {
+		return nil, nil
+	}
+	return hook.Message, nil
+}
+
+// plugin is a supervised plugin process.
+type plugin struct {
+	kind     string
+	argument string
+
+	mu   sync.Mutex
+	conn *pluginConn
+}
+
+func (p *plugin) current() *pluginConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conn
+}
+
+func (p *plugin) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	conn := p.current()
+	if conn == nil {
+		return nil, errPluginStopped
+	}
+	return conn.call(ctx, method, params)
+}
+
+func (p *plugin) command(ctx context.Context) (*exec.Cmd, error) {
+	if p.kind == "plugin" {
+		return exec.CommandContext(ctx, "/bin/sh", "-c", p.argument), nil
+	}
+
+	image, rest, _ := strings.Cut(p.argument, " ")
+	args := []string{"run", "--rm", "-i", "--init", image}
+	if len(rest) > 0 {
+		moreArgs, err := shlex.Split(rest)
+		if err != nil {
+			return nil, fmt.Errorf("parsing docker arguments: %w", err)
+		}
+		args = append(args, moreArgs...)
+	}
+	return exec.CommandContext(ctx, "docker", args...), nil
+}
+
+func (p *plugin) start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	cmd, err := p.command(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	// Let the plugins (and docker, which forwards the signal to the container) stop gracefully.
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = pluginStopTimeout
+	cmd.Stderr = logs.NewPrefixer(os.Stderr, "  - ")
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return err
+	}
+
+	conn := &pluginConn{
+		stop:    cancel,
+		stdin:   stdin,
+		pending: map[int64]chan pluginResponse{},
+		done:    make(chan struct{}),
+	}
+	go conn.read(cmd, stdout)
+
+	p.mu.Lock()
+	p.conn = conn
+	p.mu.Unlock()
+	return nil
+}
+
+// supervise checks the health of the plugin and restarts it when it stops.
+func (p *plugin) supervise(ctx context.Context) {
+	ticker := time.NewTicker(pluginHealthInterval)
+	defer ticker.Stop()
+	delay := time.Second
+
+	for {
+		conn := p.current()
+
+		select {
+		case <-ctx.Done():
+			<-conn.done
+			return
+
+		case <-conn.done:
+			logf("! Interceptor %s %s stopped, restarting in %s", p.kind, p.argument, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, pluginMaxRestartDelay)
+
+			if err := p.start(ctx); err != nil {
+				logf("! Can't restart interceptor %s %s: %s", p.kind, p.argument, err)
+			}
+
+		case <-ticker.C:
+			healthCtx, cancel := context.WithTimeout(ctx, pluginHealthTimeout)
+			_, err := conn.call(healthCtx, "health", nil)
+			cancel()
+
+			switch {
+			case ctx.Err() != nil:
+			case err != nil:
+				logf("! Interceptor %s %s is unhealthy: %s", p.kind, p.argument, err)
+				conn.stop()
+			default:
+				delay = time.Second
+			}
+		}
+	}
+}
+
+type pluginRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type pluginResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// pluginConn is a connection to one run of a plugin.
+type pluginConn struct {
+	stop  context.CancelFunc
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+
+	pendingMu sync.Mutex
+	pending   map[int64]chan pluginResponse
+	// done is closed once the plugin has exited.
+	done chan struct{}
+}
+
+func (c *pluginConn) read(cmd *exec.Cmd, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var response pluginResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			logf("! Invalid message from interceptor plugin: %s", err)
+			continue
+		}
+
+		c.pendingMu.Lock()
+		ch, found := c.pending[response.ID]
+		delete(c.pending, response.ID)
+		c.pendingMu.Unlock()
+		if found {
+			ch <- response
+		}
+	}
+
+	c.stop()
+	_ = cmd.Wait()
+	close(c.done)
+}
+
+func (c *pluginConn) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id := c.nextID.Add(1)
+	buf, err := json.Marshal(pluginRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan pluginResponse, 1)
+	c.pendingMu.Lock()
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	_, err = c.stdin.Write(append(buf, '\n'))
+	c.writeMu.Unlock()
+	if err != nil {
+		return nil, errPluginStopped
+	}
+
+	select {
+	case response := <-ch:
+		if response.Error != nil {
+			return nil, fmt.Errorf("plugin error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	case <-c.done:
+		return nil, errPluginStopped
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+package interceptors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPluginHelper is the plugin started by the tests, when run as a helper process.
+func TestPluginHelper(*testing.T) {
+	if os.Getenv("INTERCEPTOR_PLUGIN_HELPER") != "1" {
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var request struct {
+			ID     int64            `json:"id"`
+			Method string           `json:"method"`
+			Params pluginHookParams `json:"params"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &request)
+
+		var response string
+		switch {
+		case request.Method == "before" && request.Params.Server == "blocked":
+			response = `"error":{"code":1,"message":"blocked"}`
+		case request.Method == "after":
+			response = fmt.Sprintf(`"result":{"message":{"content":[{"type":"text","text":"%d"}]}}`, os.Getpid())
+		default:
+			response = `"result":{}`
+		}
+		fmt.Printf(`{"jsonrpc":"2.0","id":%d,%s}`+"\n", request.ID, response)
+	}
+	os.Exit(0)
+}
+
+func TestPlugins(t *testing.T) {
+	t.Setenv("INTERCEPTOR_PLUGIN_HELPER", "1")
+	command := "exec " + os.Args[0] + " -test.run=^TestPluginHelper$"
+
+	interceptors := []Interceptor{
+		{When: "before", Type: "plugin", Argument: command},
+		{When: "after", Type: "plugin", Argument: command},
+	}
+	plugins, err := StartPlugins(t.Context(), interceptors)
+	require.NoError(t, err)
+	defer plugins.Close()
+
+	// Both interceptors share the same plugin.
+	require.NotNil(t, interceptors[0].plugin)
+	assert.Same(t, interceptors[0].plugin, interceptors[1].plugin)
+
+	next := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "original"}}}, nil
+	}
+	before := interceptors[0].ToMiddleware()(next)
+	after := interceptors[1].ToMiddleware()(next)
+	request := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "tool"}}
+
+	result, err := before(t.Context(), "tools/call", request)
+	require.NoError(t, err)
+	assert.Equal(t, "original", result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text)
+
+	_, err = before(WithServerName(t.Context(), "blocked"), "tools/call", request)
+	require.ErrorContains(t, err, "blocked")
+
+	result, err = after(t.Context(), "tools/call", request)
+	require.NoError(t, err)
+	pid := result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text
+
+	// Plugins that stop are restarted.
+	interceptors[1].plugin.current().stop()
+	assert.Eventually(t, func() bool {
+		result, err := after(t.Context(), "tools/call", request)
+		return err == nil && result.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text != pid
+	}, 10*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,159 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/promptinjection"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+// PromptInjectionMetaKey is the key, in the _meta of the results, of what was found.
+const PromptInjectionMetaKey = "io.docker.mcp/prompt-injection"
+
+// PromptInjectionOptions configure how the content returned by the servers is inspected for
+// prompt injections.
+type PromptInjectionOptions struct {
+	Policies promptinjection.Policies
+}
+
+// PromptInjectionMiddleware inspects the text returned by the tools and the resources for prompt
+// injections, and annotates, strips or wraps it according to the policies of the servers.
+func PromptInjectionMiddleware(options PromptInjectionOptions) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" && method != "resources/read" {
+				return next(ctx, method, req)
+			}
+
+			serverName := ServerName(ctx)
+			mode := options.Policies.Mode(serverName)
+			if mode == promptinjection.Off {
+				return next(ctx, method, req)
+			}
+
+			result, err := next(ctx, method, req)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			result, err = typedResult(method, result)
+			if err != nil {
+				return nil, err
+			}
+
+			inspection := &injectionInspection{
+				mode:       mode,
+				serverName: serverName,
+				counts:     map[string]int{},
+			}
+			switch result := result.(type) {
+			case *mcp.CallToolResult:
+				for _, content := range result.Content {
+					switch c := content.(type) {
+					case *mcp.TextContent:
+						c.Text = inspection.inspect(c.Text)
+					case *mcp.EmbeddedResource:
+						if c.Resource != nil && c.Resource.Text != "" {
+							c.Resource.Text = inspection.inspect(c.Resource.Text)
+						}
+					}
+				}
+				if result.StructuredContent != nil {
+					structured, err := inspection.inspectStructured(result.StructuredContent)
+					if err != nil {
+						return nil, err
+					}
+					result.StructuredContent = structured
+				}
+			case *mcp.ReadResourceResult:
+				for _, contents := range result.Contents {
+					if contents != nil && contents.Text != "" {
+						contents.Text = inspection.inspect(contents.Text)
+					}
+				}
+			}
+
+			if len(inspection.counts) == 0 {
+				return result, nil
+			}
+
+			names := slices.Sorted(maps.Keys(inspection.counts))
+			logf("  ! Possible prompt injection returned by %s (%s): %s",
+				serverName, mode, strings.Join(names, ", "))
+			for _, name := range names {
+				telemetry.RecordPromptInjections(ctx, serverName, name, string(mode),
+					int64(inspection.counts[name]))
+			}
+			return withMeta(result, map[string]any{
+				PromptInjectionMetaKey: map[string]any{
+					"mode":     mode,
+					"findings": inspection.counts,
+				},
+			}), nil
+		}
+	}
+}
+
+// typedResult returns a result as the type of result of a method, if it's not already.
+func typedResult(method string, result mcp.Result) (mcp.Result, error) {
+	switch result.(type) {
+	case *mcp.CallToolResult, *mcp.ReadResourceResult:
+		return result, nil
+	}
+
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling result: %w", err)
+	}
+	return decodeResult(method, buf)
+}
+
+// injectionInspection inspects the texts of one result.
+type injectionInspection struct {
+	mode       promptinjection.Mode
+	serverName string
+	// counts are the findings, by heuristic.
+	counts map[string]int
+}
+
+func (i *injectionInspection) inspect(text string) string {
+	findings := promptinjection.Find(text)
+	if len(findings) == 0 {
+		return text
+	}
+	for _, finding := range findings {
+		i.counts[finding.Name]++
+	}
+
+	switch i.mode {
+	case promptinjection.Strip:
+		return promptinjection.Remove(text, findings)
+	case promptinjection.Wrap:
+		return promptinjection.WrapUntrusted(i.serverName, text)
+	default:
+		return text
+	}
+}
+
+// inspectStructured inspects the strings of the structured content of a tool result.
+func (i *injectionInspection) inspectStructured(structured any) (any, error) {
+	buf, err := json.Marshal(structured)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling structured content: %w", err)
+	}
+	var value any
+	if err := json.Unmarshal(buf, &value); err != nil {
+		return nil, fmt.Errorf("unmarshalling structured content: %w", err)
+	}
+
+	return mapStrings(value, func(text string) (string, error) {
+		return i.inspect(text), nil
+	})
+}
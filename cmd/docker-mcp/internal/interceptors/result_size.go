@@ -0,0 +1,236 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/telemetry"
+)
+
+// ResultLimit bounds the size of the text returned by the tools of some servers.
+type ResultLimit struct {
+	// Server is a glob pattern.
+	Server   string
+	MaxBytes int
+	// Spill stores the oversize results for the client to read them in pages, instead of truncating
+	// them.
+	Spill bool
+}
+
+// ParseResultLimit parses a limit given as [server=]bytes[:truncate|spill].
+func ParseResultLimit(spec string) (ResultLimit, error) {
+	server, rest, found := strings.Cut(spec, "=")
+	if !found {
+		server, rest = "*", spec
+	}
+	if _, err := path.Match(server, ""); err != nil || server == "" {
+		return ResultLimit{}, fmt.Errorf("invalid server pattern %q in result limit", server)
+	}
+
+	size, action, _ := strings.Cut(rest, ":")
+	maxBytes, err := strconv.Atoi(size)
+	if err != nil || maxBytes <= 0 {
+		return ResultLimit{}, fmt.Errorf("invalid result limit %q, expected a number of bytes", size)
+	}
+
+	limit := ResultLimit{Server: server, MaxBytes: maxBytes}
+	switch action {
+	case "", "truncate":
+	case "spill":
+		limit.Spill = true
+	default:
+		return ResultLimit{}, fmt.Errorf("invalid result limit action %q, expected truncate or spill",
+			action)
+	}
+	return limit, nil
+}
+
+// ResultSizeOptions configure how the results larger than the limits are handled.
+type ResultSizeOptions struct {
+	// Limits are evaluated in order. The first one that applies wins.
+	Limits []ResultLimit
+	// Store keeps the results that spill over. Without store, they are truncated.
+	Store *resultstore.Store
+}
+
+func (o *ResultSizeOptions) limit(serverName string) (ResultLimit, bool) {
+	for _, limit := range o.Limits {
+		if matched, _ := path.Match(limit.Server, serverName); matched {
+			return limit, true
+		}
+	}
+	return ResultLimit{}, false
+}
+
+// ResultSizeMiddleware truncates the text and drops the structured content of the tool results
+// larger than the limits, or replaces them with a link to a gateway://results/<id> resource the
+// client can read in pages.
+func ResultSizeMiddleware(options ResultSizeOptions) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			serverName := ServerName(ctx)
+			limit, found := options.limit(serverName)
+			if !found {
+				return next(ctx, method, req)
+			}
+
+			result, err := next(ctx, method, req)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			callResult := toCallToolResult(result)
+			if callResult == nil {
+				return result, nil
+			}
+			size := len(structuredContent(callResult))
+			for _, content := range callResult.Content {
+				if c, ok := content.(*mcp.TextContent); ok {
+					size += len(c.Text)
+				}
+			}
+			if size <= limit.MaxBytes {
+				return result, nil
+			}
+
+			var toolName string
+			if params, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && params != nil {
+				toolName = params.Name
+			}
+
+			if limit.Spill && options.Store != nil {
+				var sessionID string
+				if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+					sessionID = ss.ID()
+				}
+
+				spilled, err := spill(options.Store, sessionID, toolName, callResult, size, limit)
+				if err == nil {
+					logf("  > Result of %s is %d bytes, stored for the client to read in pages",
+						toolName, size)
+					telemetry.RecordOversizeResult(ctx, serverName, "spill", int64(size))
+					return spilled, nil
+				}
+				logf("  ! Can't store the result of %s, truncating it: %s", toolName, err)
+			}
+
+			logf("  > Result of %s is %d bytes, truncating it to %d bytes",
+				toolName, size, limit.MaxBytes)
+			telemetry.RecordOversizeResult(ctx, serverName, "truncate", int64(size))
+			return truncate(callResult, size, limit.MaxBytes), nil
+		}
+	}
+}
+
+// structuredContent returns the structured content of a result as JSON, if any.
+func structuredContent(result *mcp.CallToolResult) string {
+	if result.StructuredContent == nil {
+		return ""
+	}
+	buf, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+// truncate keeps the first maxBytes of the text of a result, drops its structured content, and
+// adds a notice.
+func truncate(result *mcp.CallToolResult, size, maxBytes int) *mcp.CallToolResult {
+	budget := maxBytes
+	var content []mcp.Content
+	for _, c := range result.Content {
+		text, ok := c.(*mcp.TextContent)
+		if !ok {
+			content = append(content, c)
+			continue
+		}
+		if budget <= 0 {
+			continue
+		}
+		if len(text.Text) > budget {
+			end := budget
+			for end > 0 && !utf8.RuneStart(text.Text[end]) {
+				end--
+			}
+			text.Text = text.Text[:end]
+		}
+		budget -= len(text.Text)
+		content = append(content, text)
+	}
+
+	notice := fmt.Sprintf("[The result was truncated: it is %d bytes, only the first %d are shown.]",
+		size, maxBytes)
+	if result.StructuredContent != nil {
+		result.StructuredContent = nil
+		notice += " [The structured content was dropped.]"
+	}
+	result.Content = append(content, &mcp.TextContent{Text: notice})
+	return result
+}
+
+// spill stores the text and the structured content of a result and replaces them with a link to the
+// stored result.
+func spill(
+	store *resultstore.Store,
+	sessionID, toolName string,
+	result *mcp.CallToolResult,
+	size int,
+	limit ResultLimit,
+) (*mcp.CallToolResult, error) {
+	var (
+		texts   []string
+		content []mcp.Content
+	)
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		} else {
+			content = append(content, c)
+		}
+	}
+	if structured := structuredContent(result); structured != "" {
+		texts = append(texts, structured)
+	}
+
+	id, pages, err := store.Put(sessionID, strings.Join(texts, "\n"), limit.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	uri := resultstore.URI(id, 1)
+	size64 := int64(size)
+
+	notice := fmt.Sprintf("The result of %s is %d bytes, more than the %d bytes limit. "+
+		"It was stored as %s, which can be read with resources/read in %d pages",
+		toolName, size, limit.MaxBytes, uri, pages)
+	if pages > 1 {
+		notice += fmt.Sprintf(", adding ?page=2 to ?page=%d to the URI for the next pages", pages)
+	}
+	notice += fmt.Sprintf(". It expires in %s.", store.Retention())
+
+	result.StructuredContent = nil
+	result.Content = append([]mcp.Content{
+		&mcp.TextContent{Text: notice},
+		&mcp.ResourceLink{
+			URI:         uri,
+			Name:        "result-" + id,
+			Title:       "Result of " + toolName,
+			Description: fmt.Sprintf("%d pages of up to %d bytes", pages, limit.MaxBytes),
+			MIMEType:    "text/plain",
+			Size:        &size64,
+		},
+	}, content...)
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/resultstore"
+)
+
+func callWithLargeResult(t *testing.T, options ResultSizeOptions, serverName string, texts ...string) *mcp.CallToolResult {
+	t.Helper()
+
+	handler := ResultSizeMiddleware(options)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		result := &mcp.CallToolResult{}
+		for _, text := range texts {
+			result.Content = append(result.Content, &mcp.TextContent{Text: text})
+		}
+		result.Content = append(result.Content, &mcp.ImageContent{MIMEType: "image/png", Data: []byte("png")})
+		return result, nil
+	})
+
+	result, err := handler(WithServerName(t.Context(), serverName), "tools/call", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "fetch"},
+	})
+	require.NoError(t, err)
+	return result.(*mcp.CallToolResult)
+}
+
+func TestParseResultLimit(t *testing.T) {
+	limit, err := ParseResultLimit("fetch=1000:spill")
+	require.NoError(t, err)
+	assert.Equal(t, ResultLimit{Server: "fetch", MaxBytes: 1000, Spill: true}, limit)
+
+	limit, err = ParseResultLimit("500")
+	require.NoError(t, err)
+	assert.Equal(t, ResultLimit{Server: "*", MaxBytes: 500}, limit)
+
+	for _, spec := range []string{"fetch=", "fetch=-1", "fetch=10:drop", "[=10"} {
+		_, err := ParseResultLimit(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestSmallResultsAreUnchanged(t *testing.T) {
+	options := ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 10}}}
+
+	result := callWithLargeResult(t, options, "fetch", "small")
+	assert.Len(t, result.Content, 2)
+	assert.Equal(t, "small", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestLargeResultsAreTruncated(t *testing.T) {
+	options := ResultSizeOptions{Limits: []ResultLimit{{Server: "fetch", MaxBytes: 10}}}
+
+	result := callWithLargeResult(t, options, "fetch", "0123456", "789abc", "def")
+	require.Len(t, result.Content, 4)
+	assert.Equal(t, "0123456", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, "789", result.Content[1].(*mcp.TextContent).Text)
+	assert.IsType(t, &mcp.ImageContent{}, result.Content[2])
+	assert.Equal(t, "[The result was truncated: it is 16 bytes, only the first 10 are shown.]",
+		result.Content[3].(*mcp.TextContent).Text)
+
+	// Other servers have no limit.
+	result = callWithLargeResult(t, options, "github", "0123456", "789abc", "def")
+	assert.Len(t, result.Content, 4)
+}
+
+func TestLargeResultsSpillOver(t *testing.T) {
+	store := resultstore.New(resultstore.Options{})
+	options := ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 10, Spill: true}}, Store: store}
+
+	result := callWithLargeResult(t, options, "fetch", strings.Repeat("a", 15), strings.Repeat("b", 4))
+	require.Len(t, result.Content, 3)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "is 19 bytes, more than the 10 bytes limit")
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "in 2 pages")
+	assert.IsType(t, &mcp.ImageContent{}, result.Content[2])
+
+	link := result.Content[1].(*mcp.ResourceLink)
+	assert.Equal(t, int64(19), *link.Size)
+	id, _, err := resultstore.ParseURI(link.URI)
+	require.NoError(t, err)
+
+	page, pages, err := store.Page("", id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	assert.Equal(t, strings.Repeat("a", 10), page)
+
+	page, _, err = store.Page("", id, 2)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", 5)+"\n"+strings.Repeat("b", 4), page)
+}
+
+func TestLargeResultsAreTruncatedWhenTheyCantBeStored(t *testing.T) {
+	store := resultstore.New(resultstore.Options{SessionMaxBytes: 5})
+	options := ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 10, Spill: true}}, Store: store}
+
+	result := callWithLargeResult(t, options, "fetch", strings.Repeat("a", 15))
+	require.Len(t, result.Content, 3)
+	assert.Equal(t, strings.Repeat("a", 10), result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestLargeStructuredResults(t *testing.T) {
+	structured := map[string]any{"items": strings.Repeat("a", 20)}
+	call := func(options ResultSizeOptions) *mcp.CallToolResult {
+		handler := ResultSizeMiddleware(options)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "small"}},
+				StructuredContent: structured,
+			}, nil
+		})
+		result, err := handler(WithServerName(t.Context(), "fetch"), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "fetch"},
+		})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+
+	// The text alone is under the limit, the structured content isn't.
+	result := call(ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 10}}})
+	assert.Nil(t, result.StructuredContent)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "small", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, "[The result was truncated: it is 37 bytes, only the first 10 are shown.]"+
+		" [The structured content was dropped.]", result.Content[1].(*mcp.TextContent).Text)
+
+	store := resultstore.New(resultstore.Options{})
+	result = call(ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 100, Spill: true}}, Store: store})
+	assert.Equal(t, structured, result.StructuredContent, "under the limit")
+
+	result = call(ResultSizeOptions{Limits: []ResultLimit{{Server: "*", MaxBytes: 30, Spill: true}}, Store: store})
+	assert.Nil(t, result.StructuredContent)
+	require.Len(t, result.Content, 2)
+	id, _, err := resultstore.ParseURI(result.Content[1].(*mcp.ResourceLink).URI)
+	require.NoError(t, err)
+
+	first, pages, err := store.Page("", id, 1)
+	require.NoError(t, err)
+	second, _, err := store.Page("", id, pages)
+	require.NoError(t, err)
+	assert.Equal(t, `small`+"\n"+`{"items":"`+strings.Repeat("a", 20)+`"}`, first+second)
+}
@@ -31,7 +31,32 @@ type CapabilityRefresher interface {
 	) error
 }
 
+// SamplingHandler can be implemented by a CapabilityRefresher to decide how sampling requests
+// from a server are forwarded to the client. Without it, they are forwarded as is.
+type SamplingHandler interface {
+	CreateMessage(
+		ctx context.Context,
+		serverName string,
+		serverSession *mcp.ServerSession,
+		params *mcp.CreateMessageParams,
+	) (*mcp.CreateMessageResult, error)
+}
+
+// TransportWrapper can be implemented by a CapabilityRefresher to wrap the transports to the
+// servers, e.g. to record the exchanges.
+type TransportWrapper interface {
+	WrapTransport(serverName string, transport mcp.Transport) mcp.Transport
+}
+
+func wrapTransport(serverName string, transport mcp.Transport, refresher CapabilityRefresher) mcp.Transport {
+	if wrapper, ok := refresher.(TransportWrapper); ok {
+		return wrapper.WrapTransport(serverName, transport)
+	}
+	return transport
+}
+
 func notifications(
+	serverName string,
 	serverSession *mcp.ServerSession,
 	server *mcp.Server,
 	refresher CapabilityRefresher,
@@ -42,9 +67,14 @@ func notifications(
 				_ = server.ResourceUpdated(ctx, req.Params)
 			}
 		},
-		CreateMessageHandler: func(_ context.Context, _ *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
-			// Handle create messages if needed
-			return nil, fmt.Errorf("create messages not supported")
+		CreateMessageHandler: func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			if sampler, ok := refresher.(SamplingHandler); ok {
+				return sampler.CreateMessage(ctx, serverName, serverSession, req.Params)
+			}
+			if serverSession != nil {
+				return serverSession.CreateMessage(ctx, req.Params)
+			}
+			return nil, fmt.Errorf("create message handled without server session")
 		},
 		ToolListChangedHandler: func(ctx context.Context, _ *mcp.ToolListChangedRequest) {
 			if refresher != nil && server != nil && serverSession != nil {
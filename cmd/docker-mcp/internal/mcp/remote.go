@@ -31,9 +31,9 @@ func (c *remoteMCPClient) Initialize(
 	ctx context.Context,
 	_ *mcp.InitializeParams,
 	_ bool,
-	_ *mcp.ServerSession,
-	_ *mcp.Server,
-	_ CapabilityRefresher,
+	ss *mcp.ServerSession,
+	server *mcp.Server,
+	refresher CapabilityRefresher,
 ) error {
 	if c.initialized.Load() {
 		return fmt.Errorf("client already initialized")
@@ -94,11 +94,11 @@ func (c *remoteMCPClient) Initialize(
 	c.client = mcp.NewClient(&mcp.Implementation{
 		Name:    "docker-mcp-gateway",
 		Version: "1.0.0",
-	}, nil)
+	}, notifications(c.config.Name, ss, server, refresher))
 
 	c.client.AddRoots(c.roots...)
 
-	session, err := c.client.Connect(ctx, mcpTransport, nil)
+	session, err := c.client.Connect(ctx, wrapTransport(c.config.Name, mcpTransport, refresher), nil)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
@@ -55,11 +55,11 @@ func (c *stdioMCPClient) Initialize(
 	c.client = mcp.NewClient(&mcp.Implementation{
 		Name:    "docker-mcp-gateway",
 		Version: "1.0.0",
-	}, notifications(ss, server, refresher))
+	}, notifications(c.name, ss, server, refresher))
 
 	c.client.AddRoots(c.roots...)
 
-	session, err := c.client.Connect(ctx, transport, nil)
+	session, err := c.client.Connect(ctx, wrapTransport(c.name, transport, refresher), nil)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// transportMCPClient connects to a server through a given transport, e.g. to replay recorded
+// exchanges.
+type transportMCPClient struct {
+	name        string
+	transport   mcp.Transport
+	client      *mcp.Client
+	session     *mcp.ClientSession
+	roots       []*mcp.Root
+	initialized atomic.Bool
+}
+
+func NewTransportClient(name string, transport mcp.Transport) Client {
+	return &transportMCPClient{
+		name:      name,
+		transport: transport,
+	}
+}
+
+func (c *transportMCPClient) Initialize(
+	ctx context.Context,
+	_ *mcp.InitializeParams,
+	_ bool,
+	ss *mcp.ServerSession,
+	server *mcp.Server,
+	refresher CapabilityRefresher,
+) error {
+	if c.initialized.Load() {
+		return fmt.Errorf("client already initialized")
+	}
+
+	c.client = mcp.NewClient(&mcp.Implementation{
+		Name:    "docker-mcp-gateway",
+		Version: "1.0.0",
+	}, notifications(c.name, ss, server, refresher))
+
+	c.client.AddRoots(c.roots...)
+
+	session, err := c.client.Connect(ctx, c.transport, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	c.session = session
+	c.initialized.Store(true)
+
+	return nil
+}
+
+func (c *transportMCPClient) AddRoots(roots []*mcp.Root) {
+	if c.initialized.Load() {
+		c.client.AddRoots(roots...)
+	}
+	c.roots = roots
+}
+
+func (c *transportMCPClient) Session() *mcp.ClientSession {
+	if !c.initialized.Load() {
+		panic("client not initialize")
+	}
+	return c.session
+}
+
+func (c *transportMCPClient) GetClient() *mcp.Client {
+	if !c.initialized.Load() {
+		panic("client not initialize")
+	}
+	return c.client
+}
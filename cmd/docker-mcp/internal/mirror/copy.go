@@ -0,0 +1,67 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// SignaturesRepository is where the signatures of the mcp/ images are stored.
+const SignaturesRepository = "mcp/signatures"
+
+// Copy copies an image, or a multi-platform index, from one registry to another.
+// It returns the digest of the copied manifest, which is the same on both sides.
+func Copy(ctx context.Context, src, dst string) (string, error) {
+	srcRef, err := name.ParseReference(src)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %s: %w", src, err)
+	}
+	dstRef, err := name.ParseReference(dst)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %s: %w", dst, err)
+	}
+
+	options := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+
+	desc, err := remote.Get(srcRef, options...)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", src, err)
+	}
+
+	// A reference pinned by digest can't be written by tag, so write it to the digest of the destination repository.
+	if digestRef, ok := dstRef.(name.Digest); ok {
+		dstRef = digestRef.Context().Digest(desc.Digest.String())
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return "", err
+		}
+		if err := remote.WriteIndex(dstRef, index, options...); err != nil {
+			return "", fmt.Errorf("pushing %s: %w", dst, err)
+		}
+	} else {
+		image, err := desc.Image()
+		if err != nil {
+			return "", err
+		}
+		if err := remote.Write(dstRef, image, options...); err != nil {
+			return "", fmt.Errorf("pushing %s: %w", dst, err)
+		}
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// SignatureTag returns the cosign style tag, in a given repository, of the signature of an image digest.
+func SignatureTag(repository, digest string) string {
+	return repository + ":" + strings.Replace(digest, ":", "-", 1) + ".sig"
+}
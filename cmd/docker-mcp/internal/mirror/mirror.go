@@ -0,0 +1,179 @@
+package mirror
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHub        = "docker.io"
+	dockerHubLibrary = "docker.io/library"
+)
+
+// Rule rewrites the repositories matching From into To.
+// A trailing * matches any suffix, e.g. docker.io/mcp/* -> registry.corp/mcp-mirror/*.
+type Rule struct {
+	From string
+	To   string
+}
+
+// Rules is an ordered list of registry rewrite rules. The first matching rule wins.
+// It implements pflag.Value so it can be used as a repeatable `from=to` flag.
+type Rules []Rule
+
+// ParseRule parses a rule written as `from=to` or `from -> to`.
+func ParseRule(spec string) (Rule, error) {
+	from, to, found := strings.Cut(spec, "->")
+	if !found {
+		from, to, found = strings.Cut(spec, "=")
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !found || from == "" || to == "" {
+		return Rule{}, fmt.Errorf("invalid registry mirror %q, expected from=to", spec)
+	}
+	if strings.HasSuffix(from, "*") != strings.HasSuffix(to, "*") {
+		return Rule{}, fmt.Errorf("invalid registry mirror %q, both sides must end with * or none", spec)
+	}
+	if strings.Count(from, "*") > 1 || strings.Count(to, "*") > 1 ||
+		(strings.Contains(from, "*") && !strings.HasSuffix(from, "*")) {
+		return Rule{}, fmt.Errorf("invalid registry mirror %q, * is only allowed at the end", spec)
+	}
+
+	return Rule{
+		From: normalizePattern(from),
+		To:   normalizePattern(to),
+	}, nil
+}
+
+// ParseRules parses a list of `from=to` rules.
+func ParseRules(specs []string) (Rules, error) {
+	var rules Rules
+	for _, spec := range specs {
+		if err := rules.Set(spec); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rules) String() string {
+	var specs []string
+	for _, rule := range *r {
+		specs = append(specs, rule.From+"="+rule.To)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (r *Rules) Set(spec string) error {
+	rule, err := ParseRule(spec)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+// Type is only used in help text
+func (r *Rules) Type() string {
+	return "from=to"
+}
+
+// Rewrite returns the image to use in place of image. Images that don't match any rule are returned unchanged.
+func (r Rules) Rewrite(image string) string {
+	repository, suffix := splitImage(image)
+	normalized := normalize(repository)
+
+	for _, rule := range r {
+		if rewritten, ok := rewrite(normalized, rule.From, rule.To); ok {
+			return familiar(rewritten) + suffix
+		}
+	}
+
+	return image
+}
+
+// Original maps a rewritten image back to its original identity, which is what its signatures are attached to.
+// Images that don't match any rule are returned unchanged.
+func (r Rules) Original(image string) string {
+	repository, suffix := splitImage(image)
+	normalized := normalize(repository)
+
+	for _, rule := range r {
+		if original, ok := rewrite(normalized, rule.To, rule.From); ok {
+			return familiar(original) + suffix
+		}
+	}
+
+	return image
+}
+
+func rewrite(repository, from, to string) (string, bool) {
+	if prefix, wildcard := strings.CutSuffix(from, "*"); wildcard {
+		rest, found := strings.CutPrefix(repository, prefix)
+		if !found {
+			return "", false
+		}
+		return strings.TrimSuffix(to, "*") + rest, true
+	}
+
+	if repository != from {
+		return "", false
+	}
+	return to, true
+}
+
+// splitImage splits an image reference into its repository and its `:tag` and/or `@digest` suffix.
+func splitImage(image string) (string, string) {
+	repository, digest, hasDigest := strings.Cut(image, "@")
+	suffix := ""
+	if hasDigest {
+		suffix = "@" + digest
+	}
+
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		suffix = repository[i:] + suffix
+		repository = repository[:i]
+	}
+
+	return repository, suffix
+}
+
+// normalize turns a repository into its fully qualified form, e.g. mcp/github -> docker.io/mcp/github.
+func normalize(repository string) string {
+	domain, rest, found := strings.Cut(repository, "/")
+	switch {
+	case !found:
+		return dockerHubLibrary + "/" + repository
+	case domain == "index.docker.io":
+		return dockerHub + "/" + rest
+	case strings.ContainsAny(domain, ".:") || domain == "localhost":
+		return repository
+	default:
+		return dockerHub + "/" + repository
+	}
+}
+
+func normalizePattern(pattern string) string {
+	if pattern == "*" {
+		return pattern
+	}
+	if prefix, wildcard := strings.CutSuffix(pattern, "*"); wildcard {
+		if !strings.HasSuffix(prefix, "/") {
+			// e.g. docker.io/mcp-* can't be normalized safely, it's kept as is.
+			return pattern
+		}
+		return strings.TrimSuffix(normalize(prefix+"x"), "x") + "*"
+	}
+	return normalize(pattern)
+}
+
+// familiar turns a repository back into the short form Docker users are used to, e.g. docker.io/mcp/github -> mcp/github.
+func familiar(repository string) string {
+	if rest, found := strings.CutPrefix(repository, dockerHubLibrary+"/"); found {
+		return rest
+	}
+	if rest, found := strings.CutPrefix(repository, dockerHub+"/"); found {
+		return rest
+	}
+	return repository
+}
@@ -0,0 +1,72 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule("docker.io/mcp/* -> registry.corp/mcp-mirror/*")
+	require.NoError(t, err)
+	assert.Equal(t, Rule{From: "docker.io/mcp/*", To: "registry.corp/mcp-mirror/*"}, rule)
+
+	rule, err = ParseRule("mcp/*=registry.corp/mcp-mirror/*")
+	require.NoError(t, err)
+	assert.Equal(t, Rule{From: "docker.io/mcp/*", To: "registry.corp/mcp-mirror/*"}, rule)
+
+	rule, err = ParseRule("index.docker.io/*=localhost:5000/*")
+	require.NoError(t, err)
+	assert.Equal(t, Rule{From: "docker.io/*", To: "localhost:5000/*"}, rule)
+
+	for _, invalid := range []string{"", "mcp/*", "=registry.corp", "mcp/*=registry.corp/mcp", "mcp/*/x=r/*/x"} {
+		_, err := ParseRule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	rules, err := ParseRules([]string{
+		"docker.io/mcp/github=registry.corp/special/github",
+		"docker.io/mcp/*=registry.corp/mcp-mirror/*",
+		"alpine=registry.corp/library/alpine",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"mcp/fetch", "registry.corp/mcp-mirror/fetch"},
+		{"mcp/fetch:1.0", "registry.corp/mcp-mirror/fetch:1.0"},
+		{"docker.io/mcp/fetch@sha256:abc", "registry.corp/mcp-mirror/fetch@sha256:abc"},
+		{"index.docker.io/mcp/fetch:1.0@sha256:abc", "registry.corp/mcp-mirror/fetch:1.0@sha256:abc"},
+		{"mcp/github@sha256:abc", "registry.corp/special/github@sha256:abc"},
+		{"alpine:3.20", "registry.corp/library/alpine:3.20"},
+		{"ghcr.io/org/server:latest", "ghcr.io/org/server:latest"},
+		{"localhost:5000/mcp/fetch", "localhost:5000/mcp/fetch"},
+		{"mcpx/fetch", "mcpx/fetch"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, rules.Rewrite(test.image), test.image)
+	}
+}
+
+func TestOriginal(t *testing.T) {
+	rules, err := ParseRules([]string{"docker.io/mcp/*=registry.corp/mcp-mirror/*"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "mcp/fetch@sha256:abc", rules.Original("registry.corp/mcp-mirror/fetch@sha256:abc"))
+	assert.Equal(t, "ghcr.io/org/server", rules.Original("ghcr.io/org/server"))
+	assert.Equal(t, "mcp/fetch:1.0", rules.Original(rules.Rewrite("mcp/fetch:1.0")))
+}
+
+func TestRulesFlag(t *testing.T) {
+	var rules Rules
+	require.NoError(t, rules.Set("mcp/*=registry.corp/mcp/*"))
+	require.NoError(t, rules.Set("ghcr.io/*=registry.corp/ghcr/*"))
+	assert.Equal(t, "docker.io/mcp/*=registry.corp/mcp/*,ghcr.io/*=registry.corp/ghcr/*", rules.String())
+	require.Error(t, rules.Set("invalid"))
+	assert.Len(t, rules, 2)
+}
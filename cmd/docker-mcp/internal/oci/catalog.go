@@ -0,0 +1,332 @@
+package oci
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const (
+	// MCPCatalogArtifactType is the artifact type of a whole catalog pushed with `docker mcp catalog push`.
+	MCPCatalogArtifactType = "application/vnd.docker.mcp.catalog"
+	// MCPCatalogLayerMediaType is the media type of the single layer holding the catalog YAML.
+	MCPCatalogLayerMediaType = "application/vnd.docker.mcp.catalog.v1+yaml"
+	// MCPCatalogSignatureArtifactType is the artifact type of a detached catalog signature.
+	MCPCatalogSignatureArtifactType = "application/vnd.docker.mcp.catalog.signature"
+
+	// CatalogRefPrefix is the scheme used to reference a catalog stored in an OCI registry.
+	CatalogRefPrefix = "oci://"
+
+	catalogNameAnnotation      = "io.docker.mcp.catalog.name"
+	signatureAnnotation        = "io.docker.mcp.catalog.signature"
+	signingPasswordEnvVariable = "DOCKER_MCP_SIGNING_PASSWORD"
+)
+
+// CatalogArtifact is a catalog pulled from an OCI registry.
+type CatalogArtifact struct {
+	Name    string
+	Content []byte
+	Digest  name.Digest
+}
+
+// catalogSignaturePayload is what gets signed. It binds the signature to a manifest digest.
+type catalogSignaturePayload struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// IsCatalogRef tells whether a catalog location points to an OCI registry.
+func IsCatalogRef(location string) bool {
+	return strings.HasPrefix(location, CatalogRefPrefix)
+}
+
+// TrimCatalogRef removes the oci:// scheme from a catalog location.
+func TrimCatalogRef(location string) string {
+	return strings.TrimPrefix(location, CatalogRefPrefix)
+}
+
+// PushCatalog pushes the YAML content of a catalog as an OCI artifact and returns the digest it was pushed as.
+func PushCatalog(ociRef string, catalogName string, content []byte) (name.Digest, error) {
+	ref, err := name.ParseReference(TrimCatalogRef(ociRef))
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to parse OCI reference %s: %w", ociRef, err)
+	}
+
+	annotations := map[string]string{
+		oci.AnnotationTitle:   catalogName,
+		oci.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+		catalogNameAnnotation: catalogName,
+	}
+
+	return pushArtifact(
+		ref,
+		MCPCatalogArtifactType,
+		MCPCatalogLayerMediaType,
+		content,
+		annotations,
+	)
+}
+
+// PullCatalog fetches a catalog artifact. When the reference is pinned by digest, the content is verified against it.
+func PullCatalog(ociRef string) (CatalogArtifact, error) {
+	ref, err := name.ParseReference(TrimCatalogRef(ociRef))
+	if err != nil {
+		return CatalogArtifact{}, fmt.Errorf("failed to parse OCI reference %s: %w", ociRef, err)
+	}
+
+	manifest, content, dgst, err := fetchArtifact(ref, MCPCatalogArtifactType)
+	if err != nil {
+		return CatalogArtifact{}, err
+	}
+
+	return CatalogArtifact{
+		Name:    manifest.Annotations[catalogNameAnnotation],
+		Content: content,
+		Digest:  dgst,
+	}, nil
+}
+
+// SignCatalog signs a pushed catalog with a PEM encoded private key (cosign keys are supported) and pushes
+// the signature next to it, using the same `sha256-<hex>.sig` tag convention as cosign.
+func SignCatalog(dgst name.Digest, catalogName string, keyPath string) error {
+	signer, err := signature.LoadSignerFromPEMFile(keyPath, crypto.SHA256, signingPassword)
+	if err != nil {
+		return fmt.Errorf("loading signing key %s: %w", keyPath, err)
+	}
+
+	payload, sig, err := signCatalogDigest(signer, catalogName, dgst.DigestStr())
+	if err != nil {
+		return err
+	}
+
+	sigRef, err := signatureTag(dgst)
+	if err != nil {
+		return err
+	}
+
+	annotations := map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+	}
+	if _, err := pushArtifact(
+		sigRef,
+		MCPCatalogSignatureArtifactType,
+		"application/json",
+		payload,
+		annotations,
+	); err != nil {
+		return fmt.Errorf("pushing signature: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyCatalog checks that a catalog digest was signed by the holder of the private key matching keyPath.
+func VerifyCatalog(dgst name.Digest, keyPath string) error {
+	verifier, err := signature.LoadVerifierFromPEMFile(keyPath, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("loading public key %s: %w", keyPath, err)
+	}
+
+	sigRef, err := signatureTag(dgst)
+	if err != nil {
+		return err
+	}
+
+	manifest, payload, _, err := fetchArtifact(sigRef, MCPCatalogSignatureArtifactType)
+	if err != nil {
+		return fmt.Errorf("no signature found for %s: %w", dgst.String(), err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(manifest.Annotations[signatureAnnotation])
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	return verifyCatalogDigest(verifier, payload, sig, dgst.DigestStr())
+}
+
+func signCatalogDigest(
+	signer signature.Signer,
+	catalogName string,
+	dgst string,
+) ([]byte, []byte, error) {
+	payload, err := json.Marshal(catalogSignaturePayload{
+		Type:   MCPCatalogArtifactType,
+		Name:   catalogName,
+		Digest: dgst,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sig, err := signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("signing catalog: %w", err)
+	}
+
+	return payload, sig, nil
+}
+
+func verifyCatalogDigest(
+	verifier signature.Verifier,
+	payload []byte,
+	sig []byte,
+	dgst string,
+) error {
+	if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("invalid catalog signature: %w", err)
+	}
+
+	var signed catalogSignaturePayload
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return fmt.Errorf("parsing signature payload: %w", err)
+	}
+	if signed.Type != MCPCatalogArtifactType {
+		return fmt.Errorf("signature is for a %q, not a catalog", signed.Type)
+	}
+	if signed.Digest != dgst {
+		return fmt.Errorf("signature is for %s, not %s", signed.Digest, dgst)
+	}
+
+	return nil
+}
+
+func signatureTag(dgst name.Digest) (name.Tag, error) {
+	tag := strings.Replace(dgst.DigestStr(), ":", "-", 1) + ".sig"
+	return name.NewTag(dgst.Context().Name() + ":" + tag)
+}
+
+func signingPassword(bool) ([]byte, error) {
+	return []byte(os.Getenv(signingPasswordEnvVariable)), nil
+}
+
+// pushArtifact pushes a single layer artifact with an empty config, following the OCI artifact guidelines.
+func pushArtifact(
+	ref name.Reference,
+	artifactType string,
+	layerMediaType string,
+	content []byte,
+	annotations map[string]string,
+) (name.Digest, error) {
+	emptyConfig := []byte("{}")
+	configDigest := digest.FromBytes(emptyConfig)
+	contentDigest := digest.FromBytes(content)
+
+	manifest := oci.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		MediaType:    oci.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config: oci.Descriptor{
+			MediaType: oci.MediaTypeEmptyJSON,
+			Digest:    configDigest,
+			Size:      int64(len(emptyConfig)),
+		},
+		Layers: []oci.Descriptor{
+			{
+				MediaType: layerMediaType,
+				Digest:    contentDigest,
+				Size:      int64(len(content)),
+			},
+		},
+		Annotations: annotations,
+	}
+
+	if err := uploadBlob(ref, emptyConfig, configDigest); err != nil {
+		return name.Digest{}, fmt.Errorf("failed to upload config blob: %w", err)
+	}
+	if err := uploadBlob(ref, content, contentDigest); err != nil {
+		return name.Digest{}, fmt.Errorf("failed to upload content blob: %w", err)
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := uploadManifest(ref, manifestBytes); err != nil {
+		return name.Digest{}, fmt.Errorf("failed to upload manifest: %w", err)
+	}
+
+	return ref.Context().Digest(digest.FromBytes(manifestBytes).String()), nil
+}
+
+// fetchArtifact reads a single layer artifact of the expected type and returns its manifest, content and digest.
+func fetchArtifact(
+	ref name.Reference,
+	artifactType string,
+) (oci.Manifest, []byte, name.Digest, error) {
+	desc, err := remote.Get(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf(
+			"failed to fetch artifact %s: %w",
+			ref.Name(),
+			err,
+		)
+	}
+
+	var manifest oci.Manifest
+	if err := json.Unmarshal(desc.Manifest, &manifest); err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf(
+			"failed to parse OCI manifest: %w",
+			err,
+		)
+	}
+	if manifest.ArtifactType != artifactType {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf(
+			"artifact type %s is not %s",
+			manifest.ArtifactType,
+			artifactType,
+		)
+	}
+	if len(manifest.Layers) == 0 {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf("no layers found in artifact")
+	}
+
+	layerHash, err := v1.NewHash(manifest.Layers[0].Digest.String())
+	if err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, err
+	}
+	layer, err := remote.Layer(
+		ref.Context().Digest(layerHash.String()),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	)
+	if err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf("failed to fetch layer: %w", err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf("failed to read layer: %w", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf("failed to read layer: %w", err)
+	}
+	if digest.FromBytes(content).String() != layerHash.String() {
+		return oci.Manifest{}, nil, name.Digest{}, fmt.Errorf(
+			"layer digest mismatch for %s",
+			ref.Name(),
+		)
+	}
+
+	return manifest, content, ref.Context().Digest(desc.Digest.String()), nil
+}
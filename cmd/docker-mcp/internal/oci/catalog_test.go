@@ -0,0 +1,64 @@
+package oci
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestIsCatalogRef(t *testing.T) {
+	assert.True(t, IsCatalogRef("oci://registry.example.com/mcp/catalog:v1"))
+	assert.False(t, IsCatalogRef("https://example.com/catalog.yaml"))
+	assert.False(t, IsCatalogRef("./catalog.yaml"))
+	assert.Equal(
+		t,
+		"registry.example.com/mcp/catalog:v1",
+		TrimCatalogRef("oci://registry.example.com/mcp/catalog:v1"),
+	)
+}
+
+func TestSignatureTag(t *testing.T) {
+	dgst, err := name.NewDigest("registry.example.com/mcp/catalog@" + testDigest)
+	require.NoError(t, err)
+
+	tag, err := signatureTag(dgst)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"registry.example.com/mcp/catalog:sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.sig",
+		tag.String(),
+	)
+}
+
+func TestCatalogSignatureRoundTrip(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signerVerifier, err := signature.LoadSignerVerifier(priv, crypto.SHA256)
+	require.NoError(t, err)
+
+	payload, sig, err := signCatalogDigest(signerVerifier, "team", testDigest)
+	require.NoError(t, err)
+
+	require.NoError(t, verifyCatalogDigest(signerVerifier, payload, sig, testDigest))
+
+	err = verifyCatalogDigest(signerVerifier, payload, sig, "sha256:ffff")
+	require.ErrorContains(t, err, "signature is for")
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherVerifier, err := signature.LoadVerifier(other.Public(), crypto.SHA256)
+	require.NoError(t, err)
+
+	err = verifyCatalogDigest(otherVerifier, payload, sig, testDigest)
+	require.ErrorContains(t, err, "invalid catalog signature")
+}
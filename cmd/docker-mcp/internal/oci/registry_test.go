@@ -0,0 +1,100 @@
+package oci
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalog = `registry:
+  github:
+    image: mcp/github
+`
+
+// startRegistry starts an in-process OCI registry and returns its host.
+func startRegistry(t *testing.T) string {
+	t.Helper()
+
+	// Don't use the credentials of the user.
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// writeKeys writes a new pair of PEM encoded keys and returns the paths of the private and the
+// public keys.
+func writeKeys(t *testing.T) (string, string) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privPath := filepath.Join(dir, "key.pem")
+	pubPath := filepath.Join(dir, "key.pub")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600))
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644))
+	return privPath, pubPath
+}
+
+func TestPushAndPullCatalog(t *testing.T) {
+	host := startRegistry(t)
+
+	dgst, err := PushCatalog("oci://"+host+"/mcp/catalog:v1", "team", []byte(testCatalog))
+	require.NoError(t, err)
+
+	byTag, err := PullCatalog("oci://" + host + "/mcp/catalog:v1")
+	require.NoError(t, err)
+	assert.Equal(t, "team", byTag.Name)
+	assert.Equal(t, testCatalog, string(byTag.Content))
+	assert.Equal(t, dgst, byTag.Digest)
+
+	byDigest, err := PullCatalog("oci://" + dgst.String())
+	require.NoError(t, err)
+	assert.Equal(t, testCatalog, string(byDigest.Content))
+	assert.Equal(t, dgst, byDigest.Digest)
+
+	_, err = PullCatalog("oci://" + host + "/mcp/catalog:unknown")
+	require.Error(t, err)
+}
+
+func TestSignAndVerifyCatalog(t *testing.T) {
+	host := startRegistry(t)
+	privateKey, publicKey := writeKeys(t)
+	_, otherPublicKey := writeKeys(t)
+
+	dgst, err := PushCatalog("oci://"+host+"/mcp/catalog:v1", "team", []byte(testCatalog))
+	require.NoError(t, err)
+
+	err = VerifyCatalog(dgst, publicKey)
+	require.ErrorContains(t, err, "no signature found")
+
+	require.NoError(t, SignCatalog(dgst, "team", privateKey))
+	require.NoError(t, VerifyCatalog(dgst, publicKey))
+
+	err = VerifyCatalog(dgst, otherPublicKey)
+	require.ErrorContains(t, err, "invalid catalog signature")
+
+	// A signature doesn't carry over to another version of the catalog.
+	other, err := PushCatalog("oci://"+host+"/mcp/catalog:v2", "team", []byte(testCatalog+"  slack:\n    image: mcp/slack\n"))
+	require.NoError(t, err)
+	err = VerifyCatalog(other, publicKey)
+	require.ErrorContains(t, err, "no signature found")
+}
@@ -0,0 +1,272 @@
+package pii
+
+import (
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Category is a kind of personal data.
+type Category string
+
+const (
+	Email      Category = "email"
+	Phone      Category = "phone"
+	IBAN       Category = "iban"
+	CreditCard Category = "credit-card"
+	NationalID Category = "national-id"
+)
+
+// Categories are all the categories, by decreasing priority when findings overlap.
+var Categories = []Category{NationalID, CreditCard, IBAN, Email, Phone}
+
+// Locales are the locales with specific detectors.
+var Locales = []string{"us", "gb", "fr", "es"}
+
+type detector struct {
+	category Category
+	// locale is empty for the detectors that apply everywhere.
+	locale string
+	re     *regexp.Regexp
+	// valid checks a match, e.g. its checksum.
+	valid func(match string) bool
+}
+
+var detectors = []detector{
+	{
+		category: Email,
+		re:       regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		category: CreditCard,
+		re:       regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid:    validCard,
+	},
+	{
+		category: IBAN,
+		re:       regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+		valid:    validIBAN,
+	},
+	{
+		category: Phone,
+		re:       regexp.MustCompile(`\+[1-9]\d{0,2}(?:[ .\-]?\(?\d{1,4}\)?){2,5}\b`),
+		valid:    digitsBetween(8, 15),
+	},
+	{
+		category: Phone,
+		locale:   "us",
+		re:       regexp.MustCompile(`(?:\(\d{3}\) ?|\b\d{3}[ .\-])\d{3}[ .\-]\d{4}\b`),
+	},
+	{
+		category: Phone,
+		locale:   "gb",
+		re:       regexp.MustCompile(`\b0\d{2,4}[ \-]?\d{3,4}[ \-]?\d{3,4}\b`),
+		valid:    digitsBetween(10, 11),
+	},
+	{
+		category: Phone,
+		locale:   "fr",
+		re:       regexp.MustCompile(`\b0[1-9](?:[ .\-]?\d{2}){4}\b`),
+	},
+	{
+		category: Phone,
+		locale:   "es",
+		re:       regexp.MustCompile(`\b[6-9]\d{2}[ .\-]?\d{3}[ .\-]?\d{3}\b`),
+	},
+	{
+		// Social Security Number
+		category: NationalID,
+		locale:   "us",
+		re:       regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		valid:    validSSN,
+	},
+	{
+		// National Insurance Number
+		category: NationalID,
+		locale:   "gb",
+		re:       regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		valid:    validNINO,
+	},
+	{
+		// Numéro d'inscription au répertoire (sécurité sociale)
+		category: NationalID,
+		locale:   "fr",
+		re:       regexp.MustCompile(`\b[12] ?\d{2} ?(?:0[1-9]|1[0-2]) ?(?:\d{2}|2[AB]) ?\d{3} ?\d{3} ?\d{2}\b`),
+		valid:    validNIR,
+	},
+	{
+		// Documento Nacional de Identidad
+		category: NationalID,
+		locale:   "es",
+		re:       regexp.MustCompile(`\b\d{8}-?[A-Z]\b`),
+		valid:    validDNI,
+	},
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func digitsBetween(minimum, maximum int) func(string) bool {
+	return func(match string) bool {
+		n := len(digits(match))
+		return n >= minimum && n <= maximum
+	}
+}
+
+// cardNetworks are the IIN ranges of the main card networks, and the lengths of their numbers.
+var cardNetworks = []struct {
+	// from and to have the same length.
+	from, to             string
+	minLength, maxLength int
+}{
+	{"4", "4", 13, 19},       // Visa
+	{"51", "55", 16, 16},     // Mastercard
+	{"2221", "2720", 16, 16}, // Mastercard
+	{"34", "34", 15, 15},     // American Express
+	{"37", "37", 15, 15},     // American Express
+	{"6011", "6011", 16, 19}, // Discover
+	{"644", "649", 16, 19},   // Discover
+	{"65", "65", 16, 19},     // Discover
+	{"300", "305", 14, 19},   // Diners Club
+	{"36", "36", 14, 19},     // Diners Club
+	{"38", "39", 14, 19},     // Diners Club
+	{"3528", "3589", 16, 19}, // JCB
+	{"62", "62", 16, 19},     // UnionPay
+}
+
+// validCard checks that a match is a card number: any long enough number can pass the Luhn check,
+// so the number must also be written like a card number or belong to a known card network.
+func validCard(match string) bool {
+	return validLuhn(match) && (cardLayout(match) || knownIIN(digits(match)))
+}
+
+// cardLayout tells whether a number is written in groups like on a card, e.g. 4-4-4-4 or 4-6-5,
+// with a single kind of separator.
+func cardLayout(match string) bool {
+	separator := strings.IndexAny(match, " -")
+	if separator == -1 {
+		return false
+	}
+	groups := strings.Split(match, match[separator:separator+1])
+
+	var lengths []int
+	for _, group := range groups {
+		if len(group) == 0 || len(digits(group)) != len(group) {
+			return false
+		}
+		lengths = append(lengths, len(group))
+	}
+
+	switch {
+	case slices.Equal(lengths, []int{4, 6, 5}), slices.Equal(lengths, []int{4, 6, 4}):
+		return true
+	case len(lengths) < 4:
+		return false
+	}
+	for _, length := range lengths[:len(lengths)-1] {
+		if length != 4 {
+			return false
+		}
+	}
+	return lengths[len(lengths)-1] <= 4
+}
+
+func knownIIN(number string) bool {
+	for _, network := range cardNetworks {
+		if len(number) < network.minLength || len(number) > network.maxLength {
+			continue
+		}
+		prefix := number[:len(network.from)]
+		if prefix >= network.from && prefix <= network.to {
+			return true
+		}
+	}
+	return false
+}
+
+func validLuhn(match string) bool {
+	number := digits(match)
+	if len(number) < 13 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func validIBAN(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	// Move the country code and the check digits to the end, and convert the letters to numbers.
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(strconv.Itoa(int(r - 'A' + 10)))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validSSN(match string) bool {
+	area, group, serial := match[0:3], match[4:6], match[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func validNINO(match string) bool {
+	switch strings.ToUpper(match[:2]) {
+	case "BG", "GB", "NK", "KN", "TN", "NT", "ZZ":
+		return false
+	}
+	return true
+}
+
+func validNIR(match string) bool {
+	nir := strings.ReplaceAll(match, " ", "")
+	// Corsica's departments 2A and 2B are 19 and 18 for the key.
+	nir = strings.NewReplacer("2A", "19", "2B", "18").Replace(nir[:7]) + nir[7:]
+
+	number, ok := new(big.Int).SetString(nir[:13], 10)
+	if !ok {
+		return false
+	}
+	key, ok := new(big.Int).SetString(nir[13:], 10)
+	if !ok {
+		return false
+	}
+	return 97-new(big.Int).Mod(number, big.NewInt(97)).Int64() == key.Int64()
+}
+
+func validDNI(match string) bool {
+	dni := strings.ReplaceAll(match, "-", "")
+	number, ok := new(big.Int).SetString(dni[:8], 10)
+	if !ok {
+		return false
+	}
+	return "TRWAGMYFPDXBNJZSQVHLCKE"[number.Int64()%23] == dni[8]
+}
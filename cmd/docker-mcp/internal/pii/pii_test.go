@@ -0,0 +1,111 @@
+package pii
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findCategories(t *testing.T, locales []string, text string) []Category {
+	t.Helper()
+
+	scanner, err := NewScanner(locales)
+	require.NoError(t, err)
+
+	var categories []Category
+	for _, finding := range scanner.Find(text) {
+		categories = append(categories, finding.Category)
+	}
+	return categories
+}
+
+func TestDetectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		locales  []string
+		text     string
+		expected []Category
+	}{
+		{name: "email", text: "write to jane.doe@example.com", expected: []Category{Email}},
+		{name: "credit card", text: "card 4111 1111 1111 1111 ok", expected: []Category{CreditCard}},
+		{name: "invalid credit card", text: "order 4111 1111 1111 1112", expected: nil},
+		{name: "compact credit card", text: "card 378282246310005", expected: []Category{CreditCard}},
+		{name: "number of an unknown network", text: "order 1234567812345670", expected: nil},
+		{name: "number of an unknown network as a card", text: "card 1234-5678-1234-5670", expected: []Category{CreditCard}},
+		{name: "iban", text: "pay to GB82 WEST 1234 5698 7654 32", expected: []Category{IBAN}},
+		{name: "compact iban", text: "DE89370400440532013000", expected: []Category{IBAN}},
+		{name: "invalid iban", text: "GB82 WEST 1234 5698 7654 33", expected: nil},
+		{name: "international phone", text: "call +33 6 12 34 56 78", expected: []Category{Phone}},
+		{name: "us phone", locales: []string{"us"}, text: "call (415) 555-2671", expected: []Category{Phone}},
+		{name: "ssn", locales: []string{"us"}, text: "ssn 123-45-6789", expected: []Category{NationalID}},
+		{name: "invalid ssn", locales: []string{"us"}, text: "ssn 000-45-6789", expected: nil},
+		{name: "nino", locales: []string{"gb"}, text: "NI AB 12 34 56 C", expected: []Category{NationalID}},
+		{name: "nir", locales: []string{"fr"}, text: "1 84 12 76 451 089 46", expected: []Category{NationalID}},
+		{name: "invalid nir", locales: []string{"fr"}, text: "1 84 12 76 451 089 47", expected: nil},
+		{name: "es phone", locales: []string{"es"}, text: "call 612 345 678", expected: []Category{Phone}},
+		{name: "no locale", text: "call 612 345 678 or (415) 555-2671, ssn 123-45-6789", expected: nil},
+		{name: "dni", locales: []string{"es"}, text: "DNI 12345678Z", expected: []Category{NationalID}},
+		{name: "invalid dni", locales: []string{"es"}, text: "DNI 12345678A", expected: nil},
+		{name: "other locale", locales: []string{"gb"}, text: "DNI 12345678Z", expected: nil},
+		{name: "several", text: "jane@example.com, +44 20 7946 0958", expected: []Category{Email, Phone}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, findCategories(t, test.locales, test.text))
+		})
+	}
+}
+
+func TestUnknownLocale(t *testing.T) {
+	_, err := NewScanner([]string{"xx"})
+	require.ErrorContains(t, err, `unknown locale "xx"`)
+}
+
+func TestPolicies(t *testing.T) {
+	var policies Policies
+	for _, spec := range []string{"crm:email,phone=tokenize", "crm=block", "fetch=mask"} {
+		policy, err := ParsePolicy(spec)
+		require.NoError(t, err)
+		policies = append(policies, policy)
+	}
+
+	assert.Equal(t, Tokenize, policies.Action("crm", Email))
+	assert.Equal(t, Block, policies.Action("crm", CreditCard))
+	assert.Equal(t, Mask, policies.Action("fetch", IBAN))
+	assert.Equal(t, Allow, policies.Action("github", Email))
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	for _, spec := range []string{"crm", "crm=hide", "=mask", "crm:address=mask", "[=mask"} {
+		_, err := ParsePolicy(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestVault(t *testing.T) {
+	vault := NewVault()
+
+	token := vault.Tokenize("session", Email, "jane@example.com")
+	assert.Regexp(t, `^\[EMAIL:[0-9a-f]{8}\]$`, token)
+	assert.Equal(t, token, vault.Tokenize("session", Email, "jane@example.com"))
+
+	all := func(Category) bool { return true }
+	none := func(Category) bool { return false }
+	assert.Equal(t, "to jane@example.com", vault.Detokenize("session", "to "+token, all))
+	assert.Equal(t, "to "+token, vault.Detokenize("session", "to "+token, none))
+	assert.Equal(t, "to "+token, vault.Detokenize("other", "to "+token, all))
+}
+
+func TestVaultForgetsIdleSessions(t *testing.T) {
+	vault := NewVault()
+	now := time.Now()
+	vault.now = func() time.Time { return now }
+
+	token := vault.Tokenize("session", Phone, "+33 6 12 34 56 78")
+	now = now.Add(vaultIdleTimeout + time.Minute)
+	vault.Tokenize("other", Phone, "+33 6 12 34 56 78")
+
+	assert.Equal(t, token, vault.Detokenize("session", token, func(Category) bool { return true }))
+}
@@ -0,0 +1,79 @@
+package pii
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Action is what is done with personal data.
+type Action string
+
+const (
+	// Allow lets personal data through.
+	Allow Action = "allow"
+	// Mask replaces personal data with its category, e.g. [EMAIL].
+	Mask Action = "mask"
+	// Tokenize replaces the personal data returned by a server with tokens, e.g. [EMAIL:3f2a9c1e],
+	// that are replaced back with the data when sent to servers, within the same session.
+	Tokenize Action = "tokenize"
+	// Block fails the requests with personal data.
+	Block Action = "block"
+)
+
+// Policy is the action applied to some categories of personal data sent to or returned by some
+// servers.
+type Policy struct {
+	// Server is a glob pattern.
+	Server string
+	// Categories are the categories the policy applies to. Empty means all.
+	Categories []Category
+	Action     Action
+}
+
+// ParsePolicy parses a policy given as server=action or server:category,category=action.
+func ParsePolicy(spec string) (Policy, error) {
+	target, action, found := strings.Cut(spec, "=")
+	if !found {
+		return Policy{}, fmt.Errorf("invalid pii policy %q, expected server[:categories]=action", spec)
+	}
+
+	policy := Policy{Action: Action(action)}
+	if !slices.Contains([]Action{Allow, Mask, Tokenize, Block}, policy.Action) {
+		return Policy{}, fmt.Errorf("invalid pii action %q, expected allow, mask, tokenize or block", action)
+	}
+
+	server, categories, _ := strings.Cut(target, ":")
+	if _, err := path.Match(server, ""); err != nil || server == "" {
+		return Policy{}, fmt.Errorf("invalid server pattern %q in pii policy", server)
+	}
+	policy.Server = server
+
+	if categories != "" {
+		for category := range strings.SplitSeq(categories, ",") {
+			if !slices.Contains(Categories, Category(category)) {
+				return Policy{}, fmt.Errorf("unknown pii category %q, expected one of %v", category, Categories)
+			}
+			policy.Categories = append(policy.Categories, Category(category))
+		}
+	}
+	return policy, nil
+}
+
+// Policies are evaluated in order. The first one that applies wins.
+type Policies []Policy
+
+// Action returns the action for a category of personal data sent to or returned by a server.
+// Personal data is allowed by default.
+func (p Policies) Action(serverName string, category Category) Action {
+	for _, policy := range p {
+		if matched, _ := path.Match(policy.Server, serverName); !matched {
+			continue
+		}
+		if len(policy.Categories) == 0 || slices.Contains(policy.Categories, category) {
+			return policy.Action
+		}
+	}
+	return Allow
+}
@@ -0,0 +1,66 @@
+package pii
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Finding is personal data found in a text.
+type Finding struct {
+	Category Category
+	Start    int
+	End      int
+}
+
+// Scanner finds personal data, with the detectors of some locales.
+type Scanner struct {
+	detectors []detector
+}
+
+// NewScanner creates a scanner with the detectors that apply everywhere, and those of the given
+// locales. The local phone numbers and national IDs are too close to other numbers to be detected
+// in every locale: without locales, none of them are.
+func NewScanner(locales []string) (*Scanner, error) {
+	for _, locale := range locales {
+		if !slices.Contains(Locales, locale) {
+			return nil, fmt.Errorf("unknown locale %q, expected one of %v", locale, Locales)
+		}
+	}
+
+	scanner := &Scanner{}
+	for _, d := range detectors {
+		if d.locale == "" || slices.Contains(locales, d.locale) {
+			scanner.detectors = append(scanner.detectors, d)
+		}
+	}
+	return scanner, nil
+}
+
+// Find returns the personal data found in a text, sorted by position. When findings overlap, only
+// the one with the category of highest priority is kept.
+func (s *Scanner) Find(text string) []Finding {
+	var candidates []Finding
+	for _, d := range s.detectors {
+		for _, span := range d.re.FindAllStringIndex(text, -1) {
+			if d.valid != nil && !d.valid(text[span[0]:span[1]]) {
+				continue
+			}
+			candidates = append(candidates, Finding{Category: d.category, Start: span[0], End: span[1]})
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b Finding) int {
+		return slices.Index(Categories, a.Category) - slices.Index(Categories, b.Category)
+	})
+	var findings []Finding
+	for _, candidate := range candidates {
+		if !slices.ContainsFunc(findings, func(f Finding) bool {
+			return candidate.Start < f.End && f.Start < candidate.End
+		}) {
+			findings = append(findings, candidate)
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b Finding) int { return a.Start - b.Start })
+	return findings
+}
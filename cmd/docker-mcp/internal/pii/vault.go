@@ -0,0 +1,117 @@
+package pii
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// vaultIdleTimeout is how long the tokens of a session are kept after their last use.
+	vaultIdleTimeout = 24 * time.Hour
+	// maxTokensPerSession bounds the personal data kept for each session. Past it, personal data
+	// is tokenized without being kept, so it can't be restored.
+	maxTokensPerSession = 10000
+)
+
+var tokenPattern = regexp.MustCompile(`\[(EMAIL|PHONE|IBAN|CREDIT_CARD|NATIONAL_ID):([0-9a-f]{8})\]`)
+
+// Label is how personal data of a category is referred to in masks and tokens, e.g. CREDIT_CARD.
+func Label(category Category) string {
+	return strings.ToUpper(strings.ReplaceAll(string(category), "-", "_"))
+}
+
+// MaskOf returns what personal data of a category is masked with, e.g. [EMAIL].
+func MaskOf(category Category) string {
+	return "[" + Label(category) + "]"
+}
+
+// Vault keeps the personal data replaced with tokens, for each session.
+type Vault struct {
+	key []byte
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*vaultSession
+}
+
+type vaultSession struct {
+	values   map[string]string
+	lastUsed time.Time
+}
+
+// NewVault creates an empty vault, with a random key.
+func NewVault() *Vault {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	return &Vault{
+		key:      key,
+		now:      time.Now,
+		sessions: map[string]*vaultSession{},
+	}
+}
+
+// Tokenize replaces personal data with a token. The same data gets the same token within a session.
+func (v *Vault) Tokenize(sessionID string, category Category, value string) string {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(sessionID + "\x00" + value))
+	token := "[" + Label(category) + ":" + hex.EncodeToString(mac.Sum(nil))[:8] + "]"
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	session := v.session(sessionID)
+	if len(session.values) < maxTokensPerSession {
+		session.values[token] = value
+	}
+	return token
+}
+
+// Detokenize replaces the tokens of a session with the personal data they stand for, if restore
+// allows it for their category.
+func (v *Vault) Detokenize(sessionID, text string, restore func(Category) bool) string {
+	if !strings.Contains(text, "[") {
+		return text
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	session := v.session(sessionID)
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		value, found := session.values[token]
+		if !found {
+			return token
+		}
+		label := tokenPattern.FindStringSubmatch(token)[1]
+		category := Category(strings.ReplaceAll(strings.ToLower(label), "_", "-"))
+		if !restore(category) {
+			return token
+		}
+		return value
+	})
+}
+
+// session returns the tokens of a session, and forgets the sessions that are idle. v.mu is held.
+func (v *Vault) session(sessionID string) *vaultSession {
+	now := v.now()
+	for id, session := range v.sessions {
+		if now.Sub(session.lastUsed) > vaultIdleTimeout {
+			delete(v.sessions, id)
+		}
+	}
+
+	session, found := v.sessions[sessionID]
+	if !found {
+		session = &vaultSession{values: map[string]string{}}
+		v.sessions[sessionID] = session
+	}
+	session.lastUsed = now
+	return session
+}
@@ -0,0 +1,180 @@
+package promptinjection
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Removed replaces the suspicious parts of a text that are removed, except the hidden characters.
+const Removed = "[removed]"
+
+// Kind is a kind of prompt-injection heuristic.
+type Kind string
+
+const (
+	// Pattern is a known injection pattern, e.g. "ignore all previous instructions".
+	Pattern Kind = "pattern"
+	// HiddenUnicode is a character the model reads but users don't see: zero-width characters,
+	// bidirectional controls and Unicode tags.
+	HiddenUnicode Kind = "hidden-unicode"
+	// Instruction is a suspicious instruction addressed to the model, e.g. to call a tool.
+	Instruction Kind = "instruction"
+)
+
+// Finding is something suspicious found in a text.
+type Finding struct {
+	Kind Kind
+	// Name identifies the heuristic.
+	Name  string
+	Start int
+	End   int
+}
+
+type heuristic struct {
+	kind Kind
+	name string
+	re   *regexp.Regexp
+}
+
+var heuristics = []heuristic{
+	{
+		kind: Pattern,
+		name: "ignore-instructions",
+		re: regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\s+` +
+			`(?:all\s+|any\s+|the\s+)?(?:previous|prior|above|earlier|preceding|your)\s+` +
+			`(?:instructions|prompts?|rules|directions|context)`),
+	},
+	{
+		kind: Pattern,
+		name: "new-instructions",
+		re:   regexp.MustCompile(`(?i)\b(?:new|updated|real|actual)\s+(?:system\s+)?instructions\s*:`),
+	},
+	{
+		kind: Pattern,
+		name: "role-override",
+		re: regexp.MustCompile(`(?i)\byou\s+are\s+(?:now|no\s+longer)\b|` +
+			`\b(?:enter|switch\s+to|enable)\s+(?:developer|god|jailbreak|DAN)\s+mode\b`),
+	},
+	{
+		kind: Pattern,
+		name: "system-prompt",
+		re: regexp.MustCompile(`(?i)\b(?:reveal|print|show|repeat|output)\s+(?:your|the)\s+` +
+			`(?:system\s+prompt|initial\s+instructions|hidden\s+instructions)`),
+	},
+	{
+		kind: Pattern,
+		name: "chat-template",
+		re: regexp.MustCompile(`(?i)<\|(?:im_start|im_end|system|user|assistant|endoftext)\|>|` +
+			`\[/?INST\]|<</?SYS>>|</?(?:system|assistant)>`),
+	},
+	{
+		kind: Instruction,
+		name: "addressed-to-model",
+		re: regexp.MustCompile(`(?i)\b(?:AI|assistant|model|LLM|agent|chatbot)s?\s*[,:]?\s+` +
+			`(?:must|should|shall|needs?\s+to|is\s+required\s+to|please)\s+(?:now\s+)?` +
+			`(?:call|run|execute|use|invoke|send|email|post|fetch|delete|ignore|reply|respond)\b`),
+	},
+	{
+		kind: Instruction,
+		name: "tool-call",
+		re: regexp.MustCompile(`(?i)\b(?:call|invoke|execute|run|use)\s+the\s+[\w.\-]+\s+tool\b` +
+			`[^.\n]{0,80}\b(?:with|passing|and\s+send)\b`),
+	},
+	{
+		kind: Instruction,
+		name: "exfiltration",
+		re: regexp.MustCompile(`(?i)\b(?:send|post|upload|forward|leak|exfiltrate|email)\b[^.\n]{0,80}` +
+			`\b(?:password|secret|token|api\s*key|credentials?|ssh\s+key|conversation|chat\s+history)\b` +
+			`[^.\n]{0,80}\b(?:to|at)\b\s+(?:https?://|[\w.+\-]+@)`),
+	},
+	{
+		kind: Instruction,
+		name: "conceal",
+		re: regexp.MustCompile(`(?i)\b(?:do\s+not|don't|never)\s+(?:tell|inform|` +
+			`(?:mention|reveal|show)\s+(?:this\s+)?to)\s+the\s+user\b`),
+	},
+}
+
+// hidden tells whether a character is invisible to users but read by models.
+func hidden(r rune) bool {
+	switch {
+	case r >= 0x200B && r <= 0x200F: // zero-width spaces and joiners, LTR/RTL marks
+		return true
+	case r >= 0x202A && r <= 0x202E: // bidirectional embeddings and overrides
+		return true
+	case r >= 0x2060 && r <= 0x2064: // word joiner and invisible operators
+		return true
+	case r >= 0x2066 && r <= 0x2069: // bidirectional isolates
+		return true
+	case r == 0xFEFF: // zero-width no-break space
+		return true
+	case r >= 0xE0000 && r <= 0xE007F: // tags, which can spell invisible ASCII text
+		return true
+	}
+	return false
+}
+
+func hiddenFinding(start, end int) Finding {
+	return Finding{Kind: HiddenUnicode, Name: string(HiddenUnicode), Start: start, End: end}
+}
+
+// Find returns the suspicious parts of a text, sorted by position. Consecutive hidden characters
+// are reported together.
+func Find(text string) []Finding {
+	var findings []Finding
+
+	for _, h := range heuristics {
+		for _, span := range h.re.FindAllStringIndex(text, -1) {
+			findings = append(findings,
+				Finding{Kind: h.kind, Name: h.name, Start: span[0], End: span[1]})
+		}
+	}
+
+	start := -1
+	for i, r := range text {
+		switch {
+		case hidden(r) && start < 0:
+			start = i
+		case !hidden(r) && start >= 0:
+			findings = append(findings, hiddenFinding(start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		findings = append(findings, hiddenFinding(start, len(text)))
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int { return a.Start - b.Start })
+	return findings
+}
+
+// Remove removes the hidden characters from a text and replaces the other findings with
+// [removed].
+func Remove(text string, findings []Finding) string {
+	var (
+		removed strings.Builder
+		last    int
+	)
+	for _, finding := range findings {
+		// Overlapping findings are removed together.
+		if finding.Start < last {
+			last = max(last, finding.End)
+			continue
+		}
+		removed.WriteString(text[last:finding.Start])
+		if finding.Kind != HiddenUnicode {
+			removed.WriteString(Removed)
+		}
+		last = finding.End
+	}
+	removed.WriteString(text[last:])
+
+	// Hidden characters inside other findings are gone already, but not those they overlap with.
+	return strings.Map(func(r rune) rune {
+		if hidden(r) {
+			return -1
+		}
+		return r
+	}, removed.String())
+}
@@ -0,0 +1,191 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Recorder writes the exchanges with the servers to a directory. The files of the servers are
+// overwritten the first time they're written to.
+type Recorder struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*os.File
+	err   error
+}
+
+func NewRecorder(dir string) (*Recorder, error) {
+	// The exchanges can contain secrets and personal data.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		dir:   dir,
+		files: map[string]*os.File{},
+	}, nil
+}
+
+// Transport records the exchanges going through the transport to a server.
+func (r *Recorder) Transport(serverName string, transport mcp.Transport) mcp.Transport {
+	return &recordingTransport{
+		Transport:  transport,
+		recorder:   r,
+		serverName: serverName,
+	}
+}
+
+// Close closes the files and returns the first error met while recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := []error{r.err}
+	for _, file := range r.files {
+		errs = append(errs, file.Close())
+	}
+	r.files = map[string]*os.File{}
+	return errors.Join(errs...)
+}
+
+// RecordToolCall records a call to a tool that doesn't go through a transport, such as the tools
+// running in their own container.
+func (r *Recorder) RecordToolCall(serverName string, params *mcp.CallToolParams, result *mcp.CallToolResult) {
+	paramsBuf, err := json.Marshal(params)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	resultBuf, err := json.Marshal(result)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+
+	r.record(serverName, Exchange{
+		Method: "tools/call",
+		Params: paramsBuf,
+		Result: resultBuf,
+	})
+}
+
+func (r *Recorder) record(serverName string, exchange Exchange) {
+	buf, err := json.Marshal(exchange)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, found := r.files[serverName]
+	if !found {
+		file, err = os.OpenFile(filepath.Join(r.dir, fileName(serverName)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			r.err = errors.Join(r.err, err)
+			return
+		}
+		r.files[serverName] = file
+	}
+	if _, err := file.Write(append(buf, '\n')); err != nil {
+		r.err = errors.Join(r.err, err)
+	}
+}
+
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = errors.Join(r.err, err)
+}
+
+type recordingTransport struct {
+	mcp.Transport
+	recorder   *Recorder
+	serverName string
+}
+
+func (t *recordingTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingConnection{
+		Connection: conn,
+		recorder:   t.recorder,
+		serverName: t.serverName,
+		pending:    map[jsonrpc.ID]*jsonrpc.Request{},
+	}, nil
+}
+
+// recordingConnection pairs the requests written to a server with the responses read from it.
+// The notifications, and the requests sent by the server, aren't recorded.
+type recordingConnection struct {
+	mcp.Connection
+	recorder   *Recorder
+	serverName string
+
+	mu      sync.Mutex
+	pending map[jsonrpc.ID]*jsonrpc.Request
+}
+
+func (c *recordingConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if req, ok := msg.(*jsonrpc.Request); ok && req.ID.IsValid() {
+		c.mu.Lock()
+		c.pending[req.ID] = req
+		c.mu.Unlock()
+	}
+
+	return c.Connection.Write(ctx, msg)
+}
+
+func (c *recordingConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err != nil {
+		return msg, err
+	}
+
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok {
+		return msg, nil
+	}
+	c.mu.Lock()
+	req, found := c.pending[resp.ID]
+	delete(c.pending, resp.ID)
+	c.mu.Unlock()
+	if !found {
+		return msg, nil
+	}
+
+	// The wire format carries the error as sent by the server.
+	buf, err := jsonrpc.EncodeMessage(resp)
+	if err != nil {
+		c.recorder.fail(err)
+		return msg, nil
+	}
+	var wire struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(buf, &wire); err != nil {
+		c.recorder.fail(err)
+		return msg, nil
+	}
+
+	c.recorder.record(c.serverName, Exchange{
+		Method: req.Method,
+		Params: req.Params,
+		Result: wire.Result,
+		Error:  wire.Error,
+	})
+	return msg, nil
+}
@@ -0,0 +1,26 @@
+// Package recording records the JSON-RPC exchanges between the gateway and the MCP servers, and
+// replays them instead of running the servers, so that agents can be tested deterministically.
+//
+// The exchanges with each server are kept in <dir>/<server>.jsonl, one request and its response
+// per line, in the order the responses were received.
+package recording
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// Exchange is a request sent to a server and its response.
+type Exchange struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// fileName is the name of the file where the exchanges with a server are kept.
+func fileName(serverName string) string {
+	return unsafeChars.ReplaceAllString(serverName, "_") + ".jsonl"
+}
@@ -0,0 +1,186 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type weatherArgs struct {
+	City  string `json:"city"`
+	Units string `json:"units,omitempty"`
+}
+
+// record calls the weather tool of a server for each city, and records the exchanges in a directory.
+func record(t *testing.T, cities ...string) string {
+	t.Helper()
+
+	calls := 0
+	server := mcp.NewServer(&mcp.Implementation{Name: "weather"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "forecast"},
+		func(_ context.Context, _ *mcp.CallToolRequest, args weatherArgs) (*mcp.CallToolResult, any, error) {
+			calls++
+			return &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("%s: sunny (call %d)", args.City, calls)},
+			}}, nil, nil
+		})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+
+	session := connect(t, recorder.Transport("docker/weather", clientTransport))
+	for _, city := range cities {
+		_, err := session.CallTool(t.Context(), &mcp.CallToolParams{
+			Name:      "forecast",
+			Arguments: map[string]any{"city": city, "units": "metric"},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, session.Close())
+	require.NoError(t, recorder.Close())
+
+	return dir
+}
+
+func connect(t *testing.T, transport mcp.Transport) *mcp.ClientSession {
+	t.Helper()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(t.Context(), transport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func replay(t *testing.T, dir string, options ReplayOptions) *mcp.ClientSession {
+	t.Helper()
+
+	replayer, err := NewReplayer(dir, options)
+	require.NoError(t, err)
+	transport, err := replayer.Transport("docker/weather")
+	require.NoError(t, err)
+	return connect(t, transport)
+}
+
+func forecast(t *testing.T, session *mcp.ClientSession, args map[string]any) (string, error) {
+	t.Helper()
+
+	params := &mcp.CallToolParams{Name: "forecast", Arguments: args}
+	params.SetProgressToken("progress")
+	result, err := session.CallTool(t.Context(), params)
+	if err != nil {
+		return "", err
+	}
+	return result.Content[0].(*mcp.TextContent).Text, nil
+}
+
+func TestRecord(t *testing.T) {
+	dir := record(t, "Paris")
+
+	// The exchanges can contain secrets.
+	info, err := os.Stat(filepath.Join(dir, "docker_weather.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	buf, err := os.ReadFile(filepath.Join(dir, "docker_weather.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"method":"initialize"`)
+	assert.Contains(t, lines[1], `"method":"tools/call"`)
+	assert.Contains(t, lines[1], `Paris: sunny (call 1)`)
+}
+
+func TestReplay(t *testing.T) {
+	dir := record(t, "Paris", "Paris", "Rome")
+	session := replay(t, dir, ReplayOptions{})
+
+	// The keys are compared in canonical order, without the metadata.
+	text, err := forecast(t, session, map[string]any{"units": "metric", "city": "Rome"})
+	require.NoError(t, err)
+	assert.Equal(t, "Rome: sunny (call 3)", text)
+
+	// The responses to the same request are replayed in order, then the last one again.
+	for _, expected := range []string{"call 1", "call 2", "call 2"} {
+		text, err := forecast(t, session, map[string]any{"city": "Paris", "units": "metric"})
+		require.NoError(t, err)
+		assert.Equal(t, "Paris: sunny ("+expected+")", text)
+	}
+
+	_, err = forecast(t, session, map[string]any{"city": "Oslo", "units": "metric"})
+	require.ErrorContains(t, err, "no recorded response to tools/call")
+}
+
+func TestReplayFuzzy(t *testing.T) {
+	dir := record(t, "Paris", "Rome")
+	session := replay(t, dir, ReplayOptions{Fuzzy: true})
+
+	text, err := forecast(t, session, map[string]any{"city": "Rome", "units": "imperial"})
+	require.NoError(t, err)
+	assert.Equal(t, "Rome: sunny (call 2)", text)
+}
+
+func TestReplayIgnore(t *testing.T) {
+	dir := record(t, "Paris")
+	session := replay(t, dir, ReplayOptions{Ignore: []string{"arguments.units"}})
+
+	text, err := forecast(t, session, map[string]any{"city": "Paris", "units": "imperial"})
+	require.NoError(t, err)
+	assert.Equal(t, "Paris: sunny (call 1)", text)
+}
+
+func TestReplayUnknownServer(t *testing.T) {
+	replayer, err := NewReplayer(record(t, "Paris"), ReplayOptions{})
+	require.NoError(t, err)
+
+	_, err = replayer.Transport("github")
+	require.ErrorIs(t, err, ErrNoRecording)
+
+	_, err = NewReplayer(t.TempDir(), ReplayOptions{})
+	require.ErrorIs(t, err, ErrNoRecording)
+}
+
+func TestRecordAndReplayToolCall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	recorder.RecordToolCall("curl", &mcp.CallToolParams{
+		Name:      "curl",
+		Arguments: map[string]any{"url": "https://example.com"},
+	}, &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "<html>"}}})
+	require.NoError(t, recorder.Close())
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	replayer, err := NewReplayer(dir, ReplayOptions{})
+	require.NoError(t, err)
+
+	result, err := replayer.CallTool("curl", &mcp.CallToolParams{
+		Name:      "curl",
+		Arguments: map[string]any{"url": "https://example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "<html>", result.Content[0].(*mcp.TextContent).Text)
+
+	_, err = replayer.CallTool("curl", &mcp.CallToolParams{
+		Name:      "curl",
+		Arguments: map[string]any{"url": "https://example.org"},
+	})
+	require.ErrorContains(t, err, "no recorded response to tools/call")
+
+	_, err = replayer.CallTool("wget", &mcp.CallToolParams{Name: "wget"})
+	require.ErrorIs(t, err, ErrNoRecording)
+}
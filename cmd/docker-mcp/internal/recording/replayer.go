@@ -0,0 +1,399 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var ErrNoRecording = errors.New("no recording")
+
+// ReplayOptions configure how the requests are matched with the recorded requests.
+type ReplayOptions struct {
+	// Fuzzy falls back, when no recorded request matches, to the closest recorded request with the
+	// same method and target (tool, prompt or resource).
+	Fuzzy bool
+	// Ignore lists the params that aren't compared, as dotted paths such as arguments.requestId.
+	// A * matches any key.
+	Ignore []string
+}
+
+// Replayer answers the requests to the servers with the recorded responses.
+type Replayer struct {
+	dir     string
+	options ReplayOptions
+	servers map[string]*replayServer
+}
+
+type replayServer struct {
+	name      string
+	exchanges []Exchange
+	// keys are the canonical method and params of the exchanges.
+	keys []string
+
+	mu sync.Mutex
+	// replayed counts how many times each exchange was replayed.
+	replayed map[int]int
+}
+
+// NewReplayer reads the recordings of a directory.
+func NewReplayer(dir string, options ReplayOptions) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoRecording, dir)
+	}
+
+	replayer := &Replayer{
+		dir:     dir,
+		options: options,
+		servers: map[string]*replayServer{},
+	}
+	for _, file := range files {
+		server, err := replayer.read(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		replayer.servers[filepath.Base(file)] = server
+	}
+	return replayer, nil
+}
+
+func (r *Replayer) read(file string) (*replayServer, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &replayServer{
+		name:     strings.TrimSuffix(filepath.Base(file), ".jsonl"),
+		replayed: map[int]int{},
+	}
+	for i, line := range strings.Split(string(buf), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var exchange Exchange
+		if err := json.Unmarshal([]byte(line), &exchange); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		key, err := r.key(exchange.Method, exchange.Params)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		server.exchanges = append(server.exchanges, exchange)
+		server.keys = append(server.keys, key)
+	}
+	return server, nil
+}
+
+// Transport connects to a recorded server.
+func (r *Replayer) Transport(serverName string) (mcp.Transport, error) {
+	server, found := r.servers[fileName(serverName)]
+	if !found {
+		return nil, fmt.Errorf("%w for server %s in %s", ErrNoRecording, serverName, r.dir)
+	}
+	return &replayTransport{replayer: r, server: server}, nil
+}
+
+// CallTool answers a call to a tool that doesn't go through a transport, such as the tools running
+// in their own container, with the recorded result.
+func (r *Replayer) CallTool(serverName string, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+	server, found := r.servers[fileName(serverName)]
+	if !found {
+		return nil, fmt.Errorf("%w for server %s in %s", ErrNoRecording, serverName, r.dir)
+	}
+
+	buf, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	exchange, err := r.respond(server, &jsonrpc.Request{Method: "tools/call", Params: buf})
+	if err != nil {
+		return nil, err
+	}
+	if len(exchange.Error) > 0 {
+		var wire struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(exchange.Error, &wire); err != nil {
+			return nil, err
+		}
+		return nil, errors.New(wire.Message)
+	}
+
+	var result mcp.CallToolResult
+	if err := json.Unmarshal(exchange.Result, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// key is the method and the canonical params of a request. The metadata, such as progress tokens,
+// and the ignored params aren't part of it. The initialization is matched on its method only.
+func (r *Replayer) key(method string, params json.RawMessage) (string, error) {
+	if method == "initialize" {
+		return method, nil
+	}
+
+	value, err := r.canonical(params)
+	if err != nil {
+		return "", err
+	}
+	// Maps are marshalled with sorted keys.
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return method + " " + string(buf), nil
+}
+
+func (r *Replayer) canonical(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(params, &value); err != nil {
+		return nil, err
+	}
+	if object, ok := value.(map[string]any); ok {
+		delete(object, "_meta")
+	}
+	for _, path := range r.options.Ignore {
+		value = remove(value, strings.Split(path, "."))
+	}
+	return value, nil
+}
+
+// remove removes a dotted path from a value.
+func remove(value any, path []string) any {
+	object, ok := value.(map[string]any)
+	if !ok || len(path) == 0 {
+		return value
+	}
+
+	for key, child := range object {
+		if path[0] != "*" && path[0] != key {
+			continue
+		}
+		if len(path) == 1 {
+			delete(object, key)
+		} else {
+			object[key] = remove(child, path[1:])
+		}
+	}
+	return object
+}
+
+// respond finds the recorded response to a request. A request recorded several times gets the
+// recorded responses in order, then the last one again.
+func (r *Replayer) respond(server *replayServer, req *jsonrpc.Request) (*Exchange, error) {
+	key, err := r.key(req.Method, req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	var matches []int
+	for i := range server.exchanges {
+		if server.keys[i] == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 && r.options.Fuzzy {
+		if closest, found := r.closest(server, req); found {
+			matches = []int{closest}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no recorded response to %s from server %s", key, server.name)
+	}
+
+	match := matches[len(matches)-1]
+	for _, i := range matches {
+		if server.replayed[i] == 0 {
+			match = i
+			break
+		}
+	}
+	server.replayed[match]++
+	return &server.exchanges[match], nil
+}
+
+// closest finds the recorded request with the same method and target that has the most params in
+// common with a request.
+func (r *Replayer) closest(server *replayServer, req *jsonrpc.Request) (int, bool) {
+	value, err := r.canonical(req.Params)
+	if err != nil {
+		return 0, false
+	}
+	leaves := flatten(value)
+
+	best, bestScore := -1, 0
+	for i, exchange := range server.exchanges {
+		if exchange.Method != req.Method {
+			continue
+		}
+		recorded, err := r.canonical(exchange.Params)
+		if err != nil {
+			continue
+		}
+		recordedLeaves := flatten(recorded)
+		if recordedLeaves["name"] != leaves["name"] || recordedLeaves["uri"] != leaves["uri"] {
+			continue
+		}
+
+		score := 0
+		for path, leaf := range leaves {
+			if recordedLeaves[path] == leaf {
+				score++
+			} else {
+				score--
+			}
+		}
+		for path := range recordedLeaves {
+			if _, found := leaves[path]; !found {
+				score--
+			}
+		}
+		if best == -1 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best, best != -1
+}
+
+// flatten returns the JSON of the leaves of a value, by dotted path.
+func flatten(value any) map[string]string {
+	leaves := map[string]string{}
+
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, child := range value {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+		case []any:
+			for i, child := range value {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		default:
+			buf, _ := json.Marshal(value)
+			leaves[prefix] = string(buf)
+		}
+	}
+	walk("", value)
+
+	return leaves
+}
+
+type replayTransport struct {
+	replayer *Replayer
+	server   *replayServer
+}
+
+func (t *replayTransport) Connect(context.Context) (mcp.Connection, error) {
+	return &replayConnection{
+		replayer:  t.replayer,
+		server:    t.server,
+		responses: make(chan jsonrpc.Message, 16),
+		closed:    make(chan struct{}),
+	}, nil
+}
+
+// replayConnection answers each request written to it with the recorded response. Notifications
+// are dropped.
+type replayConnection struct {
+	replayer  *Replayer
+	server    *replayServer
+	responses chan jsonrpc.Message
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *replayConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	req, ok := msg.(*jsonrpc.Request)
+	if !ok || !req.ID.IsValid() {
+		return nil
+	}
+
+	resp, err := c.response(req)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case c.responses <- resp:
+		return nil
+	case <-c.closed:
+		return io.EOF
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *replayConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case msg := <-c.responses:
+		return msg, nil
+	case <-c.closed:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *replayConnection) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *replayConnection) SessionID() string {
+	return ""
+}
+
+// response builds the response to a request, with the recorded result or error. Requests without
+// recorded response get an error.
+func (c *replayConnection) response(req *jsonrpc.Request) (jsonrpc.Message, error) {
+	wire := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      req.ID.Raw(),
+	}
+
+	exchange, err := c.replayer.respond(c.server, req)
+	switch {
+	case err != nil:
+		wire["error"] = map[string]any{"code": -32603, "message": err.Error()}
+	case len(exchange.Error) > 0:
+		wire["error"] = exchange.Error
+	case len(exchange.Result) > 0:
+		wire["result"] = exchange.Result
+	default:
+		wire["result"] = json.RawMessage("{}")
+	}
+
+	buf, err := json.Marshal(wire)
+	if err != nil {
+		return nil, err
+	}
+	return jsonrpc.DecodeMessage(buf)
+}
@@ -0,0 +1,227 @@
+// Package resultstore keeps the tool results that are too large for the clients, so that they can
+// read them in pages, as gateway://results/<id> resources.
+package resultstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DefaultRetention       = time.Hour
+	DefaultMaxBytes        = 256 << 20
+	DefaultSessionMaxBytes = 64 << 20
+
+	// URITemplate is the template of the URIs of the stored results. The pages start at 1.
+	URITemplate = "gateway://results/{id}{?page}"
+)
+
+var (
+	ErrNotFound = errors.New("result not found")
+	ErrTooLarge = errors.New("result is larger than the store quota")
+)
+
+type Options struct {
+	// Retention is how long results are kept. Zero means DefaultRetention.
+	Retention time.Duration
+	// MaxBytes bounds the size of the results, across all sessions. The oldest results are dropped
+	// first. Zero means DefaultMaxBytes.
+	MaxBytes int64
+	// SessionMaxBytes bounds the size of the results of each session. Zero means
+	// DefaultSessionMaxBytes.
+	SessionMaxBytes int64
+}
+
+// Store keeps results in memory, for the session that produced them.
+type Store struct {
+	options Options
+	now     func() time.Time
+
+	mu      sync.Mutex
+	results map[string]*result
+	// order lists the ids of the results, the oldest first.
+	order        []string
+	bytes        int64
+	sessionBytes map[string]int64
+}
+
+type result struct {
+	sessionID string
+	pages     []string
+	size      int
+	at        time.Time
+}
+
+func New(options Options) *Store {
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+	if options.SessionMaxBytes <= 0 {
+		options.SessionMaxBytes = DefaultSessionMaxBytes
+	}
+
+	return &Store{
+		options:      options,
+		now:          time.Now,
+		results:      map[string]*result{},
+		sessionBytes: map[string]int64{},
+	}
+}
+
+// Retention is how long results are kept.
+func (s *Store) Retention() time.Duration {
+	return s.options.Retention
+}
+
+// Put stores the text of a result, split in pages of at most pageSize bytes, and returns its id
+// and its number of pages.
+func (s *Store) Put(sessionID, text string, pageSize int) (string, int, error) {
+	size := int64(len(text))
+	if size > s.options.MaxBytes || size > s.options.SessionMaxBytes {
+		return "", 0, ErrTooLarge
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", 0, err
+	}
+	id := hex.EncodeToString(buf)
+	pages := split(text, pageSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(sessionID, size)
+	s.results[id] = &result{
+		sessionID: sessionID,
+		pages:     pages,
+		size:      len(text),
+		at:        s.now(),
+	}
+	s.order = append(s.order, id)
+	s.bytes += size
+	s.sessionBytes[sessionID] += size
+
+	return id, len(pages), nil
+}
+
+// Page returns a page of a result stored by a session, and the number of pages.
+func (s *Store) Page(sessionID, id string, page int) (string, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge("", 0)
+	r, found := s.results[id]
+	// Other sessions can't read the result, nor know it exists.
+	if !found || r.sessionID != sessionID {
+		return "", 0, ErrNotFound
+	}
+	if page < 1 || page > len(r.pages) {
+		return "", 0, fmt.Errorf("page %d out of range, the result has %d pages", page, len(r.pages))
+	}
+	return r.pages[page-1], len(r.pages), nil
+}
+
+// purge drops the expired results, then the oldest results until a new result of the given size
+// fits in the quota of its session, then in the global quota. It must be called with s.mu held.
+func (s *Store) purge(sessionID string, size int64) {
+	expired := s.now().Add(-s.options.Retention)
+	s.dropIf(func(r *result) bool {
+		return r.at.Before(expired) ||
+			r.sessionID == sessionID && s.sessionBytes[sessionID]+size > s.options.SessionMaxBytes
+	})
+	s.dropIf(func(*result) bool {
+		return s.bytes+size > s.options.MaxBytes
+	})
+}
+
+// dropIf drops the results, the oldest first, for which shouldDrop returns true. It must be
+// called with s.mu held.
+func (s *Store) dropIf(shouldDrop func(*result) bool) {
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if r, found := s.results[id]; found && shouldDrop(r) {
+			s.drop(id)
+		} else if found {
+			kept = append(kept, id)
+		}
+	}
+	s.order = kept
+}
+
+// drop must be called with s.mu held.
+func (s *Store) drop(id string) {
+	r := s.results[id]
+	delete(s.results, id)
+	s.bytes -= int64(r.size)
+	s.sessionBytes[r.sessionID] -= int64(r.size)
+	if s.sessionBytes[r.sessionID] <= 0 {
+		delete(s.sessionBytes, r.sessionID)
+	}
+}
+
+// split cuts a text in pages of at most pageSize bytes, on character boundaries and, when
+// possible, after a line.
+func split(text string, pageSize int) []string {
+	if pageSize <= 0 {
+		return []string{text}
+	}
+
+	var pages []string
+	for len(text) > pageSize {
+		end := pageSize
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		if newline := strings.LastIndexByte(text[:end], '\n'); newline >= pageSize*3/4 {
+			end = newline + 1
+		}
+		if end == 0 {
+			end = pageSize
+		}
+		pages = append(pages, text[:end])
+		text = text[end:]
+	}
+	return append(pages, text)
+}
+
+// URI returns the URI of a page of a stored result.
+func URI(id string, page int) string {
+	uri := "gateway://results/" + id
+	if page > 1 {
+		uri += "?page=" + strconv.Itoa(page)
+	}
+	return uri
+}
+
+// ParseURI returns the id of the result and the page a URI refers to.
+func ParseURI(uri string) (string, int, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", 0, err
+	}
+	id := strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != "gateway" || u.Host != "results" || id == "" || strings.Contains(id, "/") {
+		return "", 0, fmt.Errorf("invalid result URI %q", uri)
+	}
+
+	page := 1
+	if value := u.Query().Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid page %q", value)
+		}
+	}
+	return id, page, nil
+}
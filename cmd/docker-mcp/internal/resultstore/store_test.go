@@ -0,0 +1,97 @@
+package resultstore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutAndPage(t *testing.T) {
+	s := New(Options{})
+
+	id, pages, err := s.Put("session", "line 1\nline 2\nline 3\n", 8)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pages)
+
+	for n, expected := range []string{"line 1\n", "line 2\n", "line 3\n"} {
+		page, total, err := s.Page("session", id, n+1)
+		require.NoError(t, err)
+		assert.Equal(t, expected, page)
+		assert.Equal(t, 3, total)
+	}
+
+	_, _, err = s.Page("session", id, 4)
+	require.Error(t, err)
+	_, _, err = s.Page("other", id, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, split("abcdefghij", 4))
+	assert.Equal(t, []string{"ab\ncd", "ef"}, split("ab\ncdef", 5))
+	// Characters aren't cut in the middle.
+	assert.Equal(t, []string{"a", "é", "é"}, split("aéé", 2))
+	assert.Equal(t, []string{"abc"}, split("abc", 0))
+}
+
+func TestRetention(t *testing.T) {
+	s := New(Options{Retention: time.Minute})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	id, _, err := s.Put("session", "result", 100)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, _, err = s.Page("session", id, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, s.bytes)
+}
+
+func TestQuotas(t *testing.T) {
+	s := New(Options{MaxBytes: 30, SessionMaxBytes: 20})
+
+	first, _, err := s.Put("a", strings.Repeat("1", 10), 100)
+	require.NoError(t, err)
+	second, _, err := s.Put("b", strings.Repeat("2", 10), 100)
+	require.NoError(t, err)
+
+	// The session quota drops the oldest result of the session.
+	third, _, err := s.Put("b", strings.Repeat("3", 15), 100)
+	require.NoError(t, err)
+	_, _, err = s.Page("b", second, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, _, err = s.Page("a", first, 1)
+	require.NoError(t, err)
+
+	// The global quota drops the oldest results of any session.
+	_, _, err = s.Put("c", strings.Repeat("4", 10), 100)
+	require.NoError(t, err)
+	_, _, err = s.Page("a", first, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, _, err = s.Page("b", third, 1)
+	require.NoError(t, err)
+
+	_, _, err = s.Put("a", strings.Repeat("5", 25), 100)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestParseURI(t *testing.T) {
+	id, page, err := ParseURI(URI("abc", 1))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", id)
+	assert.Equal(t, 1, page)
+
+	id, page, err = ParseURI(URI("abc", 3))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", id)
+	assert.Equal(t, 3, page)
+
+	for _, uri := range []string{"file:///abc", "gateway://other/abc", "gateway://results/", "gateway://results/abc?page=x"} {
+		_, _, err := ParseURI(uri)
+		require.Error(t, err, uri)
+	}
+}
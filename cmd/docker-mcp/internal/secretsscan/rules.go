@@ -3,6 +3,7 @@ package secretsscan
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -16,22 +17,62 @@ const (
 )
 
 type rule struct {
+	id         string
 	expression string
 	keywords   []string
+	// entropy is the minimum Shannon entropy of the secrets found by the rule.
+	entropy float64
+	// secretGroup is the group of the expression matching the secret. By default, it's the group
+	// named secret, if any, or the whole match.
+	secretGroup int
+	allowlist   allowlist
+
+	re *regexp.Regexp
 }
 
-func (r *rule) matches(content string) bool {
-	lower := strings.ToLower(content)
+// compile precompiles the expression of the rule.
+func (r *rule) compile() error {
+	re, err := regexp.Compile(r.expression)
+	if err != nil {
+		return fmt.Errorf("rule %s: %w", r.id, err)
+	}
+	r.re = re
 
-	for _, kw := range r.keywords {
-		if strings.Contains(lower, strings.ToLower(kw)) {
-			if regexp.MustCompile(r.expression).MatchString(content) {
-				return true
-			}
+	if r.secretGroup == 0 {
+		if index := re.SubexpIndex("secret"); index > 0 {
+			r.secretGroup = index
 		}
 	}
+	if r.secretGroup > re.NumSubexp() {
+		return fmt.Errorf("rule %s: no group %d in its regex", r.id, r.secretGroup)
+	}
+	return nil
+}
 
-	return false
+// find returns the spans of the secrets found by the rule.
+func (r *rule) find(content string, lower string) [][2]int {
+	if len(r.keywords) > 0 && !slices.ContainsFunc(r.keywords, func(kw string) bool {
+		return strings.Contains(lower, strings.ToLower(kw))
+	}) {
+		return nil
+	}
+
+	var spans [][2]int
+	for _, match := range r.re.FindAllStringSubmatchIndex(content, -1) {
+		start, end := match[2*r.secretGroup], match[2*r.secretGroup+1]
+		if start < 0 {
+			continue
+		}
+		secret := content[start:end]
+		if r.entropy > 0 && shannonEntropy(secret) < r.entropy {
+			continue
+		}
+		if r.allowlist.allows(secret) {
+			continue
+		}
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
 }
 
 func withoutWordPrefix(str string) string {
@@ -39,10 +80,10 @@ func withoutWordPrefix(str string) string {
 }
 
 // Patterns copied from github.com/aquasecurity/trivy/pkg/fanal/secret/builtin-rules.go
-var rules = sync.OnceValue(func() []rule {
-	return []rule{
+var builtinRules = sync.OnceValue(func() []*rule {
+	rules := []*rule{
 		{
-			// aws-access-key-id
+			id: "aws-access-key-id",
 			expression: withoutWordPrefix(
 				fmt.Sprintf(
 					`(?P<secret>(A3T[A-Z0-9]|AKIA|AGPA|AidA|AROA|AIPA|ANPA|ANVA|ASIA)[A-Z0-9]{16})%s%s`,
@@ -53,7 +94,7 @@ var rules = sync.OnceValue(func() []rule {
 			keywords: []string{"AKIA", "AGPA", "AidA", "AROA", "AIPA", "ANPA", "ANVA", "ASIA"},
 		},
 		{
-			// aws-secret-access-key
+			id: "aws-secret-access-key",
 			expression: fmt.Sprintf(
 				`(?i)%s%s(sec(ret)?)?_?(access)?_?key%s%s%s(?P<secret>[A-Za-z0-9\/\+=]{40})%s%s`,
 				quote,
@@ -67,434 +108,441 @@ var rules = sync.OnceValue(func() []rule {
 			keywords: []string{"key"},
 		},
 		{
-			// github-pat
+			id:         "github-pat",
 			expression: withoutWordPrefix(`?P<secret>ghp_[0-9a-zA-Z]{36}`),
 			keywords:   []string{"ghp_"},
 		},
 		{
-			// github-oauth
+			id:         "github-oauth",
 			expression: withoutWordPrefix(`?P<secret>gho_[0-9a-zA-Z]{36}`),
 			keywords:   []string{"gho_"},
 		},
 		{
-			// github-app-token
+			id:         "github-app-token",
 			expression: withoutWordPrefix(`?P<secret>(ghu|ghs)_[0-9a-zA-Z]{36}`),
 			keywords:   []string{"ghu_", "ghs_"},
 		},
 		{
-			// github-refresh-token
+			id:         "github-refresh-token",
 			expression: withoutWordPrefix(`?P<secret>ghr_[0-9a-zA-Z]{76}`),
 			keywords:   []string{"ghr_"},
 		},
 		{
-			// github-fine-grained-pat
+			id:         "github-fine-grained-pat",
 			expression: `github_pat_[a-zA-Z0-9]{22}_[a-zA-Z0-9]{59}`,
 			keywords:   []string{"github_pat_"},
 		},
 		{
-			// gitlab-pat
+			id:         "gitlab-pat",
 			expression: withoutWordPrefix(`?P<secret>glpat-[0-9a-zA-Z\-\_]{20}`),
 			keywords:   []string{"glpat-"},
 		},
 		{
-			// hugging-face-access-token
+			id:         "hugging-face-access-token",
 			expression: withoutWordPrefix(`?P<secret>hf_[A-Za-z0-9]{34,40}`),
 			keywords:   []string{"hf_"},
 		},
 		{
-			// private-key
+			id:         "private-key",
 			expression: `(?i)-----\s*?BEGIN[ A-Z0-9_-]*?PRIVATE KEY( BLOCK)?\s*?-----[\s]*?(?P<secret>[A-Za-z0-9=+/\\\r\n][A-Za-z0-9=+/\\\s]+)[\s]*?-----\s*?END[ A-Z0-9_-]*? PRIVATE KEY( BLOCK)?\s*?-----`,
 			keywords:   []string{"-----"},
 		},
 		{
-			// shopify-token
+			id:         "shopify-token",
 			expression: `shp(ss|at|ca|pa)_[a-fA-F0-9]{32}`,
 			keywords:   []string{"shpss_", "shpat_", "shpca_", "shppa_"},
 		},
 		{
-			// slack-access-token
+			id:         "slack-access-token",
 			expression: withoutWordPrefix(`?P<secret>xox[baprs]-([0-9a-zA-Z]{10,48})`),
 			keywords:   []string{"xoxb-", "xoxa-", "xoxp-", "xoxr-", "xoxs-"},
 		},
 		{
-			// stripe-publishable-token
+			id:         "stripe-publishable-token",
 			expression: withoutWordPrefix(`?P<secret>(?i)pk_(test|live)_[0-9a-z]{10,32}`),
 			keywords:   []string{"pk_test_", "pk_live_"},
 		},
 		{
-			// stripe-secret-token
+			id:         "stripe-secret-token",
 			expression: withoutWordPrefix(`?P<secret>(?i)sk_(test|live)_[0-9a-z]{10,32}`),
 			keywords:   []string{"sk_test_", "sk_live_"},
 		},
 		{
-			// pypi-upload-token
+			id:         "pypi-upload-token",
 			expression: `pypi-AgEIcHlwaS5vcmc[A-Za-z0-9\-_]{50,1000}`,
 			keywords:   []string{"pypi-AgEIcHlwaS5vcmc"},
 		},
 		{
-			// gcp-service-account
+			id:         "gcp-service-account",
 			expression: `\"type\": \"service_account\"`,
 			keywords:   []string{"\"type\": \"service_account\""},
 		},
 		{
-			// heroku-api-key
+			id:         "heroku-api-key",
 			expression: ` (?i)(?P<key>heroku[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[0-9A-F]{8}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{12})['\"]`,
 			keywords:   []string{"heroku"},
 		},
 		{
-			// slack-web-hook
+			id:         "slack-web-hook",
 			expression: `https:\/\/hooks.slack.com\/services\/[A-Za-z0-9+\/]{44,48}`,
 			keywords:   []string{"hooks.slack.com"},
 		},
 		{
-			// twilio-api-key
+			id:         "twilio-api-key",
 			expression: `SK[0-9a-fA-F]{32}`,
 			keywords:   []string{"SK"},
 		},
 		{
-			// age-secret-key
+			id:         "age-secret-key",
 			expression: `AGE-SECRET-KEY-1[QPZRY9X8GF2TVDW0S3JN54KHCE6MUA7L]{58}`,
 			keywords:   []string{"AGE-SECRET-KEY-1"},
 		},
 		{
-			// facebook-token
+			id:         "facebook-token",
 			expression: `(?i)(?P<key>facebook[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{32})['\"]`,
 			keywords:   []string{"facebook"},
 		},
 		{
-			// twitter-token
+			id:         "twitter-token",
 			expression: `(?i)(?P<key>twitter[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{35,44})['\"]`,
 			keywords:   []string{"twitter"},
 		},
 		{
-			// adobe-client-id
+			id:         "adobe-client-id",
 			expression: `(?i)(?P<key>adobe[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{32})['\"]`,
 			keywords:   []string{"adobe"},
 		},
 		{
-			// adobe-client-secret
+			id:         "adobe-client-secret",
 			expression: `(p8e-)(?i)[a-z0-9]{32}`,
 			keywords:   []string{"p8e-"},
 		},
 		{
-			// alibaba-access-key-id
+			id:         "alibaba-access-key-id",
 			expression: `([^0-9A-Za-z]|^)(?P<secret>(LTAI)(?i)[a-z0-9]{20})([^0-9A-Za-z]|$)`,
 			keywords:   []string{"LTAI"},
 		},
 		{
-			// alibaba-secret-key
+			id:         "alibaba-secret-key",
 			expression: `(?i)(?P<key>alibaba[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{30})['\"]`,
 			keywords:   []string{"alibaba"},
 		},
 		{
-			// asana-client-id
+			id:         "asana-client-id",
 			expression: `(?i)(?P<key>asana[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[0-9]{16})['\"]`,
 			keywords:   []string{"asana"},
 		},
 		{
-			// asana-client-secret
+			id:         "asana-client-secret",
 			expression: `(?i)(?P<key>asana[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{32})['\"]`,
 			keywords:   []string{"asana"},
 		},
 		{
-			// atlassian-api-token
+			id:         "atlassian-api-token",
 			expression: `(?i)(?P<key>atlassian[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{24})['\"]`,
 			keywords:   []string{"atlassian"},
 		},
 		{
-			// bitbucket-client-id
+			id:         "bitbucket-client-id",
 			expression: `(?i)(?P<key>bitbucket[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{32})['\"]`,
 			keywords:   []string{"bitbucket"},
 		},
 		{
-			// bitbucket-client-secret
+			id:         "bitbucket-client-secret",
 			expression: `(?i)(?P<key>bitbucket[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9_\-]{64})['\"]`,
 			keywords:   []string{"bitbucket"},
 		},
 		{
-			// beamer-api-token
+			id:         "beamer-api-token",
 			expression: `(?i)(?P<key>beamer[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>b_[a-z0-9=_\-]{44})['\"]`,
 			keywords:   []string{"beamer"},
 		},
 		{
-			// clojars-api-token
+			id:         "clojars-api-token",
 			expression: `(CLOJARS_)(?i)[a-z0-9]{60}`,
 			keywords:   []string{"CLOJARS_"},
 		},
 		{
-			// contentful-delivery-api-token
+			id:         "contentful-delivery-api-token",
 			expression: `(?i)(?P<key>contentful[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9\-=_]{43})['\"]`,
 			keywords:   []string{"contentful"},
 		},
 		{
-			// databricks-api-token
+			id:         "databricks-api-token",
 			expression: `dapi[a-h0-9]{32}`,
 			keywords:   []string{"dapi"},
 		},
 		{
-			// discord-api-token
+			id:         "discord-api-token",
 			expression: `(?i)(?P<key>discord[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-h0-9]{64})['\"]`,
 			keywords:   []string{"discord"},
 		},
 		{
-			// discord-client-id
+			id:         "discord-client-id",
 			expression: `(?i)(?P<key>discord[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[0-9]{18})['\"]`,
 			keywords:   []string{"discord"},
 		},
 		{
-			// discord-client-secret
+			id:         "discord-client-secret",
 			expression: `(?i)(?P<key>discord[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9=_\-]{32})['\"]`,
 			keywords:   []string{"discord"},
 		},
 		{
-			// doppler-api-token
+			id:         "doppler-api-token",
 			expression: `['\"](dp\.pt\.)(?i)[a-z0-9]{43}['\"]`,
 			keywords:   []string{"dp.pt."},
 		},
 		{
-			// dropbox-api-secret
+			id:         "dropbox-api-secret",
 			expression: `(?i)(dropbox[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"]([a-z0-9]{15})['\"]`,
 			keywords:   []string{"dropbox"},
 		},
 		{
-			// dropbox-short-lived-api-token
+			id:         "dropbox-short-lived-api-token",
 			expression: `(?i)(dropbox[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](sl\.[a-z0-9\-=_]{135})['\"]`,
 			keywords:   []string{"dropbox"},
 		},
 		{
-			// dropbox-long-lived-api-token
+			id:         "dropbox-long-lived-api-token",
 			expression: `(?i)(dropbox[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"][a-z0-9]{11}(AAAAAAAAAA)[a-z0-9\-_=]{43}['\"]`,
 			keywords:   []string{"dropbox"},
 		},
 		{
-			// duffel-api-token
+			id:         "duffel-api-token",
 			expression: `['\"]duffel_(test|live)_(?i)[a-z0-9_-]{43}['\"]`,
 			keywords:   []string{"duffel_test_", "duffel_live_"},
 		},
 		{
-			// dynatrace-api-token
+			id:         "dynatrace-api-token",
 			expression: `['\"]dt0c01\.(?i)[a-z0-9]{24}\.[a-z0-9]{64}['\"]`,
 			keywords:   []string{"dt0c01."},
 		},
 		{
-			// easypost-api-token
+			id:         "easypost-api-token",
 			expression: `['\"]EZ[AT]K(?i)[a-z0-9]{54}['\"]`,
 			keywords:   []string{"EZAK", "EZAT"},
 		},
 		{
-			// fastly-api-token
+			id:         "fastly-api-token",
 			expression: `(?i)(?P<key>fastly[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9\-=_]{32})['\"]`,
 			keywords:   []string{"fastly"},
 		},
 		{
-			// finicity-client-secret
+			id:         "finicity-client-secret",
 			expression: `(?i)(?P<key>finicity[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{20})['\"]`,
 			keywords:   []string{"finicity"},
 		},
 		{
-			// finicity-api-token
+			id:         "finicity-api-token",
 			expression: `(?i)(?P<key>finicity[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{32})['\"]`,
 			keywords:   []string{"finicity"},
 		},
 		{
-			// flutterwave-public-key
+			id:         "flutterwave-public-key",
 			expression: withoutWordPrefix(`?P<secret>FLW(PUB|SEC)K_TEST-(?i)[a-h0-9]{32}-X`),
 			keywords:   []string{"FLWSECK_TEST-", "FLWPUBK_TEST-"},
 		},
 		{
-			// flutterwave-enc-key
+			id:         "flutterwave-enc-key",
 			expression: withoutWordPrefix(`?P<secret>FLWSECK_TEST[a-h0-9]{12}`),
 			keywords:   []string{"FLWSECK_TEST"},
 		},
 		{
-			// frameio-api-token
+			id:         "frameio-api-token",
 			expression: `fio-u-(?i)[a-z0-9\-_=]{64}`,
 			keywords:   []string{"fio-u-"},
 		},
 		{
-			// gocardless-api-token
+			id:         "gocardless-api-token",
 			expression: `['\"]live_(?i)[a-z0-9\-_=]{40}['\"]`,
 			keywords:   []string{"live_"},
 		},
 		{
-			// grafana-api-token
+			id:         "grafana-api-token",
 			expression: `['\"]?eyJrIjoi(?i)[a-z0-9\-_=]{72,92}['\"]?`,
 			keywords:   []string{"eyJrIjoi"},
 		},
 		{
-			// hashicorp-tf-api-token
+			id:         "hashicorp-tf-api-token",
 			expression: `['\"](?i)[a-z0-9]{14}\.atlasv1\.[a-z0-9\-_=]{60,70}['\"]`,
 			keywords:   []string{"atlasv1."},
 		},
 		{
-			// hubspot-api-token
+			id:         "hubspot-api-token",
 			expression: `(?i)(?P<key>hubspot[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-h0-9]{8}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{12})['\"]`,
 			keywords:   []string{"hubspot"},
 		},
 		{
-			// intercom-api-token
+			id:         "intercom-api-token",
 			expression: `(?i)(?P<key>intercom[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9=_]{60})['\"]`,
 			keywords:   []string{"intercom"},
 		},
 		{
-			// intercom-client-secret
+			id:         "intercom-client-secret",
 			expression: `(?i)(?P<key>intercom[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-h0-9]{8}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{12})['\"]`,
 			keywords:   []string{"intercom"},
 		},
 		{
-			// ionic-api-token
+			id:         "ionic-api-token",
 			expression: `(?i)(ionic[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](ion_[a-z0-9]{42})['\"]`,
 			keywords:   []string{"ionic"},
 		},
 		{
-			// jwt-token
+			id:         "jwt-token",
 			expression: `ey[a-zA-Z0-9]{17,}\.ey[a-zA-Z0-9\/\\_-]{17,}\.(?:[a-zA-Z0-9\/\\_-]{10,}={0,2})?`,
 			keywords:   []string{".eyJ"},
 		},
 		{
-			// linear-api-token
+			id:         "linear-api-token",
 			expression: `lin_api_(?i)[a-z0-9]{40}`,
 			keywords:   []string{"lin_api_"},
 		},
 		{
-			// linear-client-secret
+			id:         "linear-client-secret",
 			expression: `(?i)(?P<key>linear[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{32})['\"]`,
 			keywords:   []string{"linear"},
 		},
 		{
-			// lob-api-key
+			id:         "lob-api-key",
 			expression: `(?i)(?P<key>lob[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>(live|test)_[a-f0-9]{35})['\"]`,
 			keywords:   []string{"lob"},
 		},
 		{
-			// lob-pub-api-key
+			id:         "lob-pub-api-key",
 			expression: `(?i)(?P<key>lob[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>(test|live)_pub_[a-f0-9]{31})['\"]`,
 			keywords:   []string{"lob"},
 		},
 		{
-			// mailchimp-api-key
+			id:         "mailchimp-api-key",
 			expression: `(?i)(?P<key>mailchimp[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-f0-9]{32}-us20)['\"]`,
 			keywords:   []string{"mailchimp"},
 		},
 		{
-			// mailgun-token
+			id:         "mailgun-token",
 			expression: `(?i)(?P<key>mailgun[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>(pub)?key-[a-f0-9]{32})['\"]`,
 			keywords:   []string{"mailgun"},
 		},
 		{
-			// mailgun-signing-key
+			id:         "mailgun-signing-key",
 			expression: `(?i)(?P<key>mailgun[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-h0-9]{32}-[a-h0-9]{8}-[a-h0-9]{8})['\"]`,
 			keywords:   []string{"mailgun"},
 		},
 		{
-			// mapbox-api-token
+			id:         "mapbox-api-token",
 			expression: `(?i)(pk\.[a-z0-9]{60}\.[a-z0-9]{22})`,
 			keywords:   []string{"pk."},
 		},
 		{
-			// messagebird-api-token
+			id:         "messagebird-api-token",
 			expression: `(?i)(?P<key>messagebird[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{25})['\"]`,
 			keywords:   []string{"messagebird"},
 		},
 		{
-			// messagebird-client-id
+			id:         "messagebird-client-id",
 			expression: `(?i)(?P<key>messagebird[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-h0-9]{8}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{4}-[a-h0-9]{12})['\"]`,
 			keywords:   []string{"messagebird"},
 		},
 		{
-			// new-relic-user-api-key
+			id:         "new-relic-user-api-key",
 			expression: `['\"](NRAK-[A-Z0-9]{27})['\"]`,
 			keywords:   []string{"NRAK-"},
 		},
 		{
-			// new-relic-user-api-id
+			id:         "new-relic-user-api-id",
 			expression: `(?i)(?P<key>newrelic[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[A-Z0-9]{64})['\"]`,
 			keywords:   []string{"newrelic"},
 		},
 		{
-			// new-relic-browser-api-token
+			id:         "new-relic-browser-api-token",
 			expression: `['\"](NRJS-[a-f0-9]{19})['\"]`,
 			keywords:   []string{"NRJS-"},
 		},
 		{
-			// npm-access-token
+			id:         "npm-access-token",
 			expression: `['\"](npm_(?i)[a-z0-9]{36})['\"]`,
 			keywords:   []string{"npm_"},
 		},
 		{
-			// planetscale-password
+			id:         "planetscale-password",
 			expression: `pscale_pw_(?i)[a-z0-9\-_\.]{43}`,
 			keywords:   []string{"pscale_pw_"},
 		},
 		{
-			// planetscale-api-token
+			id:         "planetscale-api-token",
 			expression: `pscale_tkn_(?i)[a-z0-9\-_\.]{43}`,
 			keywords:   []string{"pscale_tkn_"},
 		},
 		{
-			// private-packagist-token
+			id:         "private-packagist-token",
 			expression: `packagist_[ou][ru]t_(?i)[a-f0-9]{68}`,
 			keywords:   []string{"packagist_uut_", "packagist_ort_", "packagist_out_"},
 		},
 		{
-			// postman-api-token
+			id:         "postman-api-token",
 			expression: `PMAK-(?i)[a-f0-9]{24}\-[a-f0-9]{34}`,
 			keywords:   []string{"PMAK-"},
 		},
 		{
-			// pulumi-api-token
+			id:         "pulumi-api-token",
 			expression: `pul-[a-f0-9]{40}`,
 			keywords:   []string{"pul-"},
 		},
 		{
-			// rubygems-api-token
+			id:         "rubygems-api-token",
 			expression: `rubygems_[a-f0-9]{48}`,
 			keywords:   []string{"rubygems_"},
 		},
 		{
-			// sendgrid-api-token
+			id:         "sendgrid-api-token",
 			expression: `SG\.(?i)[a-z0-9_\-\.]{66}`,
 			keywords:   []string{"SG."},
 		},
 		{
-			// sendinblue-api-token
+			id:         "sendinblue-api-token",
 			expression: `xkeysib-[a-f0-9]{64}\-(?i)[a-z0-9]{16}`,
 			keywords:   []string{"xkeysib-"},
 		},
 		{
-			// shippo-api-token
+			id:         "shippo-api-token",
 			expression: `shippo_(live|test)_[a-f0-9]{40}`,
 			keywords:   []string{"shippo_live_", "shippo_test_"},
 		},
 		{
-			// linkedin-client-secret
+			id:         "linkedin-client-secret",
 			expression: `(?i)(?P<key>linkedin[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z]{16})['\"]`,
 			keywords:   []string{"linkedin"},
 		},
 		{
-			// linkedin-client-id
+			id:         "linkedin-client-id",
 			expression: `(?i)(?P<key>linkedin[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{14})['\"]`,
 			keywords:   []string{"linkedin"},
 		},
 		{
-			// twitch-api-token
+			id:         "twitch-api-token",
 			expression: `(?i)(?P<key>twitch[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}['\"](?P<secret>[a-z0-9]{30})['\"]`,
 			keywords:   []string{"twitch"},
 		},
 		{
-			// typeform-api-token
+			id:         "typeform-api-token",
 			expression: `(?i)(?P<key>typeform[a-z0-9_ .\-,]{0,25})(=|>|:=|\|\|:|<=|=>|:).{0,5}(?P<secret>tfp_[a-z0-9\-_\.=]{59})`,
 			keywords:   []string{"typeform"},
 		},
 		{
-			// dockerconfig-secret
+			id:         "dockerconfig-secret",
 			expression: `(?i)(\.(dockerconfigjson|dockercfg):\s*\|*\s*(?P<secret>(ey|ew)+[A-Za-z0-9\/\+=]+))`,
 			keywords:   []string{"dockerc"},
 		},
 		{
-			// docker pat
+			id:         "docker-pat",
 			expression: `(?i)(dckr_pat_[-0-9a-zA-Z]{27})`,
 			keywords:   []string{"dckr_pat"},
 		},
 	}
+
+	for _, r := range rules {
+		if err := r.compile(); err != nil {
+			panic(err)
+		}
+	}
+	return rules
 })
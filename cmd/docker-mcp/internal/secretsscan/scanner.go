@@ -0,0 +1,245 @@
+package secretsscan
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// HighEntropyRuleID identifies the secrets found by their entropy, rather than by a rule.
+const HighEntropyRuleID = "high-entropy"
+
+// Redacted replaces the secrets that are redacted.
+const Redacted = "[REDACTED]"
+
+// highEntropyCandidates are the tokens whose entropy is checked.
+var highEntropyCandidates = regexp.MustCompile(`[A-Za-z0-9+/_\-]{20,}={0,2}`)
+
+// Finding is a secret found in a text.
+type Finding struct {
+	RuleID string
+	Start  int
+	End    int
+}
+
+// Scanner finds secrets with the builtin rules, extra rules and, optionally, by their entropy.
+type Scanner struct {
+	rules     []*rule
+	allowlist allowlist
+	// entropy is the minimum Shannon entropy of the tokens reported as secrets. 0 disables the
+	// detection by entropy.
+	entropy float64
+}
+
+// Default returns the scanner with the builtin rules.
+var Default = sync.OnceValue(func() *Scanner {
+	return &Scanner{rules: builtinRules()}
+})
+
+// Options configure a Scanner.
+type Options struct {
+	// RulesFile is a gitleaks compatible TOML file with extra rules.
+	RulesFile string
+	// Entropy is the minimum Shannon entropy of the tokens reported as secrets, e.g. 4.5. 0 disables
+	// the detection by entropy.
+	Entropy float64
+}
+
+// New creates a scanner with the builtin rules and the given options.
+func New(options Options) (*Scanner, error) {
+	scanner := &Scanner{
+		rules:   builtinRules(),
+		entropy: options.Entropy,
+	}
+
+	if options.RulesFile != "" {
+		rules, allowlist, err := readRules(options.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		scanner.rules = slices.Concat(scanner.rules, rules)
+		scanner.allowlist = allowlist
+	}
+
+	return scanner, nil
+}
+
+// Find returns the secrets found in a text, sorted by position.
+func (s *Scanner) Find(text string) []Finding {
+	lower := strings.ToLower(text)
+
+	var findings []Finding
+	for _, rule := range s.rules {
+		for _, span := range rule.find(text, lower) {
+			if !s.allowlist.allows(text[span[0]:span[1]]) {
+				findings = append(findings, Finding{RuleID: rule.id, Start: span[0], End: span[1]})
+			}
+		}
+	}
+
+	if s.entropy > 0 {
+		for _, span := range highEntropyCandidates.FindAllStringIndex(text, -1) {
+			token := text[span[0]:span[1]]
+			if shannonEntropy(token) >= s.entropy && !s.allowlist.allows(token) {
+				findings = append(findings, Finding{RuleID: HighEntropyRuleID, Start: span[0], End: span[1]})
+			}
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int { return a.Start - b.Start })
+	return findings
+}
+
+// Contains tells whether a text contains secrets.
+func (s *Scanner) Contains(text string) bool {
+	return len(s.Find(text)) > 0
+}
+
+// Redact masks the given secrets found in a text.
+func Redact(text string, findings []Finding) string {
+	var (
+		redacted strings.Builder
+		last     int
+	)
+	for _, finding := range findings {
+		// Overlapping secrets are redacted together.
+		if finding.Start < last {
+			last = max(last, finding.End)
+			continue
+		}
+		redacted.WriteString(text[last:finding.Start])
+		redacted.WriteString(Redacted)
+		last = finding.End
+	}
+	redacted.WriteString(text[last:])
+	return redacted.String()
+}
+
+// shannonEntropy returns the Shannon entropy of a string, in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var entropy float64
+	length := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// allowlist ignores some secrets.
+type allowlist struct {
+	regexes   []*regexp.Regexp
+	stopwords []string
+}
+
+func (a *allowlist) allows(secret string) bool {
+	for _, re := range a.regexes {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	lower := strings.ToLower(secret)
+	for _, stopword := range a.stopwords {
+		if strings.Contains(lower, strings.ToLower(stopword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitleaksConfig is the subset of the gitleaks configuration that's supported.
+type gitleaksConfig struct {
+	Rules []struct {
+		ID          string              `toml:"id"`
+		Regex       string              `toml:"regex"`
+		SecretGroup int                 `toml:"secretGroup"`
+		Entropy     float64             `toml:"entropy"`
+		Keywords    []string            `toml:"keywords"`
+		Allowlist   gitleaksAllowlist   `toml:"allowlist"`
+		Allowlists  []gitleaksAllowlist `toml:"allowlists"`
+	} `toml:"rules"`
+	Allowlist  gitleaksAllowlist   `toml:"allowlist"`
+	Allowlists []gitleaksAllowlist `toml:"allowlists"`
+}
+
+type gitleaksAllowlist struct {
+	Regexes   []string `toml:"regexes"`
+	Stopwords []string `toml:"stopwords"`
+}
+
+func readRules(path string) ([]*rule, allowlist, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, allowlist{}, err
+	}
+
+	var config gitleaksConfig
+	if err := toml.Unmarshal(buf, &config); err != nil {
+		return nil, allowlist{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var rules []*rule
+	for n, entry := range config.Rules {
+		// Rules without regex only match paths, which don't apply here.
+		if entry.Regex == "" {
+			continue
+		}
+		id := entry.ID
+		if id == "" {
+			id = fmt.Sprintf("%s#%d", path, n+1)
+		}
+
+		ruleAllowlist, err := compileAllowlist(append(entry.Allowlists, entry.Allowlist))
+		if err != nil {
+			return nil, allowlist{}, fmt.Errorf("rule %s: %w", id, err)
+		}
+		rule := &rule{
+			id:          id,
+			expression:  entry.Regex,
+			keywords:    entry.Keywords,
+			entropy:     entry.Entropy,
+			secretGroup: entry.SecretGroup,
+			allowlist:   ruleAllowlist,
+		}
+		if err := rule.compile(); err != nil {
+			return nil, allowlist{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	globalAllowlist, err := compileAllowlist(append(config.Allowlists, config.Allowlist))
+	if err != nil {
+		return nil, allowlist{}, err
+	}
+	return rules, globalAllowlist, nil
+}
+
+func compileAllowlist(allowlists []gitleaksAllowlist) (allowlist, error) {
+	var compiled allowlist
+	for _, entry := range allowlists {
+		for _, expression := range entry.Regexes {
+			re, err := regexp.Compile(expression)
+			if err != nil {
+				return allowlist{}, fmt.Errorf("allowlist: %w", err)
+			}
+			compiled.regexes = append(compiled.regexes, re)
+		}
+		compiled.stopwords = append(compiled.stopwords, entry.Stopwords...)
+	}
+	return compiled, nil
+}
@@ -1,11 +1,6 @@
 package secretsscan
 
+// ContainsSecrets tells whether a text contains secrets found by the builtin rules.
 func ContainsSecrets(text string) bool {
-	for _, rule := range rules() {
-		if rule.matches(text) {
-			return true
-		}
-	}
-
-	return false
+	return Default().Contains(text)
 }
@@ -0,0 +1,223 @@
+package sharedstate
+
+import (
+	"context"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/cache"
+)
+
+// Memory is a cache.Cache that keeps everything in memory. It's meant for tests and for a
+// single replica: it's not shared with other processes.
+type Memory struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+var _ cache.Cache = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		now:     time.Now,
+		entries: map[string]entry{},
+	}
+}
+
+// lookup must be called with m.mu held.
+func (m *Memory) lookup(key string) (entry, bool) {
+	e, found := m.entries[key]
+	if !found {
+		return entry{}, false
+	}
+	if !e.expires.IsZero() && !m.now().Before(e.expires) {
+		delete(m.entries, key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+// set must be called with m.mu held.
+func (m *Memory) set(key string, value []byte, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = m.now().Add(ttl)
+	}
+	m.entries[key] = entry{value: slices.Clone(value), expires: expires}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, found := m.lookup(key)
+	if !found {
+		return nil, cache.ErrKeyNotFound
+	}
+	return slices.Clone(e.value), nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, value, ttl)
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *Memory) Exists(_ context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, found := m.lookup(key)
+	return found, nil
+}
+
+func (m *Memory) MultiGet(_ context.Context, keys []string) (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := map[string][]byte{}
+	for _, key := range keys {
+		if e, found := m.lookup(key); found {
+			values[key] = slices.Clone(e.value)
+		}
+	}
+	return values, nil
+}
+
+func (m *Memory) MultiSet(_ context.Context, items map[string]cache.CacheItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, item := range items {
+		m.set(key, item.Value, item.TTL)
+	}
+	return nil
+}
+
+func (m *Memory) MultiDelete(_ context.Context, keys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) Keys(_ context.Context, pattern string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for _, key := range slices.Sorted(maps.Keys(m.entries)) {
+		if _, found := m.lookup(key); !found {
+			continue
+		}
+		if matched, err := path.Match(pattern, key); err != nil {
+			return nil, err
+		} else if matched {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *Memory) DeletePattern(ctx context.Context, pattern string) (int, error) {
+	keys, err := m.Keys(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), m.MultiDelete(ctx, keys)
+}
+
+func (m *Memory) Increment(_ context.Context, key string, delta int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var value int64
+	e, found := m.lookup(key)
+	if found {
+		var err error
+		if value, err = strconv.ParseInt(string(e.value), 10, 64); err != nil {
+			return 0, cache.NewCacheErrorWithKey(cache.ErrorCodeInvalidArgument,
+				"value is not an integer", "INCR", key, err)
+		}
+	}
+	value += delta
+	e.value = []byte(strconv.FormatInt(value, 10))
+	m.entries[key] = e
+
+	return value, nil
+}
+
+func (m *Memory) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return m.Increment(ctx, key, -delta)
+}
+
+func (m *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Like Redis, keys without an expiration are reported as not found.
+	e, found := m.lookup(key)
+	if !found || e.expires.IsZero() {
+		return 0, cache.ErrKeyNotFound
+	}
+	return e.expires.Sub(m.now()), nil
+}
+
+func (m *Memory) Expire(_ context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, found := m.lookup(key)
+	if !found {
+		return cache.ErrKeyNotFound
+	}
+	e.expires = m.now().Add(ttl)
+	m.entries[key] = e
+	return nil
+}
+
+func (m *Memory) Health(context.Context) error {
+	return nil
+}
+
+func (m *Memory) Info(context.Context) (*cache.CacheInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return &cache.CacheInfo{
+		Mode:      "memory",
+		TotalKeys: int64(len(m.entries)),
+	}, nil
+}
+
+func (m *Memory) FlushDB(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = map[string]entry{}
+	return nil
+}
@@ -0,0 +1,50 @@
+package sharedstate
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/cache"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/config"
+)
+
+// NewRedis connects to the Redis server of an address like redis://:password@host:6379/0.
+// Several hosts, separated by commas, connect to a cluster.
+func NewRedis(address string) (*cache.RedisCache, error) {
+	cfg, err := redisConfig(address)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewRedisCache(cfg)
+}
+
+func redisConfig(address string) (*config.RedisConfig, error) {
+	if !strings.Contains(address, "://") {
+		address = "redis://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis address: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis address %q: unsupported scheme %q", u.Redacted(), u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid redis address %q: missing host", u.Redacted())
+	}
+
+	cfg := &config.RedisConfig{
+		Addrs: strings.Split(u.Host, ","),
+	}
+	if password, ok := u.User.Password(); ok {
+		cfg.Password = password
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if cfg.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return cfg, nil
+}
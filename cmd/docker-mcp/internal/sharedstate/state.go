@@ -0,0 +1,288 @@
+// Package sharedstate keeps the state that the replicas of a gateway share, so that they can run
+// behind a load balancer: which replica owns each session, how to reach it, and which replica
+// keeps each long-lived server.
+package sharedstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/cache"
+)
+
+// DefaultTTL is how long the records of a replica survive it, if it stops without cleaning up.
+const DefaultTTL = 30 * time.Second
+
+const keyPrefix = "gateway:"
+
+// Replica is a gateway process sharing the state.
+type Replica struct {
+	ID string `json:"id"`
+	// URL is where the other replicas can forward the requests of the sessions owned by this
+	// replica. Without it, they can only hint the client or load balancer at the right replica.
+	URL string `json:"url,omitempty"`
+}
+
+// Session is the metadata of an MCP session, owned by the replica holding its connection.
+type Session struct {
+	ID      string    `json:"id"`
+	Replica string    `json:"replica"`
+	Client  string    `json:"client,omitempty"`
+	Roots   []string  `json:"roots,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// State is the view of a replica on the shared state. The records it writes expire unless it
+// keeps refreshing them with Run.
+type State struct {
+	cache   cache.Cache
+	replica Replica
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]Session
+	leases   map[string]bool
+}
+
+func New(c cache.Cache, replica Replica, ttl time.Duration) *State {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &State{
+		cache:    c,
+		replica:  replica,
+		ttl:      ttl,
+		sessions: map[string]Session{},
+		leases:   map[string]bool{},
+	}
+}
+
+// Replica returns the replica this state belongs to.
+func (s *State) Replica() Replica {
+	return s.replica
+}
+
+// Register announces the replica to the others.
+func (s *State) Register(ctx context.Context) error {
+	return s.put(ctx, replicaKey(s.replica.ID), s.replica)
+}
+
+// Run refreshes the records of the replica until the context is done.
+func (s *State) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refresh(ctx); err != nil && ctx.Err() == nil {
+				logf("! Refreshing the shared state: %s", err)
+			}
+		}
+	}
+}
+
+func (s *State) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	var leases []string
+	for name := range s.leases {
+		leases = append(leases, name)
+	}
+	s.mu.Unlock()
+
+	errs := []error{s.Register(ctx)}
+	for _, session := range sessions {
+		errs = append(errs, s.put(ctx, sessionKey(session.ID), session))
+	}
+	for _, name := range leases {
+		owner, err := s.get(ctx, ownerKey(name))
+		if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		if owner != s.replica.ID {
+			// The lease expired, and may have been taken over.
+			s.mu.Lock()
+			delete(s.leases, name)
+			s.mu.Unlock()
+			continue
+		}
+		errs = append(errs,
+			s.cache.Expire(ctx, leaseKey(name), s.ttl),
+			s.cache.Expire(ctx, ownerKey(name), s.ttl))
+	}
+
+	return errors.Join(errs...)
+}
+
+// PutSession records a session owned by this replica.
+func (s *State) PutSession(ctx context.Context, session Session) error {
+	session.Replica = s.replica.ID
+	if session.Created.IsZero() {
+		session.Created = time.Now()
+	}
+
+	s.mu.Lock()
+	s.sessions[session.ID] = session
+	s.mu.Unlock()
+
+	return s.put(ctx, sessionKey(session.ID), session)
+}
+
+// UpdateSession changes the metadata of a session owned by this replica.
+func (s *State) UpdateSession(ctx context.Context, id string, update func(*Session)) error {
+	s.mu.Lock()
+	session, found := s.sessions[id]
+	if !found {
+		s.mu.Unlock()
+		return fmt.Errorf("session %q is not owned by replica %q", id, s.replica.ID)
+	}
+	update(&session)
+	s.sessions[id] = session
+	s.mu.Unlock()
+
+	return s.put(ctx, sessionKey(id), session)
+}
+
+// Session looks up a session, whichever replica owns it.
+func (s *State) Session(ctx context.Context, id string) (Session, bool, error) {
+	var session Session
+	found, err := s.lookup(ctx, sessionKey(id), &session)
+	return session, found, err
+}
+
+// DeleteSession forgets a session owned by this replica.
+func (s *State) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	return s.cache.Delete(ctx, sessionKey(id))
+}
+
+// LookupReplica returns a replica that is still alive.
+func (s *State) LookupReplica(ctx context.Context, id string) (Replica, bool, error) {
+	var replica Replica
+	found, err := s.lookup(ctx, replicaKey(id), &replica)
+	return replica, found, err
+}
+
+// AcquireServer takes the lease on a long-lived server, unless another replica holds it. It returns
+// the replica holding the lease, which can be unknown while another replica is acquiring it.
+func (s *State) AcquireServer(ctx context.Context, name string) (string, error) {
+	s.mu.Lock()
+	held := s.leases[name]
+	s.mu.Unlock()
+	if held {
+		return s.replica.ID, nil
+	}
+
+	// Only the first increment of the counter acquires the lease.
+	count, err := s.cache.Increment(ctx, leaseKey(name), 1)
+	if err != nil {
+		return "", err
+	}
+	if count == 1 {
+		if err := s.cache.Set(ctx, ownerKey(name), []byte(s.replica.ID), s.ttl); err != nil {
+			_ = s.cache.Delete(ctx, leaseKey(name))
+			return "", err
+		}
+
+		s.mu.Lock()
+		s.leases[name] = true
+		s.mu.Unlock()
+		return s.replica.ID, s.cache.Expire(ctx, leaseKey(name), s.ttl)
+	}
+
+	owner, err := s.get(ctx, ownerKey(name))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		// The owner may have stopped before setting an expiration on the counter.
+		_ = s.cache.Expire(ctx, leaseKey(name), s.ttl)
+		return "", nil
+	}
+	return owner, err
+}
+
+// ReleaseServer gives up the lease on a long-lived server, if this replica holds it.
+func (s *State) ReleaseServer(ctx context.Context, name string) error {
+	s.mu.Lock()
+	held := s.leases[name]
+	delete(s.leases, name)
+	s.mu.Unlock()
+	if !held {
+		return nil
+	}
+
+	return s.cache.MultiDelete(ctx, []string{leaseKey(name), ownerKey(name)})
+}
+
+// Close removes the records of the replica, its sessions and its leases.
+func (s *State) Close(ctx context.Context) error {
+	s.mu.Lock()
+	keys := []string{replicaKey(s.replica.ID)}
+	for id := range s.sessions {
+		keys = append(keys, sessionKey(id))
+	}
+	for name := range s.leases {
+		keys = append(keys, leaseKey(name), ownerKey(name))
+	}
+	s.sessions = map[string]Session{}
+	s.leases = map[string]bool{}
+	s.mu.Unlock()
+
+	return s.cache.MultiDelete(ctx, keys)
+}
+
+func (s *State) put(ctx context.Context, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, key, data, s.ttl)
+}
+
+func (s *State) get(ctx context.Context, key string) (string, error) {
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *State) lookup(ctx context.Context, key string, value any) (bool, error) {
+	data, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func replicaKey(id string) string {
+	return keyPrefix + "replica:" + id
+}
+
+func sessionKey(id string) string {
+	return keyPrefix + "session:" + id
+}
+
+func leaseKey(name string) string {
+	return keyPrefix + "lease:" + name
+}
+
+func ownerKey(name string) string {
+	return keyPrefix + "lease-owner:" + name
+}
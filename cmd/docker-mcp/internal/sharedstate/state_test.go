@@ -0,0 +1,158 @@
+package sharedstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/portal/cache"
+)
+
+func TestSessions(t *testing.T) {
+	shared := NewMemory()
+	first := New(shared, Replica{ID: "first", URL: "http://first:8811"}, time.Minute)
+	second := New(shared, Replica{ID: "second"}, time.Minute)
+	require.NoError(t, first.Register(t.Context()))
+
+	require.NoError(t, first.PutSession(t.Context(), Session{ID: "session", Client: "client"}))
+	require.NoError(t, first.UpdateSession(t.Context(), "session", func(s *Session) {
+		s.Roots = []string{"file:///src"}
+	}))
+	require.Error(t, second.UpdateSession(t.Context(), "session", func(*Session) {}))
+
+	// Any replica finds the session, and how to reach its owner.
+	session, found, err := second.Session(t.Context(), "session")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "first", session.Replica)
+	assert.Equal(t, "client", session.Client)
+	assert.Equal(t, []string{"file:///src"}, session.Roots)
+
+	replica, found, err := second.LookupReplica(t.Context(), session.Replica)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "http://first:8811", replica.URL)
+
+	require.NoError(t, first.DeleteSession(t.Context(), "session"))
+	_, found, err = second.Session(t.Context(), "session")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRecordsExpireUnlessRefreshed(t *testing.T) {
+	shared := NewMemory()
+	now := time.Now()
+	shared.now = func() time.Time { return now }
+
+	state := New(shared, Replica{ID: "replica"}, time.Minute)
+	require.NoError(t, state.Register(t.Context()))
+	require.NoError(t, state.PutSession(t.Context(), Session{ID: "session"}))
+	owner, err := state.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "replica", owner)
+
+	now = now.Add(45 * time.Second)
+	require.NoError(t, state.refresh(t.Context()))
+	now = now.Add(45 * time.Second)
+
+	_, found, err := state.Session(t.Context(), "session")
+	require.NoError(t, err)
+	assert.True(t, found)
+	_, found, err = state.LookupReplica(t.Context(), "replica")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// Without refresh, the records of a stopped replica disappear.
+	other := New(shared, Replica{ID: "other"}, time.Minute)
+	now = now.Add(2 * time.Minute)
+	_, found, err = other.Session(t.Context(), "session")
+	require.NoError(t, err)
+	assert.False(t, found)
+	owner, err = other.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "other", owner)
+
+	// The previous owner notices it lost the lease.
+	require.NoError(t, state.refresh(t.Context()))
+	assert.Empty(t, state.leases)
+}
+
+func TestServerLeases(t *testing.T) {
+	shared := NewMemory()
+	first := New(shared, Replica{ID: "first"}, time.Minute)
+	second := New(shared, Replica{ID: "second"}, time.Minute)
+
+	owner, err := first.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "first", owner)
+	owner, err = first.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "first", owner)
+
+	owner, err = second.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "first", owner)
+
+	// Only the owner releases the lease.
+	require.NoError(t, second.ReleaseServer(t.Context(), "server"))
+	require.NoError(t, first.ReleaseServer(t.Context(), "server"))
+	owner, err = second.AcquireServer(t.Context(), "server")
+	require.NoError(t, err)
+	assert.Equal(t, "second", owner)
+
+	// Closing a replica releases everything it owns.
+	require.NoError(t, second.PutSession(t.Context(), Session{ID: "session"}))
+	require.NoError(t, second.Close(t.Context()))
+	keys, err := shared.Keys(t.Context(), "*")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Set(t.Context(), "a", []byte("1"), time.Second))
+	require.NoError(t, m.Set(t.Context(), "b", []byte("2"), 0))
+
+	_, err := m.TTL(t.Context(), "b")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound)
+	ttl, err := m.TTL(t.Context(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, ttl)
+
+	count, err := m.Increment(t.Context(), "a", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	now = now.Add(time.Second)
+	_, err = m.Get(t.Context(), "a")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound)
+	values, err := m.MultiGet(t.Context(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"b": []byte("2")}, values)
+
+	deleted, err := m.DeletePattern(t.Context(), "*")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestRedisConfig(t *testing.T) {
+	cfg, err := redisConfig("redis://:secret@redis:6379/2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"redis:6379"}, cfg.Addrs)
+	assert.Equal(t, "secret", cfg.Password)
+	assert.Equal(t, 2, cfg.DB)
+
+	cfg, err = redisConfig("first:6379,second:6379")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first:6379", "second:6379"}, cfg.Addrs)
+
+	_, err = redisConfig("http://redis:6379")
+	require.Error(t, err)
+	_, err = redisConfig("redis://redis:6379/db")
+	require.Error(t, err)
+}
@@ -26,7 +26,8 @@ MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAETcvGv5qypnoxKkFIQOdXZ+2H6JN8
 8kmAQrMkTb6SmJ7BY59OJIOpTwdjD5joLot6zFs1Q7HHDmkF5HOaC8zSnA==
 -----END PUBLIC KEY-----`
 
-func Verify(ctx context.Context, images []string) error {
+// Verify checks the signatures of mcp/ images, looking them up in signaturesRepository (usually mcp/signatures, or its mirror).
+func Verify(ctx context.Context, images []string, signaturesRepository string) error {
 	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(publicKey))
 	if err != nil {
 		return fmt.Errorf("pem to public key: %w", err)
@@ -37,7 +38,7 @@ func Verify(ctx context.Context, images []string) error {
 		return fmt.Errorf("loading public key: %w", err)
 	}
 
-	signatures, err := name.NewRepository("mcp/signatures")
+	signatures, err := name.NewRepository(signaturesRepository)
 	if err != nil {
 		return err
 	}
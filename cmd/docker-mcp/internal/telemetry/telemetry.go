@@ -76,6 +76,26 @@ var (
 	ResourceTemplateErrorCounter metric.Int64Counter
 	ResourceTemplatesDiscovered  metric.Int64Gauge
 	ListResourceTemplatesCounter metric.Int64Counter
+
+	// Sampling metrics
+	SamplingCounter  metric.Int64Counter
+	SamplingDuration metric.Float64Histogram
+
+	// Stream resumption metrics
+	StreamResumptionCounter metric.Int64Counter
+	ReplayedEventsCounter   metric.Int64Counter
+
+	// Secret redaction metrics
+	SecretRedactionCounter metric.Int64Counter
+
+	// Personal data metrics
+	PIIDetectionCounter metric.Int64Counter
+
+	// Prompt injection metrics
+	PromptInjectionCounter metric.Int64Counter
+
+	// Oversize result metrics
+	OversizeResultCounter metric.Int64Counter
 )
 
 // Init initializes the telemetry package with global providers
@@ -436,6 +456,124 @@ func Init() {
 		}
 	}
 
+	// Initialize sampling metrics
+	SamplingCounter, err = meter.Int64Counter("mcp.sampling.requests",
+		metric.WithDescription("Number of sampling requests from servers"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating sampling counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	SamplingDuration, err = meter.Float64Histogram("mcp.sampling.duration",
+		metric.WithDescription("Duration of sampling requests forwarded to the client"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating sampling duration histogram: %v\n",
+				err,
+			)
+		}
+	}
+
+	// Initialize stream resumption metrics
+	StreamResumptionCounter, err = meter.Int64Counter("mcp.streams.resumptions",
+		metric.WithDescription("Number of streams resumed by clients with Last-Event-ID"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating stream resumption counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	ReplayedEventsCounter, err = meter.Int64Counter("mcp.streams.replayed_events",
+		metric.WithDescription("Number of events replayed to clients resuming a stream"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating replayed events counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	// Initialize secret redaction metrics
+	SecretRedactionCounter, err = meter.Int64Counter("mcp.secrets.redactions",
+		metric.WithDescription("Number of secrets redacted from the arguments and results of tool calls"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating secret redaction counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	// Initialize personal data metrics
+	PIIDetectionCounter, err = meter.Int64Counter("mcp.pii.detections",
+		metric.WithDescription("Number of personal data found in the arguments and results of requests"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating personal data detection counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	// Initialize prompt injection metrics
+	PromptInjectionCounter, err = meter.Int64Counter("mcp.prompt_injection.detections",
+		metric.WithDescription("Number of possible prompt injections found in the results of tools and resources"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating prompt injection counter: %v\n",
+				err,
+			)
+		}
+	}
+
+	// Initialize oversize result metrics
+	OversizeResultCounter, err = meter.Int64Counter("mcp.results.oversize",
+		metric.WithDescription("Number of tool results larger than the limits, truncated or stored"),
+		metric.WithUnit("1"))
+	if err != nil {
+		// Log error but don't fail
+		if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"[MCP-TELEMETRY] Error creating oversize result counter: %v\n",
+				err,
+			)
+		}
+	}
+
 	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
 		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Metrics created successfully\n")
 	}
@@ -1038,3 +1176,161 @@ func RecordResourceTemplateList(ctx context.Context, serverName string, template
 			attribute.String("mcp.server.origin", serverName),
 		))
 }
+
+// RecordSampling records a sampling request from a server, with its outcome
+// (forwarded, denied or error)
+func RecordSampling(ctx context.Context, serverName string, outcome string) {
+	if SamplingCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Sampling request from server %s: %s\n",
+			serverName, outcome)
+	}
+
+	SamplingCounter.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+			attribute.String("mcp.sampling.outcome", outcome),
+		))
+}
+
+// RecordSamplingDuration records how long the client took to answer a sampling request
+func RecordSamplingDuration(ctx context.Context, serverName string, durationMs float64) {
+	if SamplingDuration == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Sampling duration: %s took %.2fms\n",
+			serverName, durationMs)
+	}
+
+	SamplingDuration.Record(ctx, durationMs,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+		))
+}
+
+// RecordStreamResumption records a client resuming a stream, with its outcome
+// (replayed, purged or expired)
+func RecordStreamResumption(ctx context.Context, outcome string) {
+	if StreamResumptionCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Stream resumption: %s\n", outcome)
+	}
+
+	StreamResumptionCounter.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("mcp.stream.outcome", outcome),
+		))
+}
+
+// RecordReplayedEvents records the events replayed to a client resuming a stream
+func RecordReplayedEvents(ctx context.Context, count int64) {
+	if ReplayedEventsCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Replayed %d events\n", count)
+	}
+
+	ReplayedEventsCounter.Add(ctx, count)
+}
+
+// RecordSecretRedactions records the secrets found by a rule and redacted from the arguments or
+// the result of a tool call
+func RecordSecretRedactions(
+	ctx context.Context,
+	serverName, ruleID, direction string,
+	count int64,
+) {
+	if SecretRedactionCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Redacted %d %s secrets from %s of %s\n",
+			count, ruleID, direction, serverName)
+	}
+
+	SecretRedactionCounter.Add(ctx, count,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+			attribute.String("mcp.secret.rule", ruleID),
+			attribute.String("mcp.secret.direction", direction),
+		))
+}
+
+// RecordPIIDetections records the personal data of a category found in the arguments or the result
+// of a request, and the action taken
+func RecordPIIDetections(
+	ctx context.Context,
+	serverName, category, direction, action string,
+	count int64,
+) {
+	if PIIDetectionCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Found %d %s in %s of %s (%s)\n",
+			count, category, direction, serverName, action)
+	}
+
+	PIIDetectionCounter.Add(ctx, count,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+			attribute.String("mcp.pii.category", category),
+			attribute.String("mcp.pii.direction", direction),
+			attribute.String("mcp.pii.action", action),
+		))
+}
+
+// RecordPromptInjections records the possible prompt injections found by a heuristic in the
+// content returned by a server, and the mode applied
+func RecordPromptInjections(
+	ctx context.Context,
+	serverName, heuristic, mode string,
+	count int64,
+) {
+	if PromptInjectionCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Found %d possible %s prompt injections from %s (%s)\n",
+			count, heuristic, serverName, mode)
+	}
+
+	PromptInjectionCounter.Add(ctx, count,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+			attribute.String("mcp.prompt_injection.heuristic", heuristic),
+			attribute.String("mcp.prompt_injection.mode", mode),
+		))
+}
+
+// RecordOversizeResult records a tool result larger than the limit of its server, and whether it
+// was truncated or stored
+func RecordOversizeResult(ctx context.Context, serverName, action string, size int64) {
+	if OversizeResultCounter == nil {
+		return // Telemetry not initialized
+	}
+
+	if os.Getenv("DOCKER_MCP_TELEMETRY_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "[MCP-TELEMETRY] Result of %d bytes from %s (%s)\n",
+			size, serverName, action)
+	}
+
+	OversizeResultCounter.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("mcp.server.origin", serverName),
+			attribute.String("mcp.result.action", action),
+		))
+}
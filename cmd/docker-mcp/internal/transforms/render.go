@@ -0,0 +1,153 @@
+package transforms
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// render renders a JSON value as text. The keys of the objects keep their order.
+func render(value []byte, format string) (string, error) {
+	if format == FormatJSON {
+		return string(value), nil
+	}
+
+	// JSON is YAML, and YAML nodes keep the order of the keys.
+	var document yaml.Node
+	if err := yaml.Unmarshal(value, &document); err != nil {
+		return "", err
+	}
+	if len(document.Content) == 0 {
+		return "", nil
+	}
+	node := document.Content[0]
+
+	if format == FormatTable {
+		return table(node)
+	}
+
+	blockStyle(node)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// blockStyle drops the JSON styles, so that YAML is rendered in block style with quotes only
+// where they're needed.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// table renders an array of objects with a column per key, an object with a row per key and an
+// array of scalars with a single column.
+func table(node *yaml.Node) (string, error) {
+	var (
+		columns []string
+		rows    [][]*yaml.Node
+	)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		columns = []string{"key", "value"}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			rows = append(rows, []*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+
+	case yaml.SequenceNode:
+		objects := len(node.Content) > 0
+		for _, item := range node.Content {
+			if item.Kind != yaml.MappingNode {
+				objects = false
+			}
+		}
+
+		if !objects {
+			columns = []string{"value"}
+			for _, item := range node.Content {
+				rows = append(rows, []*yaml.Node{item})
+			}
+			break
+		}
+
+		index := map[string]int{}
+		for _, item := range node.Content {
+			for i := 0; i+1 < len(item.Content); i += 2 {
+				if _, found := index[item.Content[i].Value]; !found {
+					index[item.Content[i].Value] = len(columns)
+					columns = append(columns, item.Content[i].Value)
+				}
+			}
+		}
+		for _, item := range node.Content {
+			row := make([]*yaml.Node, len(columns))
+			for i := 0; i+1 < len(item.Content); i += 2 {
+				row[index[item.Content[i].Value]] = item.Content[i+1]
+			}
+			rows = append(rows, row)
+		}
+
+	default:
+		return cell(node)
+	}
+
+	var out strings.Builder
+	out.WriteString("|")
+	for _, column := range columns {
+		out.WriteString(" " + escape(column) + " |")
+	}
+	out.WriteString("\n|")
+	for range columns {
+		out.WriteString(" --- |")
+	}
+	out.WriteString("\n")
+	for _, row := range rows {
+		out.WriteString("|")
+		for _, node := range row {
+			text, err := cell(node)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(" " + escape(text) + " |")
+		}
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+// cell renders scalars as they are, and other values as compact JSON.
+func cell(node *yaml.Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return "", err
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+var escaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
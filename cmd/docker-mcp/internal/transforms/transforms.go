@@ -0,0 +1,255 @@
+// Package transforms rewrites the JSON results of the tools, as configured in tools.yaml, with
+// jq/yq expressions and JSONPath selections, and renders them as JSON, YAML or markdown tables.
+package transforms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/yq"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+)
+
+// Pipeline transforms the results of a tool.
+type Pipeline struct {
+	steps        []config.TransformStep
+	format       string
+	outputSchema map[string]any
+	// wrapped is true when the structured results are wrapped in a {"result": ...} object, because
+	// the output schema of a tool must be of type object.
+	wrapped bool
+}
+
+// New validates the configuration of a tool and returns its pipeline.
+func New(toolConfig config.ToolConfig) (*Pipeline, error) {
+	pipeline := &Pipeline{
+		format:       FormatJSON,
+		outputSchema: toolConfig.OutputSchema,
+	}
+
+	for i, step := range toolConfig.Transform {
+		operations := 0
+		for _, value := range []string{step.JQ, step.YQ, step.JSONPath, step.Format} {
+			if value != "" {
+				operations++
+			}
+		}
+		if operations != 1 {
+			return nil, fmt.Errorf("transform step %d must have one of jq, yq, jsonpath or format", i+1)
+		}
+
+		switch {
+		case step.JQ != "":
+			if err := yq.Validate(step.JQ); err != nil {
+				return nil, fmt.Errorf("transform step %d: %w", i+1, err)
+			}
+		case step.YQ != "":
+			if err := yq.Validate(step.YQ); err != nil {
+				return nil, fmt.Errorf("transform step %d: %w", i+1, err)
+			}
+		case step.JSONPath != "":
+			if _, err := jsonpath.New(step.JSONPath); err != nil {
+				return nil, fmt.Errorf("transform step %d: invalid JSONPath '%s': %w", i+1, step.JSONPath, err)
+			}
+		case step.Format != "":
+			if i != len(toolConfig.Transform)-1 {
+				return nil, fmt.Errorf("transform step %d: format must be the last step", i+1)
+			}
+			switch step.Format {
+			case FormatJSON, FormatYAML, FormatTable:
+			default:
+				return nil, fmt.Errorf("transform step %d: invalid format %q, expected json, yaml or table",
+					i+1, step.Format)
+			}
+			pipeline.format = step.Format
+			continue
+		}
+		pipeline.steps = append(pipeline.steps, step)
+	}
+
+	if toolConfig.OutputSchema != nil && toolConfig.OutputSchema["type"] != "object" {
+		pipeline.wrapped = true
+	}
+	if _, err := pipeline.OutputSchema(); err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+
+	return pipeline, nil
+}
+
+// OutputSchema is the schema of the structured results of the tool, or nil when the transformed
+// results aren't structured.
+func (p *Pipeline) OutputSchema() (*jsonschema.Schema, error) {
+	if p.outputSchema == nil {
+		return nil, nil
+	}
+
+	outputSchema := p.outputSchema
+	if p.wrapped {
+		outputSchema = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"result": p.outputSchema},
+			"required":   []string{"result"},
+		}
+	}
+
+	buf, err := json.Marshal(outputSchema)
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(buf, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// Handler transforms the results of a tool handler. Errors and results without JSON are returned
+// as is.
+func (p *Pipeline) Handler(next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		transformed, err := p.Apply(result)
+		if err != nil {
+			return nil, fmt.Errorf("transforming the result of %s: %w", req.Params.Name, err)
+		}
+		return transformed, nil
+	}
+}
+
+// Apply transforms the structured content of a result or, without structured content, the first
+// text content that's JSON.
+func (p *Pipeline) Apply(result *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+	input, found, err := jsonInput(result)
+	if err != nil || !found {
+		return result, err
+	}
+
+	value, err := p.transform(input)
+	if err != nil {
+		return nil, err
+	}
+	text, err := render(value, p.format)
+	if err != nil {
+		return nil, err
+	}
+
+	content := []mcp.Content{&mcp.TextContent{Text: text}}
+	for _, c := range result.Content {
+		if _, ok := c.(*mcp.TextContent); !ok {
+			content = append(content, c)
+		}
+	}
+	result.Content = content
+
+	result.StructuredContent = nil
+	if p.outputSchema != nil {
+		var structured any
+		if err := json.Unmarshal(value, &structured); err != nil {
+			return nil, err
+		}
+		if p.wrapped {
+			structured = map[string]any{"result": structured}
+		}
+		result.StructuredContent = structured
+	}
+
+	return result, nil
+}
+
+func jsonInput(result *mcp.CallToolResult) ([]byte, bool, error) {
+	if result.StructuredContent != nil {
+		buf, err := json.Marshal(result.StructuredContent)
+		return buf, err == nil, err
+	}
+
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok && json.Valid([]byte(text.Text)) {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, []byte(text.Text)); err != nil {
+				return nil, false, err
+			}
+			return buf.Bytes(), true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// transform runs the steps on a JSON value and returns the resulting JSON value.
+func (p *Pipeline) transform(value []byte) ([]byte, error) {
+	for _, step := range p.steps {
+		var err error
+		switch {
+		case step.JQ != "":
+			value, err = evaluate(step.JQ, value)
+		case step.YQ != "":
+			value, err = evaluate(step.YQ, value)
+		case step.JSONPath != "":
+			value, err = selectPath(step.JSONPath, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// evaluate evaluates a jq/yq expression. When the expression yields several values, they are
+// collected in an array.
+func evaluate(expr string, value []byte) ([]byte, error) {
+	out, err := yq.Evaluate(expr, value, yq.NewJSONDecoder(), yq.NewCompactJSONEncoder())
+	if err != nil {
+		return nil, err
+	}
+
+	var values []json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var v json.RawMessage
+		if err := decoder.Decode(&v); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading the result of '%s': %w", expr, err)
+		}
+		values = append(values, v)
+	}
+
+	switch len(values) {
+	case 0:
+		return []byte("null"), nil
+	case 1:
+		return values[0], nil
+	default:
+		return json.Marshal(values)
+	}
+}
+
+func selectPath(path string, value []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	selected, err := jsonpath.Get(path, v)
+	if err != nil {
+		return nil, fmt.Errorf("selecting '%s': %w", path, err)
+	}
+	return json.Marshal(selected)
+}
@@ -0,0 +1,153 @@
+package transforms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jrmatherly/mcp-hub-gateway/cmd/docker-mcp/internal/config"
+)
+
+const issues = `{"total": 2, "items": [
+  {"number": 1, "title": "Crash | on start", "user": {"login": "alice"}, "draft": false},
+  {"number": 2, "title": "Typo", "user": {"login": "bob"}, "labels": ["docs"]}
+]}`
+
+func apply(t *testing.T, toolConfig config.ToolConfig, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+
+	pipeline, err := New(toolConfig)
+	require.NoError(t, err)
+	transformed, err := pipeline.Apply(result)
+	require.NoError(t, err)
+	return transformed
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+func TestJQ(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{JQ: `.items | map({"number": .number, "author": .user.login})`},
+	}}, textResult(issues))
+
+	assert.Equal(t, `[{"number":1,"author":"alice"},{"number":2,"author":"bob"}]`,
+		result.Content[0].(*mcp.TextContent).Text)
+	assert.Nil(t, result.StructuredContent)
+}
+
+func TestSeveralValuesAreCollected(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{YQ: `.items[].title`},
+	}}, textResult(issues))
+
+	assert.Equal(t, `["Crash | on start","Typo"]`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestJSONPath(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{JSONPath: `$.items[*].user.login`},
+	}}, textResult(issues))
+
+	assert.Equal(t, `["alice","bob"]`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestTable(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{JQ: `.items`},
+		{Format: FormatTable},
+	}}, textResult(issues))
+
+	assert.Equal(t, "| number | title | user | draft | labels |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| 1 | Crash \\| on start | {\"login\":\"alice\"} | false |  |\n"+
+		"| 2 | Typo | {\"login\":\"bob\"} |  | [\"docs\"] |\n",
+		result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestTableOfObjectAndScalars(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{Format: FormatTable},
+	}}, textResult(`{"b": 1, "a": "x"}`))
+	assert.Equal(t, "| key | value |\n| --- | --- |\n| b | 1 |\n| a | x |\n",
+		result.Content[0].(*mcp.TextContent).Text)
+
+	result = apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{Format: FormatTable},
+	}}, textResult(`["a", "b"]`))
+	assert.Equal(t, "| value |\n| --- |\n| a |\n| b |\n", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestYAML(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{
+		{JQ: `{"id": "123", "tags": .items[1].labels}`},
+		{Format: FormatYAML},
+	}}, textResult(issues))
+
+	assert.Equal(t, "id: \"123\"\ntags:\n  - docs\n", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestStructuredContent(t *testing.T) {
+	toolConfig := config.ToolConfig{
+		Transform:    []config.TransformStep{{JQ: `.total`}},
+		OutputSchema: map[string]any{"type": "integer"},
+	}
+	pipeline, err := New(toolConfig)
+	require.NoError(t, err)
+
+	schema, err := pipeline.OutputSchema()
+	require.NoError(t, err)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "integer", schema.Properties["result"].Type)
+
+	result, err := pipeline.Apply(&mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: "ignored"}},
+		StructuredContent: map[string]any{"total": 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, map[string]any{"result": float64(2)}, result.StructuredContent)
+}
+
+func TestNotJSON(t *testing.T) {
+	result := apply(t, config.ToolConfig{Transform: []config.TransformStep{{JQ: `.items`}}},
+		textResult("Not found"))
+
+	assert.Equal(t, "Not found", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestHandlerKeepsErrors(t *testing.T) {
+	pipeline, err := New(config.ToolConfig{Transform: []config.TransformStep{{JQ: `.items`}}})
+	require.NoError(t, err)
+
+	handler := pipeline.Handler(func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := textResult(issues)
+		result.IsError = true
+		return result, nil
+	})
+	result, err := handler(t.Context(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "search"}})
+	require.NoError(t, err)
+	assert.Equal(t, issues, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		steps    []config.TransformStep
+		expected string
+	}{
+		{steps: []config.TransformStep{{}}, expected: "must have one of jq, yq, jsonpath or format"},
+		{steps: []config.TransformStep{{JQ: ".a", Format: "json"}}, expected: "must have one of"},
+		{steps: []config.TransformStep{{JQ: ".items[ | "}}, expected: "invalid YQ expression"},
+		{steps: []config.TransformStep{{JSONPath: "$.[?"}}, expected: "invalid JSONPath"},
+		{steps: []config.TransformStep{{Format: "csv"}}, expected: `invalid format "csv"`},
+		{steps: []config.TransformStep{{Format: "table"}, {JQ: "."}}, expected: "format must be the last step"},
+	}
+	for _, test := range tests {
+		_, err := New(config.ToolConfig{Transform: test.steps})
+		require.ErrorContains(t, err, test.expected)
+	}
+}
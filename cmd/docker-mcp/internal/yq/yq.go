@@ -76,3 +76,28 @@ func Evaluate(
 	}
 	return []byte(result), nil
 }
+
+func NewJSONDecoder() yqlib.Decoder {
+	return yqlib.NewJSONDecoder()
+}
+
+// NewCompactJSONEncoder encodes each result as JSON, on one line, with the strings quoted.
+func NewCompactJSONEncoder() yqlib.Encoder {
+	pref := yqlib.JsonPreferences{
+		Indent:        0,
+		ColorsEnabled: false,
+		UnwrapScalar:  false,
+	}
+	return yqlib.NewJSONEncoder(pref)
+}
+
+// Validate parses a YQ expression without evaluating it.
+func Validate(yqExpr string) error {
+	yqlib.GetLogger().SetBackend(logBackend{})
+	yqlib.InitExpressionParser()
+
+	if _, err := yqlib.ExpressionParser.ParseExpression(yqExpr); err != nil {
+		return fmt.Errorf("invalid YQ expression '%s': %w", yqExpr, err)
+	}
+	return nil
+}
@@ -2,29 +2,64 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"gopkg.in/yaml.v3"
 )
 
+// CallOptions configure how a tool is called and how its result is printed.
+type CallOptions struct {
+	// ArgsJSON is a JSON object of arguments.
+	ArgsJSON string
+	// ArgsFile is a JSON or YAML file of arguments, or - to read them from stdin.
+	ArgsFile string
+	// Output is text, json, yaml or raw. text prints the duration of the call and the text of the
+	// result. raw only prints the text.
+	Output string
+	// SaveDir is where the images, audio and binary resources of the result are saved.
+	SaveDir string
+	// LogLevel is the minimum level of the server logs that are printed.
+	LogLevel string
+}
+
 func Call(
 	ctx context.Context,
 	version string,
 	gatewayArgs []string,
 	debug bool,
 	args []string,
+	options CallOptions,
 ) error {
 	if len(args) == 0 {
 		return errors.New("no tool name provided")
 	}
 	toolName := args[0]
 
+	switch options.Output {
+	case "", "text", "json", "yaml", "raw":
+	default:
+		return fmt.Errorf("invalid output %q, expected text, json, yaml or raw", options.Output)
+	}
+
+	arguments, err := readArgs(options)
+	if err != nil {
+		return err
+	}
+
 	// Initialize telemetry for CLI tool calls
 	meter := otel.GetMeterProvider().Meter("github.com/jrmatherly/mcp-hub-gateway")
 	toolCallCounter, _ := meter.Int64Counter("mcp.cli.tool.calls",
@@ -34,16 +69,44 @@ func Call(
 		metric.WithDescription("Tool call duration from CLI"),
 		metric.WithUnit("ms"))
 
-	c, err := start(ctx, version, gatewayArgs, debug)
+	// Progress and logs go to stderr, to keep stdout for the result.
+	c, err := start(ctx, version, gatewayArgs, debug, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			fmt.Fprintln(os.Stderr, progressLine(req.Params))
+		},
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			fmt.Fprintln(os.Stderr, logLine(req.Params))
+		},
+	})
 	if err != nil {
 		return fmt.Errorf("starting client: %w", err)
 	}
 	defer c.Close()
 
+	if options.LogLevel != "" {
+		// Older gateways don't forward logs.
+		_ = c.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: mcp.LoggingLevel(options.LogLevel)})
+	}
+
+	if len(args) > 1 {
+		schema, err := inputSchema(ctx, c, toolName)
+		if err != nil {
+			return err
+		}
+		coerced, err := coerceArgs(parseArgs(args[1:]), schema)
+		if err != nil {
+			return err
+		}
+		for key, value := range coerced {
+			arguments[key] = value
+		}
+	}
+
 	params := &mcp.CallToolParams{
 		Name:      toolName,
-		Arguments: parseArgs(args[1:]),
+		Arguments: arguments,
 	}
+	params.SetProgressToken("tools-call")
 
 	start := time.Now()
 	response, err := c.CallTool(ctx, params)
@@ -65,13 +128,29 @@ func Call(
 	toolCallCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
 	toolCallDuration.Record(ctx, float64(duration.Milliseconds()), metric.WithAttributes(attrs...))
 
-	fmt.Println("Tool call took:", duration)
+	switch options.Output {
+	case "json", "yaml":
+		if err := printResult(os.Stdout, response, options.Output); err != nil {
+			return err
+		}
+	case "raw":
+		if err := writeContent(os.Stdout, os.Stderr, options.SaveDir, toolName, response); err != nil {
+			return err
+		}
+	default:
+		fmt.Println("Tool call took:", duration)
 
-	if response.IsError {
-		return fmt.Errorf("error calling tool %s: %s", toolName, toText(response))
+		if response.IsError {
+			return fmt.Errorf("error calling tool %s: %s", toolName, toText(response))
+		}
+		if err := writeContent(os.Stdout, os.Stdout, options.SaveDir, toolName, response); err != nil {
+			return err
+		}
 	}
 
-	fmt.Println(toText(response))
+	if response.IsError {
+		return fmt.Errorf("tool %s returned an error", toolName)
+	}
 
 	return nil
 }
@@ -122,3 +201,263 @@ func parseArgs(args []string) map[string]any {
 
 	return parsed
 }
+
+// readArgs reads the arguments given as a file and as JSON. The JSON arguments win.
+func readArgs(options CallOptions) (map[string]any, error) {
+	arguments := map[string]any{}
+
+	if options.ArgsFile != "" {
+		var (
+			buf []byte
+			err error
+		)
+		if options.ArgsFile == "-" {
+			buf, err = io.ReadAll(os.Stdin)
+		} else {
+			buf, err = os.ReadFile(options.ArgsFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading arguments: %w", err)
+		}
+		// JSON is YAML.
+		if err := yaml.Unmarshal(buf, &arguments); err != nil {
+			return nil, fmt.Errorf("parsing arguments file %s: %w", options.ArgsFile, err)
+		}
+		if arguments == nil {
+			arguments = map[string]any{}
+		}
+	}
+
+	if options.ArgsJSON != "" {
+		var jsonArguments map[string]any
+		if err := json.Unmarshal([]byte(options.ArgsJSON), &jsonArguments); err != nil {
+			return nil, fmt.Errorf("parsing JSON arguments: %w", err)
+		}
+		for key, value := range jsonArguments {
+			arguments[key] = value
+		}
+	}
+
+	return arguments, nil
+}
+
+func inputSchema(ctx context.Context, c *mcp.ClientSession, toolName string) (*jsonschema.Schema, error) {
+	response, err := c.ListTools(ctx, &mcp.ListToolsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("listing tools: %w", err)
+	}
+	for _, tool := range response.Tools {
+		if tool.Name == toolName {
+			return tool.InputSchema, nil
+		}
+	}
+	// Let the gateway report unknown tools.
+	return nil, nil
+}
+
+// coerceArgs converts the key=value arguments to the types of the tool's input schema.
+func coerceArgs(args map[string]any, schema *jsonschema.Schema) (map[string]any, error) {
+	if schema == nil {
+		return args, nil
+	}
+
+	for key, value := range args {
+		property, found := schema.Properties[key]
+		if !found {
+			continue
+		}
+		coerced, err := coerce(value, property)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", key, err)
+		}
+		args[key] = coerced
+	}
+
+	return args, nil
+}
+
+func coerce(value any, schema *jsonschema.Schema) (any, error) {
+	schemaType := typeOf(schema)
+
+	switch value := value.(type) {
+	case nil:
+		// A flag, given without value.
+		if schemaType == "boolean" {
+			return true, nil
+		}
+		return nil, nil
+
+	case []any:
+		if schemaType != "array" {
+			return value, nil
+		}
+		items := make([]any, 0, len(value))
+		for _, item := range value {
+			coerced, err := coerce(item, schema.Items)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, coerced)
+		}
+		return items, nil
+
+	case string:
+		switch schemaType {
+		case "integer":
+			i, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q isn't an integer", value)
+			}
+			return i, nil
+		case "number":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q isn't a number", value)
+			}
+			return f, nil
+		case "boolean":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%q isn't a boolean", value)
+			}
+			return b, nil
+		case "array":
+			if strings.HasPrefix(strings.TrimSpace(value), "[") {
+				var items []any
+				if err := json.Unmarshal([]byte(value), &items); err != nil {
+					return nil, fmt.Errorf("%q isn't a JSON array: %w", value, err)
+				}
+				return items, nil
+			}
+			item, err := coerce(value, schema.Items)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		case "object":
+			var object map[string]any
+			if err := json.Unmarshal([]byte(value), &object); err != nil {
+				return nil, fmt.Errorf("%q isn't a JSON object: %w", value, err)
+			}
+			return object, nil
+		}
+	}
+
+	return value, nil
+}
+
+func typeOf(schema *jsonschema.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	if schema.Type != "" {
+		return schema.Type
+	}
+	for _, t := range schema.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+func progressLine(params *mcp.ProgressNotificationParams) string {
+	line := "[progress] " + strconv.FormatFloat(params.Progress, 'f', -1, 64)
+	if params.Total > 0 {
+		line += "/" + strconv.FormatFloat(params.Total, 'f', -1, 64)
+	}
+	if params.Message != "" {
+		line += " " + params.Message
+	}
+	return line
+}
+
+func logLine(params *mcp.LoggingMessageParams) string {
+	line := "[" + string(params.Level) + "]"
+	if params.Logger != "" {
+		line += " " + params.Logger + ":"
+	}
+	if text, ok := params.Data.(string); ok {
+		return line + " " + text
+	}
+	buf, _ := json.Marshal(params.Data)
+	return line + " " + string(buf)
+}
+
+func printResult(w io.Writer, response *mcp.CallToolResult, format string) error {
+	buf, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling result: %w", err)
+	}
+	if format == "yaml" {
+		var value any
+		if err := json.Unmarshal(buf, &value); err != nil {
+			return err
+		}
+		if buf, err = yaml.Marshal(value); err != nil {
+			return fmt.Errorf("marshalling result: %w", err)
+		}
+	}
+
+	_, err = fmt.Fprintln(w, strings.TrimSuffix(string(buf), "\n"))
+	return err
+}
+
+// writeContent writes the text of a result to out and saves its images, audio and binary resources
+// in dir. The saved files are reported to notices.
+func writeContent(out, notices io.Writer, dir, toolName string, response *mcp.CallToolResult) error {
+	// The names given by the servers aren't used, so that they can't overwrite the user's files.
+	save := func(index int, mimeType string, data []byte) error {
+		file := filepath.Join(dir, fmt.Sprintf("%s-%d%s", toolName, index+1, extension(mimeType)))
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return fmt.Errorf("saving %s: %w", mimeType, err)
+		}
+		_, err := fmt.Fprintf(notices, "Saved %s to %s\n", mimeType, file)
+		return err
+	}
+
+	for i, content := range response.Content {
+		var err error
+		switch content := content.(type) {
+		case *mcp.TextContent:
+			_, err = fmt.Fprintln(out, content.Text)
+		case *mcp.ImageContent:
+			err = save(i, content.MIMEType, content.Data)
+		case *mcp.AudioContent:
+			err = save(i, content.MIMEType, content.Data)
+		case *mcp.ResourceLink:
+			_, err = fmt.Fprintf(notices, "Resource %s: %s\n", content.Name, content.URI)
+		case *mcp.EmbeddedResource:
+			if content.Resource == nil {
+				continue
+			}
+			if content.Resource.Blob != nil {
+				err = save(i, content.Resource.MIMEType, content.Resource.Blob)
+			} else {
+				_, err = fmt.Fprintln(out, content.Resource.Text)
+			}
+		default:
+			_, err = fmt.Fprintf(out, "%v\n", content)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extension(mimeType string) string {
+	extensions, _ := mime.ExtensionsByType(mimeType)
+	// Prefer .jpeg to .jfif.
+	_, subtype, _ := strings.Cut(mimeType, "/")
+	for _, extension := range extensions {
+		if extension == "."+subtype {
+			return extension
+		}
+	}
+	if len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ".bin"
+}
@@ -161,7 +161,7 @@ func update(
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(toolsConfig.ServerTools); err != nil {
+	if err := encoder.Encode(toolsConfig); err != nil {
 		return fmt.Errorf("encoding tools: %w", err)
 	}
 
@@ -25,7 +25,7 @@ func List(
 		metric.WithDescription("Number of tools discovered by CLI"),
 		metric.WithUnit("1"))
 
-	c, err := start(ctx, version, gatewayArgs, debug)
+	c, err := start(ctx, version, gatewayArgs, debug, nil)
 	if err != nil {
 		return fmt.Errorf("starting client: %w", err)
 	}
@@ -15,6 +15,7 @@ func start(
 	version string,
 	gatewayArgs []string,
 	verbose bool,
+	options *mcp.ClientOptions,
 ) (*mcp.ClientSession, error) {
 	var args []string
 	if version == "2" {
@@ -33,7 +34,7 @@ func start(
 		cmd.Stderr = logs.NewPrefixer(os.Stderr, "- mcp-gateway: ")
 	}
 
-	c := mcp.NewClient(&mcp.Implementation{Name: "mcp-gateway-client", Version: "1.0.0"}, nil)
+	c := mcp.NewClient(&mcp.Implementation{Name: "mcp-gateway-client", Version: "1.0.0"}, options)
 	transport := &mcp.CommandTransport{Command: cmd}
 	session, err := c.Connect(ctx, transport, nil)
 	if err != nil {
@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/docker/docker/api/types/volume"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,6 +66,24 @@ func TestEnableToolsExistingTool(t *testing.T) {
 	assert.Contains(t, toolsConfig.ServerTools["duckduckgo"], "other_tool")
 }
 
+func TestEnableToolsKeepsTransforms(t *testing.T) {
+	ctx, docker := setup(t,
+		withToolsConfig("duckduckgo:\n  - name: other_tool\n    transform:\n      - jq: .results\n      - format: table"),
+		withSampleCatalog())
+
+	err := Enable(ctx, docker, []string{"search_duckduckgo"}, "duckduckgo")
+	require.NoError(t, err)
+
+	toolsYAML, err := config.ReadTools(ctx, docker)
+	require.NoError(t, err)
+	toolsConfig, err := config.ParseToolsConfig(toolsYAML)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"other_tool", "search_duckduckgo"}, toolsConfig.ServerTools["duckduckgo"])
+	assert.Equal(t, []config.TransformStep{{JQ: ".results"}, {Format: "table"}},
+		toolsConfig.Tools["duckduckgo"]["other_tool"].Transform)
+}
+
 func TestEnableToolsDuplicateTool(t *testing.T) {
 	ctx, docker := setup(t,
 		withEmptyToolsConfig(),
@@ -327,7 +347,7 @@ func withSampleCatalog() option {
 // Unit tests for call
 
 func TestCallNoToolName(t *testing.T) {
-	err := Call(context.Background(), "2", []string{}, false, []string{})
+	err := Call(context.Background(), "2", []string{}, false, []string{}, CallOptions{})
 	require.Error(t, err)
 	assert.Equal(t, "no tool name provided", err.Error())
 }
@@ -356,6 +376,102 @@ func TestParseArgs(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestCallInvalidOutput(t *testing.T) {
+	err := Call(context.Background(), "2", []string{}, false, []string{"search"}, CallOptions{Output: "xml"})
+	require.ErrorContains(t, err, `invalid output "xml"`)
+}
+
+func TestCoerceArgs(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"limit":   {Type: "integer"},
+			"ratio":   {Types: []string{"null", "number"}},
+			"verbose": {Type: "boolean"},
+			"draft":   {Type: "boolean"},
+			"tags":    {Type: "array", Items: &jsonschema.Schema{Type: "integer"}},
+			"labels":  {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			"filter":  {Type: "object"},
+		},
+	}
+
+	args, err := coerceArgs(parseArgs([]string{
+		"limit=10", "ratio=0.5", "verbose", "draft=false", "tags=1", "tags=2", "labels=bug",
+		`filter={"state":"open"}`, "query=10",
+	}), schema)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"limit":   int64(10),
+		"ratio":   0.5,
+		"verbose": true,
+		"draft":   false,
+		"tags":    []any{int64(1), int64(2)},
+		"labels":  []any{"bug"},
+		"filter":  map[string]any{"state": "open"},
+		"query":   "10",
+	}, args)
+
+	_, err = coerceArgs(parseArgs([]string{"limit=ten"}), schema)
+	require.ErrorContains(t, err, `argument limit: "ten" isn't an integer`)
+}
+
+func TestReadArgs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "args.yaml")
+	writeFile(t, file, []byte("query: docker\nlimit: 5\n"))
+
+	args, err := readArgs(CallOptions{ArgsFile: file, ArgsJSON: `{"limit": 10, "tags": ["a"]}`})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"query": "docker", "limit": float64(10), "tags": []any{"a"}}, args)
+
+	_, err = readArgs(CallOptions{ArgsJSON: `[1]`})
+	require.ErrorContains(t, err, "parsing JSON arguments")
+}
+
+func TestWriteContent(t *testing.T) {
+	dir := t.TempDir()
+	response := &mcp.CallToolResult{Content: []mcp.Content{
+		&mcp.TextContent{Text: "A chart:"},
+		&mcp.ImageContent{MIMEType: "image/png", Data: []byte("png")},
+		&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+			URI: "file:///reports/q3.pdf", MIMEType: "application/pdf", Blob: []byte("pdf"),
+		}},
+		&mcp.ResourceLink{Name: "logs", URI: "gateway://results/1"},
+	}}
+
+	var out, notices strings.Builder
+	require.NoError(t, writeContent(&out, &notices, dir, "chart", response))
+
+	assert.Equal(t, "A chart:\n", out.String())
+	assert.Equal(t, "Saved image/png to "+filepath.Join(dir, "chart-2.png")+"\n"+
+		"Saved application/pdf to "+filepath.Join(dir, "chart-3.pdf")+"\n"+
+		"Resource logs: gateway://results/1\n", notices.String())
+
+	buf, err := os.ReadFile(filepath.Join(dir, "chart-2.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(buf))
+	// The servers don't choose the names of the files.
+	assert.NoFileExists(t, filepath.Join(dir, "q3.pdf"))
+}
+
+func TestPrintResultYAML(t *testing.T) {
+	var out strings.Builder
+	err := printResult(&out, &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: "boom"}},
+		IsError: true,
+	}, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "content:\n    - text: boom\n      type: text\nisError: true\n", out.String())
+}
+
+func TestProgressAndLogLines(t *testing.T) {
+	assert.Equal(t, "[progress] 3/10 Cloning", progressLine(&mcp.ProgressNotificationParams{
+		Progress: 3, Total: 10, Message: "Cloning",
+	}))
+	assert.Equal(t, `[warning] git: {"retry":2}`, logLine(&mcp.LoggingMessageParams{
+		Level: "warning", Logger: "git", Data: map[string]any{"retry": 2},
+	}))
+}
+
 // Unit tests for list
 
 func TestToolDescription(t *testing.T) {